@@ -0,0 +1,72 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package levels
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// ServeHTTP implements [http.Handler], allowing levels to be inspected and
+// changed at runtime.
+//
+//   - GET returns all configured levels as a JSON object.
+//   - PUT or POST sets the level of the logger named by the "logger" query
+//     parameter to the "level" query parameter, e.g. ?logger=db&level=debug.
+//   - DELETE removes the level of the logger named by the "logger" query
+//     parameter.
+//
+// The registry should only be exposed on internal or authenticated endpoints.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get(NameKey)
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(req.URL.Query().Get("level"))); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Set(name, level)
+	case http.MethodDelete:
+		if name == "" {
+			http.Error(w, "the default level cannot be removed", http.StatusBadRequest)
+			return
+		}
+		r.Unset(name)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	levels := make(map[string]string)
+	for name, level := range r.Levels() {
+		levels[name] = level.String()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levels)
+}
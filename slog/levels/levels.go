@@ -0,0 +1,172 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package levels implements a registry of per-component log levels that can be
+changed at runtime.
+
+Loggers are named using the [NameKey] attribute, and names are hierarchical:
+the level of "db.pool" is inherited from "db" unless it is set explicitly.
+
+	reg := levels.NewRegistry(slog.LevelInfo)
+	logger := slog.New(reg.Handler(handler)).With(levels.NameKey, "db.pool")
+
+	reg.Set("db", slog.LevelDebug) // enables debug logs for "db.pool"
+*/
+package levels
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// NameKey is the attribute key used to name loggers.
+const NameKey = "logger"
+
+// Registry maps logger names to levels.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	root *slog.LevelVar
+
+	mu     sync.RWMutex
+	levels map[string]*slog.LevelVar
+}
+
+// NewRegistry returns a new Registry that uses the given level for loggers
+// without a configured level.
+func NewRegistry(level slog.Level) *Registry {
+	r := &Registry{
+		root:   new(slog.LevelVar),
+		levels: make(map[string]*slog.LevelVar),
+	}
+	r.root.Set(level)
+	return r
+}
+
+// Set sets the level for the named logger and all of its children that do
+// not have their own level. An empty name sets the default level.
+func (r *Registry) Set(name string, level slog.Level) {
+	if name == "" {
+		r.root.Set(level)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.levels[name]
+	if !ok {
+		v = new(slog.LevelVar)
+		r.levels[name] = v
+	}
+	v.Set(level)
+}
+
+// Unset removes the level for the named logger, so that it is inherited from
+// its parent again. The default level cannot be removed.
+func (r *Registry) Unset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.levels, name)
+}
+
+// Level returns the level for the named logger, which is the level of the
+// closest configured ancestor, or the default level.
+func (r *Registry) Level(name string) slog.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name != "" {
+		if v, ok := r.levels[name]; ok {
+			return v.Level()
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return r.root.Level()
+}
+
+// Levels returns a copy of all configured levels. The default level is
+// returned with an empty name.
+func (r *Registry) Levels() map[string]slog.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m := make(map[string]slog.Level, len(r.levels)+1)
+	m[""] = r.root.Level()
+	for name, v := range r.levels {
+		m[name] = v.Level()
+	}
+	return m
+}
+
+// Handler returns a [slog.Handler] that wraps next and uses the registry to
+// decide whether records are enabled. The logger name is taken from the last
+// [NameKey] attribute added with WithAttrs.
+//
+// The level of next is not consulted, so next should be configured to handle
+// records of all levels.
+func (r *Registry) Handler(next slog.Handler) slog.Handler {
+	return &handler{next: next, r: r}
+}
+
+// handler is a [slog.Handler] that filters records using a Registry.
+type handler struct {
+	next slog.Handler
+	r    *Registry
+	name string
+}
+
+// Enabled implements [slog.Handler.Enabled].
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.r.Level(h.name)
+}
+
+// Handle implements [slog.Handler.Handle].
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs implements [slog.Handler.WithAttrs].
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	name := h.name
+	for _, attr := range attrs {
+		if attr.Key == NameKey {
+			name = attr.Value.Resolve().String()
+		}
+	}
+	return &handler{next: h.next.WithAttrs(attrs), r: h.r, name: name}
+}
+
+// WithGroup implements [slog.Handler.WithGroup].
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), r: h.r, name: h.name}
+}
+
+// Unwrap returns the wrapped handler.
+func (h *handler) Unwrap() slog.Handler {
+	return h.next
+}
@@ -0,0 +1,118 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package levels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistryLevel(t *testing.T) {
+	r := NewRegistry(slog.LevelInfo)
+	r.Set("db", slog.LevelDebug)
+	r.Set("db.pool.conn", slog.LevelError)
+
+	tests := []struct {
+		name string
+		want slog.Level
+	}{
+		{name: "", want: slog.LevelInfo},
+		{name: "http", want: slog.LevelInfo},
+		{name: "db", want: slog.LevelDebug},
+		{name: "db.pool", want: slog.LevelDebug},
+		{name: "db.pool.conn", want: slog.LevelError},
+		{name: "dbx", want: slog.LevelInfo},
+	}
+	for _, tt := range tests {
+		if got := r.Level(tt.name); got != tt.want {
+			t.Errorf("Level(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	r.Unset("db")
+	if got := r.Level("db.pool"); got != slog.LevelInfo {
+		t.Errorf("Level(%q) after Unset = %v, want %v", "db.pool", got, slog.LevelInfo)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	buf := new(bytes.Buffer)
+	r := NewRegistry(slog.LevelInfo)
+	logger := slog.New(r.Handler(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})))
+	db := logger.With(NameKey, "db")
+
+	if db.Enabled(ctx, slog.LevelDebug) {
+		t.Error("debug should be disabled by default")
+	}
+	r.Set("db", slog.LevelDebug)
+	if !db.Enabled(ctx, slog.LevelDebug) {
+		t.Error("debug should be enabled for db")
+	}
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		t.Error("debug should be disabled for the root logger")
+	}
+
+	db.WithGroup("g").Debug("hello")
+	if !bytes.Contains(buf.Bytes(), []byte("msg=hello")) {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry(slog.LevelInfo)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/?logger=db&level=debug", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got["db"] != "DEBUG" || got[""] != "INFO" {
+		t.Errorf("unexpected levels: %v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/?logger=db&level=loud", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid level status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/?logger=db", nil))
+	if r.Level("db") != slog.LevelInfo {
+		t.Errorf("DELETE did not remove level, got %v", r.Level("db"))
+	}
+}
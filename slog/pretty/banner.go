@@ -0,0 +1,100 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// Banner describes a start-up banner, written once when a handler is created.
+// See [Options.Banner].
+type Banner struct {
+	// Name is the name of the application.
+	Name string
+
+	// Version is the version of the application. If empty, the version of
+	// the main module is used, if available.
+	Version string
+
+	// BuildInfo adds the Go version and VCS revision to the banner.
+	BuildInfo bool
+
+	// PID adds the process ID to the banner.
+	PID bool
+
+	// Hostname adds the hostname to the banner.
+	Hostname bool
+
+	// Attrs are additional attributes added to the banner.
+	Attrs []slog.Attr
+}
+
+// WriteBanner writes the banner to the handler as a record at
+// [slog.LevelInfo], regardless of the handler's level. This can be used to
+// write banners to handlers that do not support [Options.Banner].
+func WriteBanner(ctx context.Context, h slog.Handler, b *Banner) error {
+	return h.Handle(ctx, b.record())
+}
+
+// record returns the banner as a record.
+func (b *Banner) record() slog.Record {
+	info, hasInfo := debug.ReadBuildInfo()
+
+	msg := "Starting"
+	if b.Name != "" {
+		msg += " " + b.Name
+	}
+	version := b.Version
+	if version == "" && hasInfo && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	if version != "" {
+		msg += " " + version
+	}
+
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)
+	if b.BuildInfo && hasInfo {
+		r.AddAttrs(slog.String("go", info.GoVersion))
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				r.AddAttrs(slog.String("revision", s.Value))
+			}
+		}
+	}
+	if b.PID {
+		r.AddAttrs(slog.Int("pid", os.Getpid()))
+	}
+	if b.Hostname {
+		if host, err := os.Hostname(); err == nil {
+			r.AddAttrs(slog.String("host", host))
+		}
+	}
+	r.AddAttrs(b.Attrs...)
+	return r
+}
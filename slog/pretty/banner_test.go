@@ -0,0 +1,66 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHandlerBanner(t *testing.T) {
+	buf := new(bytes.Buffer)
+	NewHandler(buf, &Options{
+		Level:        slog.LevelError,
+		DisableColor: true,
+		OmitTime:     true,
+		Banner: &Banner{
+			Name:    "app",
+			Version: "v1.2.3",
+			PID:     true,
+			Attrs:   []slog.Attr{slog.String("env", "test")},
+		},
+	})
+
+	want := "INF Starting app v1.2.3 pid=" + strconv.Itoa(os.Getpid()) + " env=test\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteBannerBuildInfo(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewHandler(buf, &Options{DisableColor: true, OmitTime: true})
+	if err := WriteBanner(context.Background(), h, &Banner{BuildInfo: true, Hostname: true}); err != nil {
+		t.Fatalf("WriteBanner() = %v", err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "INF Starting go=go") || !strings.Contains(got, " host=") {
+		t.Errorf("unexpected banner: %q", got)
+	}
+}
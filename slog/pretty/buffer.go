@@ -26,40 +26,79 @@ package pretty
 
 import (
 	"io"
+	"log/slog"
+	"slices"
 	"strconv"
-	"sync"
 	"time"
+
+	"hypera.dev/lib/util/pool"
 )
 
-const poolMaxBufferSize = 16 << 10
+const (
+	defaultBufferSize    = 1024
+	defaultMaxBufferSize = 16 << 10
+)
 
-// bufferPool is a simple Buffer pool.
-type bufferPool struct {
-	pool sync.Pool
+// BufferPool is a pool of [Buffer]s.
+// A single BufferPool may be shared between multiple handlers.
+type BufferPool struct {
+	pool *pool.Pool[*Buffer]
 }
 
-// newBufferPool returns a new bufferPool.
-func newBufferPool() *bufferPool {
-	return &bufferPool{
-		pool: sync.Pool{
-			New: func() any {
-				return newBuffer()
-			},
-		},
+// BufferPoolStats contains statistics about a [BufferPool].
+type BufferPoolStats struct {
+	// Hits is the number of buffers that were reused from the pool.
+	Hits uint64
+
+	// Misses is the number of buffers that had to be allocated.
+	Misses uint64
+
+	// Drops is the number of buffers that were not returned to the pool
+	// because they were larger than the maximum retained size.
+	Drops uint64
+}
+
+// NewBufferPool returns a new [BufferPool].
+//
+// New buffers are created with an initial capacity of size bytes, and buffers
+// larger than maxSize bytes are discarded instead of being returned to the
+// pool. If size or maxSize are zero, defaults of 1KiB and 16KiB are used.
+// If maxSize is negative, buffers are always retained.
+func NewBufferPool(size, maxSize int) *BufferPool {
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+	if maxSize == 0 {
+		maxSize = defaultMaxBufferSize
+	}
+	return &BufferPool{
+		pool: pool.New(&pool.Options[*Buffer]{
+			New:     func() *Buffer { return newBuffer(size) },
+			Reset:   (*Buffer).Reset,
+			Size:    func(b *Buffer) int { return cap(b.buf) },
+			MaxSize: max(maxSize, 0),
+		}),
 	}
 }
 
 // Acquire returns a buffer from the pool.
 // If there are no available buffers, a new one will be created.
-func (p *bufferPool) Acquire() *Buffer {
-	return p.pool.Get().(*Buffer)
+func (p *BufferPool) Acquire() *Buffer {
+	return p.pool.Get()
 }
 
 // Free returns the given buffer to the pool.
-func (p *bufferPool) Free(b *Buffer) {
-	if cap(b.buf) <= poolMaxBufferSize {
-		b.Reset()
-		p.pool.Put(b)
+func (p *BufferPool) Free(b *Buffer) {
+	p.pool.Put(b)
+}
+
+// Stats returns statistics about the pool.
+func (p *BufferPool) Stats() BufferPoolStats {
+	stats := p.pool.Stats()
+	return BufferPoolStats{
+		Hits:   stats.Hits,
+		Misses: stats.Misses,
+		Drops:  stats.Drops,
 	}
 }
 
@@ -68,9 +107,9 @@ type Buffer struct {
 	buf []byte
 }
 
-// newBuffer returns a new [Buffer].
-func newBuffer() *Buffer {
-	return &Buffer{buf: make([]byte, 0, 1024)}
+// newBuffer returns a new [Buffer] with the given initial capacity.
+func newBuffer(size int) *Buffer {
+	return &Buffer{buf: make([]byte, 0, size)}
 }
 
 // Write writes the given bytes to the buffer.
@@ -147,6 +186,19 @@ func (b *Buffer) AppendTimeFormat(t time.Time, layout string) {
 	b.buf = t.AppendFormat(b.buf, layout)
 }
 
+// AppendAny writes the given value to the buffer, formatted in the same way
+// as attribute values are formatted by the handler.
+func (b *Buffer) AppendAny(v any) {
+	appendValue(b, slog.AnyValue(v), true)
+}
+
+// Grow grows the buffer's capacity, if necessary, to guarantee space for
+// another n bytes.
+// If n is negative, Grow will panic.
+func (b *Buffer) Grow(n int) {
+	b.buf = slices.Grow(b.buf, n)
+}
+
 // Replace replaces the byte at index i with the given byte, if the underlying
 // byte slice contains index i.
 func (b *Buffer) Replace(i int, p byte) {
@@ -156,6 +208,15 @@ func (b *Buffer) Replace(i int, p byte) {
 	b.buf[i] = p
 }
 
+// Truncate discards all but the first n bytes of the buffer.
+// If n is out of range, Truncate does nothing.
+func (b *Buffer) Truncate(n int) {
+	if n < 0 || n > b.Len() {
+		return
+	}
+	b.buf = b.buf[:n]
+}
+
 // Len returns the length of the underlying byte slice.
 func (b *Buffer) Len() int {
 	return len(b.buf)
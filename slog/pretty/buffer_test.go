@@ -25,14 +25,66 @@
 package pretty
 
 import (
+	"errors"
 	"io"
 	"strings"
 	"testing"
 	"time"
 )
 
+func TestBuffer_Grow(t *testing.T) {
+	buf := newBuffer(0)
+	buf.AppendString("hello")
+	buf.Grow(4096)
+	if buf.Cap()-buf.Len() < 4096 {
+		t.Errorf("Cap() = %d, want at least %d", buf.Cap(), buf.Len()+4096)
+	}
+	if buf.String() != "hello" {
+		t.Errorf("String() = %q, want %q", buf.String(), "hello")
+	}
+}
+
+func TestBuffer_AppendAny(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{in: "hello", want: "hello"},
+		{in: "hello world", want: `"hello world"`},
+		{in: 42, want: "42"},
+		{in: 3.5, want: "3.5"},
+		{in: true, want: "true"},
+		{in: time.Second, want: "1s"},
+		{in: errors.New("oops"), want: "oops"},
+		{in: []int{1, 2}, want: `"[1 2]"`},
+	}
+	for _, tt := range tests {
+		buf := newBuffer(defaultBufferSize)
+		buf.AppendAny(tt.in)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("AppendAny(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	pool := NewBufferPool(16, 64)
+
+	buf := pool.Acquire()
+	if buf.Cap() != 16 {
+		t.Errorf("Cap() = %d, want 16", buf.Cap())
+	}
+	buf.Grow(128)
+	pool.Free(buf)
+
+	stats := pool.Stats()
+	if stats.Misses != 1 || stats.Drops != 1 {
+		t.Errorf("Stats() = %+v, want 1 miss and 1 drop", stats)
+	}
+}
+
 func BenchmarkBufferPool(b *testing.B) {
-	pool := newBufferPool()
+	pool := NewBufferPool(0, 0)
 	b.ResetTimer()
 
 	b.RunParallel(func(pb *testing.PB) {
@@ -44,7 +96,7 @@ func BenchmarkBufferPool(b *testing.B) {
 }
 
 func BenchmarkBuffer_Write(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := []byte("Hello, world!")
 	b.ResetTimer()
 
@@ -54,7 +106,7 @@ func BenchmarkBuffer_Write(b *testing.B) {
 }
 
 func BenchmarkBuffer_WriteString(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := "Hello, world!"
 	b.ResetTimer()
 
@@ -64,7 +116,7 @@ func BenchmarkBuffer_WriteString(b *testing.B) {
 }
 
 func BenchmarkBuffer_WriteTo(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	_, _ = buf.WriteString(strings.Repeat("a", 1024))
 	b.ResetTimer()
 
@@ -74,7 +126,7 @@ func BenchmarkBuffer_WriteTo(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendByte(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := byte('\n')
 	b.ResetTimer()
 
@@ -84,7 +136,7 @@ func BenchmarkBuffer_AppendByte(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendBytes(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := []byte("Hello, world!")
 	b.ResetTimer()
 
@@ -94,7 +146,7 @@ func BenchmarkBuffer_AppendBytes(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendString(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := "Hello, world!"
 	b.ResetTimer()
 
@@ -104,7 +156,7 @@ func BenchmarkBuffer_AppendString(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendInt(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := int64(42)
 	b.ResetTimer()
 
@@ -114,7 +166,7 @@ func BenchmarkBuffer_AppendInt(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendUint(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := uint64(73)
 	b.ResetTimer()
 
@@ -124,7 +176,7 @@ func BenchmarkBuffer_AppendUint(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendFloat32(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := float32(3.14)
 	b.ResetTimer()
 
@@ -134,7 +186,7 @@ func BenchmarkBuffer_AppendFloat32(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendFloat64(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	in := 3.14159265
 	b.ResetTimer()
 
@@ -144,7 +196,7 @@ func BenchmarkBuffer_AppendFloat64(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendBool(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -153,7 +205,7 @@ func BenchmarkBuffer_AppendBool(b *testing.B) {
 }
 
 func BenchmarkBuffer_AppendTimeFormat(b *testing.B) {
-	buf := newBuffer()
+	buf := newBuffer(defaultBufferSize)
 	t := time.Now()
 	layout := time.RFC3339
 	b.ResetTimer()
@@ -0,0 +1,81 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DiscardHandler is a [slog.Handler] that discards all records.
+// Enabled always returns false, so callers can skip building records entirely.
+var DiscardHandler slog.Handler = discardHandler{}
+
+// discardHandler is a [slog.Handler] that discards all records.
+type discardHandler struct{}
+
+// Enabled implements [slog.Handler.Enabled].
+func (discardHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+// Handle implements [slog.Handler.Handle].
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs implements [slog.Handler.WithAttrs].
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler { return d }
+
+// WithGroup implements [slog.Handler.WithGroup].
+func (d discardHandler) WithGroup(string) slog.Handler { return d }
+
+// NewLevelHandler returns a [slog.Handler] that reports records at or above
+// the given level as enabled, but discards everything it is asked to handle.
+// If level is nil, [slog.LevelInfo] is used.
+//
+// This is useful for benchmarking the cost of building records, or as a
+// default handler in library code.
+func NewLevelHandler(level slog.Leveler) slog.Handler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return levelHandler{level: level}
+}
+
+// levelHandler is a [slog.Handler] that only checks the level of records.
+type levelHandler struct {
+	level slog.Leveler
+}
+
+// Enabled implements [slog.Handler.Enabled].
+func (h levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements [slog.Handler.Handle].
+func (levelHandler) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs implements [slog.Handler.WithAttrs].
+func (h levelHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+// WithGroup implements [slog.Handler.WithGroup].
+func (h levelHandler) WithGroup(string) slog.Handler { return h }
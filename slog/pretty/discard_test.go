@@ -0,0 +1,68 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestDiscardHandler(t *testing.T) {
+	ctx := context.Background()
+	h := DiscardHandler.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g")
+	for _, l := range []slog.Level{slog.LevelDebug, slog.LevelError, LevelFatal} {
+		if h.Enabled(ctx, l) {
+			t.Errorf("Enabled(%v) = true, want false", l)
+		}
+	}
+	if err := h.Handle(ctx, slog.Record{}); err != nil {
+		t.Errorf("Handle() = %v, want nil", err)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	ctx := context.Background()
+	h := NewLevelHandler(slog.LevelWarn).WithGroup("g")
+	if h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("Enabled(INFO) = true, want false")
+	}
+	if !h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("Enabled(WARN) = false, want true")
+	}
+	if !NewLevelHandler(nil).Enabled(ctx, slog.LevelInfo) {
+		t.Error("default level should enable INFO")
+	}
+}
+
+func BenchmarkDiscardHandler(b *testing.B) {
+	l := slog.New(DiscardHandler)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		l.Info("Hello, world!", "a", 1)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables used by [NewHandlerFromEnv].
+const (
+	// EnvLevel is the minimum level to log, e.g. "debug" or "warn+2".
+	EnvLevel = "LOG_LEVEL"
+
+	// EnvFormat is the output format, one of "pretty", "json" or "text".
+	EnvFormat = "LOG_FORMAT"
+
+	// EnvSource enables adding the source code position to records.
+	// The value "absolute" also enables it, using [AbsoluteSourceFormatter].
+	EnvSource = "LOG_SOURCE"
+
+	// EnvColor controls coloured output, one of "auto", "always" or "never".
+	EnvColor = "LOG_COLOR"
+
+	// EnvTimeFormat is the time layout, either a Go layout string or the name
+	// of a layout in the time package, e.g. "RFC3339" or "DateTime".
+	EnvTimeFormat = "LOG_TIME_FORMAT"
+)
+
+// timeLayouts maps layout names to layouts from the time package.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// NewHandlerFromEnv returns a [slog.Handler] that writes to w, configured
+// using environment variables:
+//
+//   - LOG_LEVEL: the minimum level, defaults to "info".
+//   - LOG_FORMAT: "pretty" (default), "json" or "text".
+//   - LOG_SOURCE: whether to add source positions, defaults to false. The
+//     value "absolute" writes absolute paths using [AbsoluteSourceFormatter].
+//   - LOG_COLOR: "auto" (default), "always" or "never". In auto mode, colours
+//     are enabled if w is a terminal and NO_COLOR is not set.
+//   - LOG_TIME_FORMAT: the time layout, defaults to [time.DateTime] for the
+//     pretty format and RFC 3339 for other formats.
+//
+// Invalid values are ignored and the default is used instead.
+func NewHandlerFromEnv(w io.Writer) slog.Handler {
+	level := slog.LevelInfo
+	if v, ok := os.LookupEnv(EnvLevel); ok {
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(v)); err == nil {
+			level = l
+		}
+	}
+
+	var addSource, absSource bool
+	if v, ok := os.LookupEnv(EnvSource); ok {
+		if strings.EqualFold(v, "absolute") {
+			addSource, absSource = true, true
+		} else {
+			addSource, _ = strconv.ParseBool(v)
+		}
+	}
+
+	layout := os.Getenv(EnvTimeFormat)
+	if l, ok := timeLayouts[layout]; ok {
+		layout = l
+	}
+
+	switch strings.ToLower(os.Getenv(EnvFormat)) {
+	case "json":
+		return slog.NewJSONHandler(w, stdHandlerOptions(level, addSource, layout))
+	case "text":
+		return slog.NewTextHandler(w, stdHandlerOptions(level, addSource, layout))
+	}
+
+	opts := &Options{
+		Level:        level,
+		AddSource:    addSource,
+		DisableColor: !colorFromEnv(w),
+	}
+	if layout != "" {
+		opts.TimeFormatter = DefaultTimeFormatter(layout)
+	}
+	if absSource {
+		opts.SourceFormatter = AbsoluteSourceFormatter(!opts.DisableColor)
+	}
+	return NewHandler(w, opts)
+}
+
+// stdHandlerOptions returns options for the handlers in the slog package.
+func stdHandlerOptions(level slog.Level, addSource bool, layout string) *slog.HandlerOptions {
+	opts := &slog.HandlerOptions{
+		Level:     level,
+		AddSource: addSource,
+	}
+	if layout != "" {
+		opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+				a.Value = slog.StringValue(a.Value.Time().Format(layout))
+			}
+			return a
+		}
+	}
+	return opts
+}
+
+// colorFromEnv reports whether coloured output should be used for w.
+func colorFromEnv(w io.Writer) bool {
+	switch strings.ToLower(os.Getenv(EnvColor)) {
+	case "always", "true", "1":
+		return true
+	case "never", "false", "0":
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device, such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,85 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewHandlerFromEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		debug bool
+		check func(out string) bool
+	}{
+		{
+			name:  "defaults",
+			check: func(out string) bool { return strings.Contains(out, "INF hello") },
+		},
+		{
+			name:  "json",
+			env:   map[string]string{EnvFormat: "json", EnvLevel: "debug"},
+			debug: true,
+			check: func(out string) bool { return strings.HasPrefix(out, `{"time":`) },
+		},
+		{
+			name:  "text time format",
+			env:   map[string]string{EnvFormat: "TEXT", EnvTimeFormat: "Kitchen"},
+			check: func(out string) bool { return strings.Contains(out, "M level=INFO") },
+		},
+		{
+			name:  "invalid level",
+			env:   map[string]string{EnvLevel: "loud", EnvColor: "never"},
+			check: func(out string) bool { return !strings.Contains(out, "\033[") },
+		},
+		{
+			name:  "colour",
+			env:   map[string]string{EnvColor: "always"},
+			check: func(out string) bool { return strings.Contains(out, ansiLevelInfo) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{EnvLevel, EnvFormat, EnvSource, EnvColor, EnvTimeFormat} {
+				t.Setenv(k, tt.env[k])
+			}
+
+			buf := new(bytes.Buffer)
+			h := NewHandlerFromEnv(buf)
+			if got := h.Enabled(context.Background(), slog.LevelDebug); got != tt.debug {
+				t.Errorf("Enabled(DEBUG) = %v, want %v", got, tt.debug)
+			}
+			slog.New(h).Info("hello")
+			if !tt.check(buf.String()) {
+				t.Errorf("unexpected output: %q", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+// MultiErrorFormat controls how errors wrapping multiple errors, such as those
+// returned by [errors.Join], are formatted.
+type MultiErrorFormat int
+
+const (
+	// MultiErrorJoined formats multi-errors as a single string, using the
+	// result of the error's Error method.
+	MultiErrorJoined MultiErrorFormat = iota
+
+	// MultiErrorIndexed formats each wrapped error as a separate attribute,
+	// keyed by its index (e.g. err.0=... err.1=...).
+	MultiErrorIndexed
+
+	// MultiErrorList formats each wrapped error on a separate indented line
+	// following the attribute key.
+	MultiErrorList
+)
+
+// multiError is implemented by errors that wrap multiple errors.
+type multiError interface {
+	error
+	Unwrap() []error
+}
+
+// asMultiError returns the multi-error held by v, if any.
+func asMultiError(v slog.Value) (multiError, bool) {
+	if v.Kind() != slog.KindAny {
+		return nil, false
+	}
+	err, ok := v.Any().(multiError)
+	return err, ok
+}
+
+// multiErrorGroup returns a group value containing each of the wrapped errors,
+// keyed by their index.
+func multiErrorGroup(err multiError) slog.Value {
+	errs := err.Unwrap()
+	attrs := make([]slog.Attr, 0, len(errs))
+	for i, e := range errs {
+		if e == nil {
+			continue
+		}
+		attrs = append(attrs, slog.Any(strconv.Itoa(i), e))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// appendErrorList writes each of the wrapped errors on a separate indented
+// line. Nested multi-errors are indented further.
+func appendErrorList(buf *Buffer, err multiError, depth int) {
+	for _, e := range err.Unwrap() {
+		if e == nil {
+			continue
+		}
+		buf.AppendByte('\n')
+		for i := 0; i <= depth; i++ {
+			buf.AppendString("  ")
+		}
+		buf.AppendString("- ")
+		if me, ok := e.(multiError); ok {
+			buf.AppendString("multiple errors:")
+			appendErrorList(buf, me, depth+1)
+			continue
+		}
+		buf.AppendString(e.Error())
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandlerMultiError(t *testing.T) {
+	err := errors.Join(
+		errors.New("first"),
+		errors.Join(errors.New("second"), errors.New("third")),
+	)
+	tests := []struct {
+		format MultiErrorFormat
+		want   string
+	}{
+		{
+			format: MultiErrorJoined,
+			want:   `err="first\nsecond\nthird"`,
+		},
+		{
+			format: MultiErrorIndexed,
+			want:   "err.0=first err.1.0=second err.1.1=third",
+		},
+		{
+			format: MultiErrorList,
+			want:   "err=\n  - first\n  - multiple errors:\n    - second\n    - third",
+		},
+	}
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		l := slog.New(NewHandler(buf, &Options{
+			DisableColor:     true,
+			MultiErrorFormat: tt.format,
+		}))
+		l.Info("msg", "err", err)
+
+		_, got, _ := strings.Cut(strings.TrimSpace(buf.String()), "msg ")
+		if got != tt.want {
+			t.Errorf("format %d: got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
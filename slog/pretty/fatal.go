@@ -0,0 +1,70 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+)
+
+// LevelFatal is the level used by [Fatal] and [FatalContext].
+const LevelFatal = slog.LevelError + 4
+
+// ExitFunc is called by [Fatal] and [FatalContext] after the record has been
+// logged. It defaults to [os.Exit] and may be replaced in tests.
+var ExitFunc = os.Exit
+
+// Fatal logs a message at [LevelFatal] using the given logger, flushes the
+// logger's handler using [Flush] and then calls [ExitFunc] with exit code 1.
+// If the logger is nil, [slog.Default] is used.
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	fatal(context.Background(), logger, msg, args...)
+}
+
+// FatalContext is like [Fatal], but passes the given context to the handler.
+func FatalContext(ctx context.Context, logger *slog.Logger, msg string, args ...any) {
+	fatal(ctx, logger, msg, args...)
+}
+
+func fatal(ctx context.Context, logger *slog.Logger, msg string, args ...any) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	h := logger.Handler()
+	if h.Enabled(ctx, LevelFatal) {
+		var pcs [1]uintptr
+		runtime.Callers(3, pcs[:]) // skip [runtime.Callers, fatal, Fatal]
+		r := slog.NewRecord(time.Now(), LevelFatal, msg, pcs[0])
+		r.Add(args...)
+		_ = h.Handle(ctx, r)
+	}
+
+	_ = Flush(h)
+	ExitFunc(1)
+}
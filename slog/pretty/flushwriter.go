@@ -0,0 +1,148 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// writeFlusher is a writer that buffers output.
+type writeFlusher interface {
+	io.Writer
+	Flusher
+}
+
+// FlushWriter wraps a buffered writer and flushes it periodically, so that
+// output is not held in the buffer indefinitely. It is safe for concurrent
+// use.
+//
+// Handlers returned by [NewHandler] flush and close their writer when they
+// are closed with [Close], which also stops a FlushWriter.
+type FlushWriter struct {
+	mu     sync.Mutex
+	w      writeFlusher
+	under  io.Writer
+	err    error
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewFlushWriter returns a [FlushWriter] that flushes w every interval. If w
+// does not implement [Flusher], it is wrapped in a [bufio.Writer]. If the
+// interval is not positive, w is only flushed when Flush or Close is called.
+//
+// Closing the returned writer closes w if it implements [io.Closer], unless
+// it is [os.Stdout] or [os.Stderr].
+func NewFlushWriter(w io.Writer, interval time.Duration) *FlushWriter {
+	fw := &FlushWriter{under: w}
+	if bw, ok := w.(writeFlusher); ok {
+		fw.w = bw
+	} else {
+		fw.w = bufio.NewWriter(w)
+	}
+
+	if interval > 0 {
+		fw.stop = make(chan struct{})
+		fw.done = make(chan struct{})
+		go fw.run(interval)
+	}
+	return fw
+}
+
+func (w *FlushWriter) run(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.err == nil {
+				w.err = w.w.Flush()
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Write implements [io.Writer]. If a periodic flush failed, the error is
+// returned and nothing is written. Writing to a closed writer returns
+// [os.ErrClosed].
+func (w *FlushWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if err := w.takeErr(); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+// Flush implements [Flusher] by flushing the underlying writer.
+func (w *FlushWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	return errors.Join(w.takeErr(), w.w.Flush())
+}
+
+// Close stops periodic flushing, flushes the underlying writer and closes it.
+// Calling Close more than once has no effect.
+func (w *FlushWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	if w.stop != nil {
+		close(w.stop)
+		<-w.done
+	}
+	err := errors.Join(w.takeErr(), w.w.Flush())
+	return errors.Join(err, closeWriter(w.under))
+}
+
+// takeErr returns and clears the error from the last periodic flush.
+func (w *FlushWriter) takeErr() error {
+	err := w.err
+	w.err = nil
+	return err
+}
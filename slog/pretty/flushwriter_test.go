@@ -0,0 +1,98 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFlushWriterInterval(t *testing.T) {
+	out := new(syncBuffer)
+	w := NewFlushWriter(out, time.Millisecond)
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected output to be flushed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := out.String(); got != "hello\n" {
+		t.Errorf("got %q, want %q", got, "hello\n")
+	}
+}
+
+func TestFlushWriterClose(t *testing.T) {
+	out := new(closeRecorder)
+	w := NewFlushWriter(out, time.Hour)
+	l := slog.New(NewHandler(w, &Options{DisableColor: true, OmitTime: true}))
+	l.Info("hello")
+
+	if out.Len() != 0 {
+		t.Fatalf("expected buffered output, got %q", out.String())
+	}
+	if err := Close(l.Handler()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if got := out.String(); got != "INF hello\n" {
+		t.Errorf("got %q, want %q", got, "INF hello\n")
+	}
+	if !out.closed {
+		t.Error("expected writer to be closed")
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write() after Close = %v, want %v", err, os.ErrClosed)
+	}
+}
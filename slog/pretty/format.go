@@ -38,6 +38,7 @@ const (
 	ansiLevelInfo  = "\033[1;36m"
 	ansiLevelWarn  = "\033[1;33m"
 	ansiLevelError = "\033[1;91m"
+	ansiLevelFatal = "\033[1;97;41m"
 )
 
 // TimeFormatter writes the formatted time to the buffer.
@@ -56,39 +57,106 @@ type LevelFormatter func(buf *Buffer, l slog.Level)
 // DefaultLevelFormatter is the default LevelFormatter.
 func DefaultLevelFormatter(color bool) LevelFormatter {
 	return func(buf *Buffer, l slog.Level) {
-		switch {
-		case l < slog.LevelInfo:
-			if color {
-				buf.AppendString(ansiLevelDebug)
-				defer buf.AppendString(ansiReset)
-			}
-			buf.AppendString("DBG")
-			appendLevelDelta(buf, l-slog.LevelDebug)
-		case l < slog.LevelWarn:
-			if color {
-				buf.AppendString(ansiLevelInfo)
-				defer buf.AppendString(ansiReset)
-			}
-			buf.AppendString("INF")
-			appendLevelDelta(buf, l-slog.LevelInfo)
-		case l < slog.LevelError:
-			if color {
-				buf.AppendString(ansiLevelWarn)
-				defer buf.AppendString(ansiReset)
-			}
-			buf.AppendString("WRN")
-			appendLevelDelta(buf, l-slog.LevelWarn)
+		name, ansi, base := levelStyle(l)
+		if color {
+			buf.AppendString(ansi)
+			defer buf.AppendString(ansiReset)
+		}
+		buf.AppendString(name)
+		appendLevelDelta(buf, l-base)
+	}
+}
+
+// LevelIcons contains the icons used by [IconLevelFormatter].
+type LevelIcons struct {
+	Debug string
+	Info  string
+	Warn  string
+	Error string
+	Fatal string
+}
+
+var (
+	// EmojiLevelIcons are emoji level icons.
+	EmojiLevelIcons = LevelIcons{
+		Debug: "🐛",
+		Info:  "ℹ️",
+		Warn:  "⚠️",
+		Error: "❌",
+		Fatal: "💀",
+	}
+
+	// NerdFontLevelIcons are Nerd Font level icons.
+	// See https://www.nerdfonts.com for details.
+	NerdFontLevelIcons = LevelIcons{
+		Debug: "\uf188", // nf-fa-bug
+		Info:  "\uf05a", // nf-fa-info_circle
+		Warn:  "\uf071", // nf-fa-warning
+		Error: "\uf057", // nf-fa-times_circle
+		Fatal: "\uf1e2", // nf-fa-bomb
+	}
+)
+
+// IconLevelFormatter returns a LevelFormatter that writes an icon for each
+// level. If showName is true, the level name is written after the icon.
+//
+// Icons are padded with spaces to the display width of the widest icon, so
+// that the following columns stay aligned.
+func IconLevelFormatter(icons LevelIcons, showName, color bool) LevelFormatter {
+	width := max(
+		stringWidth(icons.Debug), stringWidth(icons.Info), stringWidth(icons.Warn),
+		stringWidth(icons.Error), stringWidth(icons.Fatal),
+	)
+	return func(buf *Buffer, l slog.Level) {
+		name, ansi, base := levelStyle(l)
+		var icon string
+		switch base {
+		case slog.LevelDebug:
+			icon = icons.Debug
+		case slog.LevelInfo:
+			icon = icons.Info
+		case slog.LevelWarn:
+			icon = icons.Warn
+		case slog.LevelError:
+			icon = icons.Error
 		default:
+			icon = icons.Fatal
+		}
+		buf.AppendString(icon)
+		for i := stringWidth(icon); i < width; i++ {
+			buf.AppendByte(' ')
+		}
+
+		if showName {
+			buf.AppendByte(' ')
 			if color {
-				buf.AppendString(ansiLevelError)
+				buf.AppendString(ansi)
 				defer buf.AppendString(ansiReset)
 			}
-			buf.AppendString("ERR")
-			appendLevelDelta(buf, l-slog.LevelError)
+			buf.AppendString(name)
+			appendLevelDelta(buf, l-base)
 		}
 	}
 }
 
+// levelStyle returns the name, ANSI colour and base level for the given level.
+// Only [LevelFatal] itself is written as FTL, so that other levels above
+// errors keep being written relative to ERR.
+func levelStyle(l slog.Level) (string, string, slog.Level) {
+	switch {
+	case l == LevelFatal:
+		return "FTL", ansiLevelFatal, LevelFatal
+	case l < slog.LevelInfo:
+		return "DBG", ansiLevelDebug, slog.LevelDebug
+	case l < slog.LevelWarn:
+		return "INF", ansiLevelInfo, slog.LevelInfo
+	case l < slog.LevelError:
+		return "WRN", ansiLevelWarn, slog.LevelWarn
+	default:
+		return "ERR", ansiLevelError, slog.LevelError
+	}
+}
+
 func appendLevelDelta(buf *Buffer, delta slog.Level) {
 	if delta == 0 {
 		return
@@ -119,3 +187,18 @@ func DefaultSourceFormatter(color bool) SourceFormatter {
 		buf.AppendByte('>')
 	}
 }
+
+// AbsoluteSourceFormatter is a SourceFormatter that writes the full path of the
+// source file followed by the line number, e.g. /home/user/project/main.go:42.
+// Most IDE terminals, including VS Code and GoLand, make this form clickable.
+func AbsoluteSourceFormatter(color bool) SourceFormatter {
+	return func(buf *Buffer, src *slog.Source) {
+		if color {
+			buf.AppendString(ansiFaint)
+			defer buf.AppendString(ansiReset)
+		}
+		buf.AppendString(src.File)
+		buf.AppendByte(':')
+		buf.AppendInt(int64(src.Line))
+	}
+}
@@ -31,18 +31,38 @@ The output format can be customised with [Options].
 package pretty
 
 import (
+	"bytes"
 	"context"
-	"encoding"
 	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 	"unicode"
 )
 
-var emptyAttr = slog.Attr{}
+const (
+	// multilineIndent is the indent used for attributes in multiline mode.
+	multilineIndent = "  "
+
+	// groupBlockEnd is written after a group block, so that the following
+	// attributes are indented less than the attributes in the block.
+	groupBlockEnd = "\n" + multilineIndent
+
+	// maxResolveDepth is the maximum depth of nested slices and maps that
+	// will be resolved into groups.
+	maxResolveDepth = 4
+)
+
+var (
+	emptyAttr     = slog.Attr{}
+	logValuerType = reflect.TypeFor[slog.LogValuer]()
+)
 
 // Options allows you to customise the output format.
 // This is a drop-in replacement for [slog.HandlerOptions].
@@ -63,13 +83,106 @@ type Options struct {
 	DisableColor bool
 
 	// TimeFormatter is the [time.Time] formatter used to format log timestamps.
+	// If the formatter writes nothing, the time column is omitted.
 	TimeFormatter TimeFormatter
 
+	// OmitTime disables writing log timestamps. This is useful where logs
+	// are already timestamped, such as with journald or Docker.
+	OmitTime bool
+
 	// LevelFormatter is the [slog.Level] formatter used to format log levels.
 	LevelFormatter LevelFormatter
 
 	// SourceFormatter is the [slog.Source] formatter used to format log sources.
 	SourceFormatter SourceFormatter
+
+	// BufferSize is the initial capacity of the buffers used to format records.
+	// If zero, a default size of 1KiB is used.
+	BufferSize int
+
+	// MaxBufferSize is the maximum capacity of buffers retained for reuse.
+	// Larger buffers are discarded after use. If zero, a default size of
+	// 16KiB is used. If negative, buffers are always retained.
+	MaxBufferSize int
+
+	// BufferPool is the pool used to acquire buffers. This allows a pool to
+	// be shared between multiple handlers. If set, BufferSize and
+	// MaxBufferSize are ignored.
+	BufferPool *BufferPool
+
+	// MultiErrorFormat controls how errors wrapping multiple errors, such as
+	// those returned by [errors.Join], are formatted.
+	// Defaults to [MultiErrorJoined].
+	MultiErrorFormat MultiErrorFormat
+
+	// KeyValueSeparator is written between attribute keys and values.
+	// Defaults to "=".
+	KeyValueSeparator string
+
+	// AttrSeparator is written between attributes. Defaults to " ".
+	// AttrSeparator is ignored if Multiline is enabled.
+	AttrSeparator string
+
+	// LevelWriters maps levels to the writers used for records at or above
+	// that level, up to the next level in the map. Records below the lowest
+	// level in the map are written to the handler's writer.
+	//
+	// For example, {slog.LevelWarn: os.Stderr} writes records at WARN and
+	// above to stderr, and all other records to the handler's writer.
+	LevelWriters map[slog.Level]io.Writer
+
+	// Banner is a start-up banner written once when the handler is created,
+	// regardless of Level. See [Banner] for details.
+	Banner *Banner
+
+	// IncludeHost adds the hostname of the machine to every record,
+	// using the key [HostKey].
+	IncludeHost bool
+
+	// IncludePID adds the process ID to every record, using the key [PIDKey].
+	IncludePID bool
+
+	// IncludeGoroutineID adds the ID of the goroutine that logged the record
+	// to every record, using the key [GoroutineKey]. This is computed for
+	// each record and has a small performance cost.
+	IncludeGoroutineID bool
+
+	// MaxWidth clips lines that are wider than MaxWidth columns, ending them
+	// with an ellipsis. This is useful for dashboard-style output, where
+	// wrapped lines break alignment. If [AutoWidth], the width of the
+	// terminal is detected using [TerminalWidth] when the handler is created.
+	// If zero, lines are not clipped.
+	MaxWidth int
+
+	// HeaderKeys are the keys of attributes that are always written first,
+	// directly after the log message, regardless of where they were added.
+	// Keys of attributes in groups must be qualified, e.g. "request.id".
+	HeaderKeys []string
+
+	// Multiline enables writing each attribute on a separate indented line
+	// below the log message, which is easier to read for records with many
+	// attributes.
+	Multiline bool
+
+	// RecordStyle is called for each record to choose a [Style] that the
+	// whole record is highlighted with, e.g. to give audit events a
+	// background colour. If it returns nil, the record is not highlighted.
+	// RecordStyle is ignored if DisableColor is true.
+	RecordStyle func(record slog.Record) *Style
+
+	// GroupBlockAttrs and GroupBlockWidth control when groups are written as
+	// an indented block instead of inline. A group is written as a block if
+	// it has more than GroupBlockAttrs attributes, or if its inline form is
+	// wider than GroupBlockWidth columns. If zero, the threshold is ignored.
+	GroupBlockAttrs, GroupBlockWidth int
+
+	// MessagePrefix and MessageSuffix are written before and after the log
+	// message, e.g. "[" and "]".
+	MessagePrefix, MessageSuffix string
+
+	// SourcePrefix and SourceSuffix are written before and after the
+	// formatted log source.
+	SourcePrefix, SourceSuffix string
 }
 
 // ReplaceAttrFunc is used to rewrite each non-group [slog.Attr] before it is logged.
@@ -77,19 +190,38 @@ type ReplaceAttrFunc func(groups []string, attr slog.Attr) slog.Attr
 
 // handler is an implementation of [slog.Handler].
 type handler struct {
-	w          io.Writer
-	mu         *sync.Mutex
-	opts       *Options
-	bufferPool *bufferPool
-
-	attrsPrefix string
-	groupPrefix string
-	groups      []string
+	w            io.Writer
+	mu           *sync.Mutex
+	levelWriters []levelWriter
+	maxWidth     int
+	opts         *Options
+	bufferPool   *BufferPool
+
+	headerPrefix string
+	attrsPrefix  string
+	groups       []string
 }
 
 // NewHandler returns a [slog.Handler] that writes human-readable and
 // optionally coloured logs to the writer.
 func NewHandler(w io.Writer, opts *Options) slog.Handler {
+	return newHandler(w, opts)
+}
+
+func newHandler(w io.Writer, opts *Options) *handler {
+	h := initHandler(w, opts)
+	if attrs := processAttrs(h.opts); len(attrs) > 0 {
+		h = h.WithAttrs(attrs).(*handler)
+	}
+	if h.opts.Banner != nil {
+		_ = WriteBanner(context.Background(), h, h.opts.Banner)
+	}
+	return h
+}
+
+// initHandler returns a handler with the defaults applied to opts, without
+// the per-process attributes or banner.
+func initHandler(w io.Writer, opts *Options) *handler {
 	if opts == nil {
 		opts = new(Options)
 	}
@@ -98,7 +230,15 @@ func NewHandler(w io.Writer, opts *Options) slog.Handler {
 		w:          w,
 		mu:         new(sync.Mutex),
 		opts:       opts,
-		bufferPool: newBufferPool(),
+		bufferPool: opts.BufferPool,
+	}
+	if h.bufferPool == nil {
+		h.bufferPool = NewBufferPool(opts.BufferSize, opts.MaxBufferSize)
+	}
+	h.levelWriters = newLevelWriters(opts.LevelWriters, h.w, h.mu)
+	h.maxWidth = opts.MaxWidth
+	if h.maxWidth == AutoWidth {
+		h.maxWidth = TerminalWidth(w)
 	}
 	if h.opts.Level == nil {
 		h.opts.Level = slog.LevelInfo
@@ -112,6 +252,14 @@ func NewHandler(w io.Writer, opts *Options) slog.Handler {
 	if h.opts.SourceFormatter == nil {
 		h.opts.SourceFormatter = DefaultSourceFormatter(!h.opts.DisableColor)
 	}
+	if h.opts.KeyValueSeparator == "" {
+		h.opts.KeyValueSeparator = "="
+	}
+	if h.opts.Multiline {
+		h.opts.AttrSeparator = "\n" + multilineIndent
+	} else if h.opts.AttrSeparator == "" {
+		h.opts.AttrSeparator = " "
+	}
 	return h
 }
 
@@ -133,7 +281,7 @@ func (h *handler) Handle(_ context.Context, record slog.Record) error {
 	if rep == nil {
 		h.opts.LevelFormatter(buf, record.Level)
 	} else if a := rep(nil, slog.Any(slog.LevelKey, record.Level)); a.Key != "" {
-		h.appendValue(buf, a.Value, false)
+		appendValue(buf, a.Value, false)
 	}
 	buf.AppendByte(' ')
 
@@ -142,35 +290,91 @@ func (h *handler) Handle(_ context.Context, record slog.Record) error {
 
 	// Message
 	if rep == nil {
-		buf.AppendString(record.Message)
+		h.appendMessage(buf, slog.StringValue(record.Message))
 	} else if a := rep(nil, slog.String(slog.MessageKey, record.Message)); a.Key != "" {
-		h.appendValue(buf, a.Value, false)
+		h.appendMessage(buf, a.Value)
 	}
-	buf.AppendByte(' ')
-
-	// handler attributes
-	if len(h.attrsPrefix) > 0 {
-		buf.AppendString(h.attrsPrefix)
+	trailing := 1
+	if h.opts.Multiline {
+		buf.AppendString(h.opts.AttrSeparator)
+		trailing = len(h.opts.AttrSeparator)
+	} else {
+		buf.AppendByte(' ')
 	}
 
-	// Write attributes
-	record.Attrs(func(attr slog.Attr) bool {
-		if rep != nil {
-			attr = rep(h.groups, attr)
-		}
-		h.appendAttr(buf, attr, h.groupPrefix)
-		return true
-	})
+	// Attributes
+	attrsStart := buf.Len()
+	h.appendAttrs(buf, record)
+	if buf.Len() > attrsStart {
+		trailing = h.trailingSeparator(buf)
+	}
 
 	if buf.Len() == 0 {
 		return nil
 	}
-	buf.Replace(buf.Len()-1, '\n') // Replace the last space with a newline
+	// Replace the last separator with a newline
+	buf.Truncate(buf.Len() - trailing)
+	if style := h.recordStyle(record); style != nil {
+		styled := h.bufferPool.Acquire()
+		defer h.bufferPool.Free(styled)
+		style.appendStyled(styled, buf.buf)
+		buf = styled
+	}
+	buf.AppendByte('\n')
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	_, err := buf.WriteTo(h.w)
-	return err
+	return h.write(record.Level, buf)
+}
+
+// recordStyle returns the style to highlight the record with, if any.
+func (h *handler) recordStyle(record slog.Record) *Style {
+	if h.opts.RecordStyle == nil || h.opts.DisableColor {
+		return nil
+	}
+	return h.opts.RecordStyle(record)
+}
+
+// appendAttrs writes the handler and record attributes to the buffer.
+func (h *handler) appendAttrs(buf *Buffer, record slog.Record) {
+	var goroutine slog.Attr
+	if h.opts.IncludeGoroutineID {
+		goroutine = slog.Uint64(GoroutineKey, goroutineID())
+	}
+	h.appendRecordAttrs(buf, record, goroutine)
+}
+
+// appendRecordAttrs writes the handler attributes, the goroutine attribute
+// unless it is empty, and the record attributes to the buffer.
+func (h *handler) appendRecordAttrs(buf *Buffer, record slog.Record, goroutine slog.Attr) {
+	if len(h.opts.HeaderKeys) == 0 {
+		buf.AppendString(h.attrsPrefix)
+		if !goroutine.Equal(emptyAttr) {
+			h.appendAttr(buf, nil, goroutine, nil)
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			h.appendAttr(buf, nil, attr, h.groups)
+			return true
+		})
+		return
+	}
+
+	// Header attributes must be written before all other attributes, so
+	// record attributes need to be collected first.
+	hdr := h.bufferPool.Acquire()
+	defer h.bufferPool.Free(hdr)
+	attrs := h.bufferPool.Acquire()
+	defer h.bufferPool.Free(attrs)
+
+	if !goroutine.Equal(emptyAttr) {
+		h.appendAttr(attrs, hdr, goroutine, nil)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		h.appendAttr(attrs, hdr, attr, h.groups)
+		return true
+	})
+	buf.AppendString(h.headerPrefix)
+	buf.AppendBytes(hdr.buf)
+	buf.AppendString(h.attrsPrefix)
+	buf.AppendBytes(attrs.buf)
 }
 
 // WithAttrs implements [slog.Handler.WithAttrs].
@@ -182,14 +386,19 @@ func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
 
 	buf := h.bufferPool.Acquire()
 	defer h.bufferPool.Free(buf)
+	var hdr *Buffer
+	if len(h.opts.HeaderKeys) > 0 {
+		hdr = h.bufferPool.Acquire()
+		defer h.bufferPool.Free(hdr)
+	}
 
 	for _, attr := range attrs {
-		if h.opts.ReplaceAttr != nil {
-			attr = h.opts.ReplaceAttr(h.groups, attr)
-		}
-		h.appendAttr(buf, attr, h.groupPrefix)
+		h.appendAttr(buf, hdr, attr, h.groups)
 	}
 	h2.attrsPrefix += buf.String()
+	if hdr != nil {
+		h2.headerPrefix += hdr.String()
+	}
 	return h2
 }
 
@@ -199,91 +408,359 @@ func (h *handler) WithGroup(name string) slog.Handler {
 		return h
 	}
 	h2 := h.clone()
-	h2.groupPrefix += name + "."
-	h2.groups = append(h2.groups, name)
+	h2.groups = append(slices.Clip(h.groups), name)
 	return h2
 }
 
 func (h *handler) clone() *handler {
 	return &handler{
-		w:           h.w,
-		mu:          h.mu,
-		opts:        h.opts,
-		bufferPool:  h.bufferPool,
-		attrsPrefix: h.attrsPrefix,
-		groupPrefix: h.groupPrefix,
-		groups:      h.groups,
+		w:            h.w,
+		mu:           h.mu,
+		levelWriters: h.levelWriters,
+		maxWidth:     h.maxWidth,
+		opts:         h.opts,
+		bufferPool:   h.bufferPool,
+		headerPrefix: h.headerPrefix,
+		attrsPrefix:  h.attrsPrefix,
+		groups:       h.groups,
 	}
 }
 
 func (h *handler) appendTime(buf *Buffer, rep ReplaceAttrFunc, record slog.Record) {
-	if !record.Time.IsZero() {
-		val := record.Time.Round(0)
-		if rep == nil {
-			h.opts.TimeFormatter(buf, val)
-		} else if a := rep(nil, slog.Time(slog.TimeKey, val)); a.Key != "" {
-			if a.Value.Kind() == slog.KindTime {
-				h.opts.TimeFormatter(buf, a.Value.Time())
-			} else {
-				h.appendValue(buf, a.Value, false)
-			}
+	if h.opts.OmitTime || record.Time.IsZero() {
+		return
+	}
+	start := buf.Len()
+	val := record.Time.Round(0)
+	if rep == nil {
+		h.opts.TimeFormatter(buf, val)
+	} else if a := rep(nil, slog.Time(slog.TimeKey, val)); a.Key != "" {
+		if a.Value.Kind() == slog.KindTime {
+			h.opts.TimeFormatter(buf, a.Value.Time())
+		} else {
+			appendValue(buf, a.Value, false)
 		}
+	}
+	// Formatters may write nothing, in which case the time column is omitted
+	if buf.Len() > start {
 		buf.AppendByte(' ')
 	}
 }
 
 func (h *handler) appendSource(buf *Buffer, rep ReplaceAttrFunc, record slog.Record) {
 	if h.opts.AddSource {
-		fs := runtime.CallersFrames([]uintptr{record.PC})
-		f, _ := fs.Next()
-		if f.File != "" {
-			src := &slog.Source{
-				Function: f.Function,
-				File:     f.File,
-				Line:     f.Line,
-			}
+		if src := recordSource(record); src != nil {
+			buf.AppendString(h.opts.SourcePrefix)
 			if rep == nil {
 				h.opts.SourceFormatter(buf, src)
 			} else if a := rep(nil, slog.Any(slog.SourceKey, src)); a.Key != "" {
-				h.appendValue(buf, a.Value, false)
+				appendValue(buf, a.Value, false)
 			}
+			buf.AppendString(h.opts.SourceSuffix)
 			buf.AppendByte(' ')
 		}
 	}
 }
 
-func (h *handler) appendAttr(buf *Buffer, attr slog.Attr, groupsPrefix string) {
+// recordSource returns the source code position of the record, or nil if it
+// is not available.
+func recordSource(record slog.Record) *slog.Source {
+	fs := runtime.CallersFrames([]uintptr{record.PC})
+	f, _ := fs.Next()
+	if f.File == "" {
+		return nil
+	}
+	return &slog.Source{
+		Function: f.Function,
+		File:     f.File,
+		Line:     f.Line,
+	}
+}
+
+// appendAttr writes the attribute to the buffer. If hdr is not nil, attributes
+// with keys listed in [Options.HeaderKeys] are written to hdr instead.
+//
+// groups contains the names of the groups the attribute is in, including
+// groups opened with WithGroup. As with [slog.HandlerOptions.ReplaceAttr],
+// ReplaceAttr is called for each non-group attribute with these groups.
+func (h *handler) appendAttr(buf, hdr *Buffer, attr slog.Attr, groups []string) {
+	h.appendBlockAttr(buf, hdr, attr, groups, groupBlock{sep: h.opts.AttrSeparator})
+}
+
+// groupBlock describes the group block that attributes are written in.
+type groupBlock struct {
+	sep      string // the separator written after each attribute
+	base     int    // the number of groups omitted from keys
+	depth    int    // the number of enclosing group blocks
+	replaced bool   // whether the attributes were replaced with replaceAttr
+}
+
+func (h *handler) appendBlockAttr(buf, hdr *Buffer, attr slog.Attr, groups []string, blk groupBlock) {
+	if !blk.replaced {
+		attr.Value = attr.Value.Resolve()
+		if rep := h.opts.ReplaceAttr; rep != nil && attr.Value.Kind() != slog.KindGroup {
+			attr = rep(groups, attr)
+			attr.Value = attr.Value.Resolve()
+		}
+	}
 	if attr.Equal(emptyAttr) {
 		return
 	}
-	attr.Value = attr.Value.Resolve()
+	if v, ok := resolveNested(attr.Value, 0); ok {
+		attr.Value = v
+	}
+	if err, ok := asMultiError(attr.Value); ok {
+		switch h.opts.MultiErrorFormat {
+		case MultiErrorIndexed:
+			attr.Value = multiErrorGroup(err)
+		case MultiErrorList:
+			buf = h.attrBuffer(buf, hdr, attr.Key, groups)
+			h.appendKey(buf, attr.Key, groups[blk.base:])
+			appendErrorList(buf, err, 0)
+			buf.AppendString(blk.sep)
+			return
+		case MultiErrorJoined:
+			// Formatted as a regular value
+		}
+	}
 
 	if attr.Value.Kind() == slog.KindGroup {
+		// Groups without attributes are elided, and groups with empty keys
+		// are inlined.
 		if attr.Key != "" {
-			groupsPrefix += attr.Key + "."
+			if h.opts.GroupBlockWidth > 0 && !blk.replaced {
+				// Measuring the width of the group renders it, so its
+				// attributes are replaced once, before it is rendered
+				attr = h.replaceAttr(attr, groups)
+				blk.replaced = true
+			}
+			if h.isGroupBlock(attr, groups, blk) {
+				h.appendGroupBlock(buf, attr, groups, blk)
+				return
+			}
+			groups = append(slices.Clip(groups), attr.Key)
 		}
 		for _, groupAttr := range attr.Value.Group() {
-			h.appendAttr(buf, groupAttr, groupsPrefix)
+			h.appendBlockAttr(buf, hdr, groupAttr, groups, blk)
 		}
 		return
 	}
 
-	h.appendKey(buf, attr.Key, groupsPrefix)
-	h.appendValue(buf, attr.Value, true)
-	buf.AppendByte(' ')
+	buf = h.attrBuffer(buf, hdr, attr.Key, groups)
+	h.appendKey(buf, attr.Key, groups[blk.base:])
+	appendValue(buf, attr.Value, true)
+	buf.AppendString(blk.sep)
+}
+
+// replaceAttr resolves the attribute, and calls [Options.ReplaceAttr] for it,
+// or for each attribute in it if it is a group, in the same way as
+// appendBlockAttr does when writing it. The result can be written without
+// calling ReplaceAttr or [slog.LogValuer] methods again. An empty attribute
+// is returned if it was removed.
+func (h *handler) replaceAttr(attr slog.Attr, groups []string) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if rep := h.opts.ReplaceAttr; rep != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = rep(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if v, ok := resolveNested(attr.Value, 0); ok {
+		attr.Value = v
+	}
+	if err, ok := asMultiError(attr.Value); ok && h.opts.MultiErrorFormat == MultiErrorIndexed {
+		attr.Value = multiErrorGroup(err)
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		return attr
+	}
+
+	if attr.Key != "" {
+		groups = append(slices.Clip(groups), attr.Key)
+	}
+	group := attr.Value.Group()
+	replaced := make([]slog.Attr, 0, len(group))
+	for _, a := range group {
+		if a = h.replaceAttr(a, groups); !a.Equal(emptyAttr) {
+			replaced = append(replaced, a)
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: slog.GroupValue(replaced...)}
+}
+
+// isGroupBlock reports whether the group should be written as a block,
+// according to [Options.GroupBlockAttrs] and [Options.GroupBlockWidth].
+func (h *handler) isGroupBlock(attr slog.Attr, groups []string, blk groupBlock) bool {
+	if n := h.opts.GroupBlockAttrs; n > 0 && len(attr.Value.Group()) > n {
+		return true
+	}
+	if h.opts.GroupBlockWidth <= 0 {
+		return false
+	}
+
+	buf := h.bufferPool.Acquire()
+	defer h.bufferPool.Free(buf)
+	groups = append(slices.Clip(groups), attr.Key)
+	for _, groupAttr := range attr.Value.Group() {
+		h.appendBlockAttr(buf, nil, groupAttr, groups, blk)
+	}
+	return visibleWidthBytes(buf.buf) > h.opts.GroupBlockWidth
+}
+
+// appendGroupBlock writes the group as an indented block, with each
+// attribute on a separate line. Keys in the block are relative to the group.
+func (h *handler) appendGroupBlock(buf *Buffer, attr slog.Attr, groups []string, blk groupBlock) {
+	start := buf.Len()
+	h.appendKeySep(buf, attr.Key, groups[blk.base:], ":")
+	groups = append(slices.Clip(groups), attr.Key)
+	child := groupBlock{
+		sep:      "\n" + strings.Repeat(multilineIndent, blk.depth+2),
+		base:     len(groups),
+		depth:    blk.depth + 1,
+		replaced: blk.replaced,
+	}
+	buf.AppendString(child.sep)
+
+	attrsStart := buf.Len()
+	for _, groupAttr := range attr.Value.Group() {
+		h.appendBlockAttr(buf, nil, groupAttr, groups, child)
+	}
+	if buf.Len() == attrsStart {
+		// Groups without attributes are elided
+		buf.Truncate(start)
+		return
+	}
+	buf.Truncate(buf.Len() - len(child.sep))
+	if blk.depth == 0 {
+		buf.AppendString(groupBlockEnd)
+	} else {
+		buf.AppendString(blk.sep)
+	}
 }
 
-func (h *handler) appendKey(buf *Buffer, key, groups string) {
+// trailingSeparator returns the length of the separator written after the
+// last attribute in the buffer, which is the longest of the attribute separator
+// and the group block end.
+func (h *handler) trailingSeparator(buf *Buffer) int {
+	sep := len(h.opts.AttrSeparator)
+	if len(groupBlockEnd) > sep && bytes.HasSuffix(buf.buf, []byte(groupBlockEnd)) {
+		return len(groupBlockEnd)
+	}
+	return sep
+}
+
+// attrBuffer returns the buffer that the attribute with the given key should
+// be written to.
+func (h *handler) attrBuffer(buf, hdr *Buffer, key string, groups []string) *Buffer {
+	if hdr != nil && slices.Contains(h.opts.HeaderKeys, qualifiedKey(key, groups)) {
+		return hdr
+	}
+	return buf
+}
+
+// qualifiedKey returns the key prefixed by the group names, separated by dots.
+func qualifiedKey(key string, groups []string) string {
+	if len(groups) == 0 {
+		return key
+	}
+	return strings.Join(groups, ".") + "." + key
+}
+
+func (h *handler) appendMessage(buf *Buffer, v slog.Value) {
+	buf.AppendString(h.opts.MessagePrefix)
+	appendValue(buf, v, false)
+	buf.AppendString(h.opts.MessageSuffix)
+}
+
+// resolveNested converts slices, arrays and maps of [slog.LogValuer]s into
+// group values, so that they are rendered as nested groups rather than being
+// formatted with [fmt.Sprint]. Elements are keyed by their index or map key.
+func resolveNested(v slog.Value, depth int) (slog.Value, bool) {
+	if v.Kind() != slog.KindAny || v.Any() == nil {
+		return v, false
+	}
+	rv := reflect.ValueOf(v.Any())
+	if !isNestedLogValuer(rv.Type(), depth) {
+		return v, false
+	}
+
+	var attrs []slog.Attr
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		attrs = make([]slog.Attr, rv.Len())
+		for i := range attrs {
+			attrs[i] = slog.Attr{
+				Key:   strconv.Itoa(i),
+				Value: resolveElem(rv.Index(i), depth),
+			}
+		}
+	case reflect.Map:
+		attrs = make([]slog.Attr, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			attrs = append(attrs, slog.Attr{
+				Key:   fmt.Sprint(iter.Key().Interface()),
+				Value: resolveElem(iter.Value(), depth),
+			})
+		}
+		slices.SortFunc(attrs, func(a, b slog.Attr) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+	default:
+		return v, false
+	}
+	return slog.GroupValue(attrs...), true
+}
+
+// resolveElem resolves an element of a slice, array or map.
+func resolveElem(rv reflect.Value, depth int) slog.Value {
+	v := slog.AnyValue(rv.Interface()).Resolve()
+	if nested, ok := resolveNested(v, depth+1); ok {
+		return nested
+	}
+	return v
+}
+
+// isNestedLogValuer reports whether t is a slice, array or map with elements
+// that implement [slog.LogValuer], directly or through nested containers.
+func isNestedLogValuer(t reflect.Type, depth int) bool {
+	if depth >= maxResolveDepth {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		elem := t.Elem()
+		if elem.Implements(logValuerType) {
+			return true
+		}
+		return isNestedLogValuer(elem, depth+1)
+	default:
+		return false
+	}
+}
+
+func (h *handler) appendKey(buf *Buffer, key string, groups []string) {
+	h.appendKeySep(buf, key, groups, h.opts.KeyValueSeparator)
+}
+
+// appendKeySep writes the key prefixed by the group names, followed by sep.
+func (h *handler) appendKeySep(buf *Buffer, key string, groups []string, sep string) {
 	if !h.opts.DisableColor {
 		buf.AppendString(ansiFaint)
 		defer buf.AppendString(ansiReset)
 	}
-	appendString(buf, groups+key, true)
-	buf.AppendByte('=')
+	if needsQuoting(key) || slices.ContainsFunc(groups, needsQuoting) {
+		buf.AppendQuote(qualifiedKey(key, groups))
+	} else {
+		for _, g := range groups {
+			buf.AppendString(g)
+			buf.AppendByte('.')
+		}
+		buf.AppendString(key)
+	}
+	buf.AppendString(sep)
 }
 
 // nolint: cyclop
-func (h *handler) appendValue(buf *Buffer, v slog.Value, quote bool) {
+func appendValue(buf *Buffer, v slog.Value, quote bool) {
 	switch v.Kind() {
 	case slog.KindString:
 		appendString(buf, v.String(), quote)
@@ -300,16 +777,7 @@ func (h *handler) appendValue(buf *Buffer, v slog.Value, quote bool) {
 	case slog.KindTime:
 		appendString(buf, v.Time().String(), quote)
 	case slog.KindAny, slog.KindLogValuer:
-		if tm, ok := v.Any().(encoding.TextMarshaler); ok {
-			b, err := tm.MarshalText()
-			if err != nil {
-				break
-			}
-			appendString(buf, string(b), quote)
-			return
-		}
-
-		appendString(buf, fmt.Sprint(v.Any()), quote)
+		appendAny(buf, v.Any(), quote)
 	case slog.KindGroup:
 		// Nothing to do
 	}
@@ -30,6 +30,7 @@ import (
 	"io"
 	"log/slog"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -37,7 +38,7 @@ import (
 	"time"
 )
 
-var levelRegexp = regexp.MustCompile("(DBG|INF|WRN|ERR)([+-][0-9]+)?")
+var levelRegexp = regexp.MustCompile("(DBG|INF|WRN|ERR|FTL)([+-][0-9]+)?")
 
 func TestHandler(t *testing.T) {
 	bufs := make(map[string]*bytes.Buffer)
@@ -65,6 +66,297 @@ func TestHandler(t *testing.T) {
 	slogtest.Run(t, newHandler, result)
 }
 
+func TestHandlerReplaceAttrGroups(t *testing.T) {
+	var calls []string
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{
+		DisableColor: true,
+		OmitTime:     true,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			calls = append(calls, strings.Join(append(groups, a.Key), "/"))
+			if a.Key == "secret" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	l.WithGroup("h").With("w", 0).WithGroup("empty").Info("msg",
+		slog.Group("a", "b", 1, "secret", "x"),
+		slog.Group("", "inline", 2),
+		slog.Group("none"),
+	)
+
+	wantCalls := []string{
+		"h/w", "level", "msg", "h/empty/a/b", "h/empty/a/secret", "h/empty/inline",
+	}
+	if !slices.Equal(calls, wantCalls) {
+		t.Errorf("ReplaceAttr calls = %q, want %q", calls, wantCalls)
+	}
+	want := "INFO msg h.w=0 h.empty.a.b=1 h.empty.inline=2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHandlerSeparators(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{
+		DisableColor:      true,
+		KeyValueSeparator: ": ",
+		AttrSeparator:     ", ",
+		MessagePrefix:     "[",
+		MessageSuffix:     "]",
+		TimeFormatter:     func(*Buffer, time.Time) {},
+	}))
+	l.With("a", 1).Info("hello", "b", "two")
+	l.Info("no attrs")
+
+	want := "INF [hello] a: 1, b: two\nINF [no attrs]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSourceFormatters(t *testing.T) {
+	src := &slog.Source{File: "/home/user/project/pkg/main.go", Line: 42}
+	tests := []struct {
+		name string
+		f    SourceFormatter
+		want string
+	}{
+		{name: "default", f: DefaultSourceFormatter(false), want: "<pkg/main.go:42>"},
+		{name: "absolute", f: AbsoluteSourceFormatter(false), want: "/home/user/project/pkg/main.go:42"},
+	}
+	for _, tt := range tests {
+		buf := newBuffer(0)
+		tt.f(buf, src)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultLevelFormatterHighLevels(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{level: slog.LevelError + 3, want: "ERR+3"},
+		{level: LevelFatal, want: "FTL"},
+		{level: LevelFatal + 1, want: "ERR+5"},
+	}
+	for _, tt := range tests {
+		buf := newBuffer(0)
+		DefaultLevelFormatter(false)(buf, tt.level)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("DefaultLevelFormatter(%v) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestIconLevelFormatter(t *testing.T) {
+	tests := []struct {
+		icons    LevelIcons
+		showName bool
+		level    slog.Level
+		want     string
+	}{
+		{icons: EmojiLevelIcons, level: slog.LevelInfo, want: "ℹ️"},
+		{icons: EmojiLevelIcons, showName: true, level: slog.LevelWarn + 1, want: "⚠️ WRN+1"},
+		{icons: NerdFontLevelIcons, level: slog.LevelError, want: "\uf057"},
+		{icons: LevelIcons{Debug: "D", Info: "🐛"}, level: slog.LevelDebug, want: "D "},
+	}
+	for _, tt := range tests {
+		buf := newBuffer(0)
+		IconLevelFormatter(tt.icons, tt.showName, false)(buf, tt.level)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("IconLevelFormatter(%v) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerMultiline(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{
+		DisableColor: true,
+		Multiline:    true,
+		OmitTime:     true,
+	}))
+	l.With("a", 1).Info("hello", slog.Group("g", "b", "two", "c", 3))
+	l.Info("no attrs")
+
+	want := "INF hello\n  a=1\n  g.b=two\n  g.c=3\nINF no attrs\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHandlerGroupBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		args []any
+		want string
+	}{
+		{
+			name: "inline",
+			opts: Options{GroupBlockAttrs: 2},
+			args: []any{slog.Group("g", "a", 1, "b", 2), "c", 3},
+			want: "INF msg g.a=1 g.b=2 c=3\n",
+		},
+		{
+			name: "attrs",
+			opts: Options{GroupBlockAttrs: 2},
+			args: []any{slog.Group("g", "a", 1, "b", 2, "c", 3), "d", 4},
+			want: "INF msg g:\n    a=1\n    b=2\n    c=3\n  d=4\n",
+		},
+		{
+			name: "width",
+			opts: Options{GroupBlockWidth: 10},
+			args: []any{slog.Group("g", "a", "long value", slog.Group("h", "b", 2))},
+			want: "INF msg g:\n    a=\"long value\"\n    h.b=2\n",
+		},
+		{
+			name: "nested",
+			opts: Options{GroupBlockAttrs: 1},
+			args: []any{slog.Group("g", "a", 1, slog.Group("h", "b", 2, "c", 3))},
+			want: "INF msg g:\n    a=1\n    h:\n      b=2\n      c=3\n",
+		},
+		{
+			name: "multiline",
+			opts: Options{GroupBlockAttrs: 1, Multiline: true},
+			args: []any{"a", 1, slog.Group("g", "b", 2, "c", 3), "d", 4},
+			want: "INF msg\n  a=1\n  g:\n    b=2\n    c=3\n  d=4\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tt.opts.DisableColor = true
+			tt.opts.OmitTime = true
+			slog.New(NewHandler(buf, &tt.opts)).Info("msg", tt.args...)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// countingValuer counts the calls to its LogValue method.
+type countingValuer struct {
+	calls *int
+}
+
+func (v countingValuer) LogValue() slog.Value {
+	*v.calls++
+	return slog.StringValue("value")
+}
+
+func TestHandlerGroupBlockWidthReplaceOnce(t *testing.T) {
+	buf := new(bytes.Buffer)
+	calls := make(map[string]int)
+	var logValueCalls int
+	l := slog.New(NewHandler(buf, &Options{
+		DisableColor:    true,
+		OmitTime:        true,
+		GroupBlockWidth: 10,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			calls[qualifiedKey(a.Key, groups)]++
+			return a
+		},
+	}))
+	l.Info("msg", slog.Group("g", "a", "long value", "v", countingValuer{&logValueCalls}, slog.Group("h", "b", 2)))
+
+	want := "INFO msg g:\n    a=\"long value\"\n    v=value\n    h.b=2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, key := range []string{"g.a", "g.v", "g.h.b"} {
+		if calls[key] != 1 {
+			t.Errorf("ReplaceAttr called %d times for %q, want once", calls[key], key)
+		}
+	}
+	if logValueCalls != 1 {
+		t.Errorf("LogValue called %d times, want once", logValueCalls)
+	}
+}
+
+func TestHandlerHeaderKeys(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{
+		DisableColor:  true,
+		HeaderKeys:    []string{"request_id", "trace.id"},
+		TimeFormatter: func(*Buffer, time.Time) {},
+	}))
+	l = l.With("a", 1, "request_id", "abc")
+	l.Info("hello", "b", 2, slog.Group("trace", "id", "xyz", "span", 3))
+
+	want := "INF hello request_id=abc trace.id=xyz a=1 b=2 trace.span=3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHandlerOmitTime(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{DisableColor: true, OmitTime: true}))
+	l.Info("hello", "a", 1)
+
+	if got, want := buf.String(), "INF hello a=1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type testUser struct {
+	id   int
+	name string
+}
+
+func (u testUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.id), slog.String("name", u.name))
+}
+
+func TestHandlerNestedLogValuers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			name:  "slice",
+			value: []testUser{{1, "alice"}, {2, "bob"}},
+			want:  "users.0.id=1 users.0.name=alice users.1.id=2 users.1.name=bob",
+		},
+		{
+			name:  "map",
+			value: map[string]testUser{"b": {2, "bob"}, "a": {1, "alice"}},
+			want:  "users.a.id=1 users.a.name=alice users.b.id=2 users.b.name=bob",
+		},
+		{
+			name:  "nested",
+			value: [][]testUser{{{1, "alice"}}},
+			want:  "users.0.0.id=1 users.0.0.name=alice",
+		},
+		{
+			name:  "plain",
+			value: []int{1, 2},
+			want:  `users="[1 2]"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			l := slog.New(NewHandler(buf, &Options{DisableColor: true}))
+			l.Info("msg", "users", tt.value)
+
+			_, got, _ := strings.Cut(strings.TrimSpace(buf.String()), "msg ")
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func BenchmarkDefaultTextHandler(b *testing.B) {
 	l := slog.New(slog.NewTextHandler(io.Discard, nil))
 	b.ResetTimer()
@@ -191,6 +483,8 @@ func parseLevel(s string) (slog.Level, error) {
 		return slog.LevelWarn + delta, nil
 	case "ERR":
 		return slog.LevelError + delta, nil
+	case "FTL":
+		return LevelFatal + delta, nil
 	default:
 		return 0, fmt.Errorf("unknown level (%q): %q", s, groups[1])
 	}
@@ -0,0 +1,88 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"log/slog"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// Keys of attributes added by [Options.IncludeHost], [Options.IncludePID] and
+// [Options.IncludeGoroutineID].
+const (
+	HostKey      = "host"
+	PIDKey       = "pid"
+	GoroutineKey = "goroutine"
+)
+
+// hostname returns the cached hostname of the machine.
+var hostname = sync.OnceValue(func() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return host
+})
+
+// processAttrs returns the per-process attributes enabled in the options.
+// These are computed once when the handler is created.
+func processAttrs(opts *Options) []slog.Attr {
+	var attrs []slog.Attr
+	if opts.IncludeHost {
+		if host := hostname(); host != "" {
+			attrs = append(attrs, slog.String(HostKey, host))
+		}
+	}
+	if opts.IncludePID {
+		attrs = append(attrs, slog.Int(PIDKey, os.Getpid()))
+	}
+	return attrs
+}
+
+// goroutineID returns the ID of the current goroutine, parsed from the
+// header of its stack trace, or 0 if it cannot be determined.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+
+	// The stack trace starts with "goroutine 123 [running]:"
+	const prefix = "goroutine "
+	if len(b) < len(prefix) || string(b[:len(prefix)]) != prefix {
+		return 0
+	}
+	b = b[len(prefix):]
+	i := 0
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		i++
+	}
+	id, err := strconv.ParseUint(string(b[:i]), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,60 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestHandlerInjectedAttrs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{
+		DisableColor:       true,
+		OmitTime:           true,
+		IncludeHost:        true,
+		IncludePID:         true,
+		IncludeGoroutineID: true,
+	}))
+	l.WithGroup("g").Info("hello", "a", 1)
+
+	want := regexp.MustCompile(`^INF hello host=\S+ pid=` + strconv.Itoa(os.Getpid()) +
+		` goroutine=[1-9][0-9]* g\.a=1\n$`)
+	if got := buf.String(); !want.MatchString(got) {
+		t.Errorf("got %q, want match for %s", got, want)
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	ids := make(chan uint64)
+	go func() { ids <- goroutineID() }()
+	if a, b := goroutineID(), <-ids; a == 0 || b == 0 || a == b {
+		t.Errorf("goroutineID() = %d and %d, want distinct non-zero IDs", a, b)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Flusher is implemented by handlers and writers that buffer output.
+type Flusher interface {
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+}
+
+// Flush flushes the given handler and every handler wrapped by it.
+//
+// Handlers that wrap other handlers should implement an Unwrap() slog.Handler
+// or Handlers() []slog.Handler method so that they can be traversed.
+func Flush(h slog.Handler) error {
+	return walkHandlers(h, func(h slog.Handler) error {
+		if f, ok := h.(Flusher); ok {
+			return f.Flush()
+		}
+		return nil
+	})
+}
+
+// Close flushes and closes the given handler and every handler wrapped by it.
+// Handlers that do not implement [io.Closer] are flushed if they implement
+// [Flusher].
+//
+// Handlers returned by [NewHandler] close their writer if it implements
+// [io.Closer], unless it is [os.Stdout] or [os.Stderr]. As handlers created
+// with WithAttrs and WithGroup share a writer, closing one of them closes the
+// writer for all of them.
+func Close(h slog.Handler) error {
+	return walkHandlers(h, func(h slog.Handler) error {
+		switch c := h.(type) {
+		case io.Closer:
+			return c.Close()
+		case Flusher:
+			return c.Flush()
+		default:
+			return nil
+		}
+	})
+}
+
+// walkHandlers calls fn for h and every handler wrapped by h.
+func walkHandlers(h slog.Handler, fn func(h slog.Handler) error) error {
+	if h == nil {
+		return nil
+	}
+	err := fn(h)
+	switch w := h.(type) {
+	case interface{ Unwrap() slog.Handler }:
+		err = errors.Join(err, walkHandlers(w.Unwrap(), fn))
+	case interface{ Handlers() []slog.Handler }:
+		for _, h := range w.Handlers() {
+			err = errors.Join(err, walkHandlers(h, fn))
+		}
+	}
+	return err
+}
+
+// Flush implements [Flusher] by flushing the handler's writers that
+// implement [Flusher].
+func (h *handler) Flush() error {
+	return h.eachWriter(flushWriter)
+}
+
+// Close implements [io.Closer] by flushing and closing the handler's writers.
+// [os.Stdout] and [os.Stderr] are never closed.
+func (h *handler) Close() error {
+	return h.eachWriter(closeWriter)
+}
+
+func flushWriter(w io.Writer) error {
+	if f, ok := w.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func closeWriter(w io.Writer) error {
+	err := flushWriter(w)
+	if w == os.Stdout || w == os.Stderr {
+		return err
+	}
+	if c, ok := w.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
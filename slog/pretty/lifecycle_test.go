@@ -0,0 +1,80 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+type wrapHandler struct {
+	slog.Handler
+}
+
+func (w wrapHandler) Unwrap() slog.Handler {
+	return w.Handler
+}
+
+func TestFlush(t *testing.T) {
+	out := new(bytes.Buffer)
+	bw := bufio.NewWriter(out)
+	h := wrapHandler{NewHandler(bw, &Options{DisableColor: true})}
+
+	_ = h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0))
+	if out.Len() != 0 {
+		t.Fatalf("expected buffered output, got %q", out.String())
+	}
+	if err := Flush(h); err != nil {
+		t.Fatalf("Flush() = %v", err)
+	}
+	if got := out.String(); got != "INF hello\n" {
+		t.Errorf("got %q, want %q", got, "INF hello\n")
+	}
+}
+
+func TestClose(t *testing.T) {
+	w := new(closeRecorder)
+	h := wrapHandler{NewHandler(w, nil)}
+	if err := Close(h.WithAttrs([]slog.Attr{slog.Int("a", 1)})); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if !w.closed {
+		t.Error("expected writer to be closed")
+	}
+}
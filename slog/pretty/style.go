@@ -0,0 +1,117 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Color is one of the standard ANSI terminal colours.
+type Color uint8
+
+const (
+	// ColorDefault leaves the terminal's colour unchanged.
+	ColorDefault Color = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+// Style describes how a whole record is highlighted, see
+// [Options.RecordStyle].
+type Style struct {
+	// Foreground and Background are the text and background colours.
+	Foreground, Background Color
+
+	// Bold, Underline and Reverse enable the corresponding text attributes.
+	Bold, Underline, Reverse bool
+}
+
+// sequence returns the ANSI escape sequence for the style, or an empty
+// string if the style has no effect.
+func (s *Style) sequence() string {
+	codes := make([]string, 0, 5)
+	if s.Bold {
+		codes = append(codes, "1")
+	}
+	if s.Underline {
+		codes = append(codes, "4")
+	}
+	if s.Reverse {
+		codes = append(codes, "7")
+	}
+	if s.Foreground != ColorDefault {
+		codes = append(codes, strconv.Itoa(29+int(s.Foreground)))
+	}
+	if s.Background != ColorDefault {
+		codes = append(codes, strconv.Itoa(39+int(s.Background)))
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(codes, ";") + "m"
+}
+
+// appendStyled writes src to dst with the style applied. The style is
+// written again after every reset sequence in src, so that it is not
+// cancelled by colours within the line, and is reset at the end of each line.
+func (s *Style) appendStyled(dst *Buffer, src []byte) {
+	seq := s.sequence()
+	if seq == "" {
+		dst.AppendBytes(src)
+		return
+	}
+
+	reset := []byte(ansiReset)
+	dst.AppendString(seq)
+	for len(src) > 0 {
+		i := bytes.IndexByte(src, '\n')
+		j := bytes.Index(src, reset)
+		switch {
+		case j >= 0 && (i < 0 || j < i):
+			dst.AppendBytes(src[:j])
+			dst.AppendString(ansiReset)
+			dst.AppendString(seq)
+			src = src[j+len(reset):]
+		case i >= 0:
+			dst.AppendBytes(src[:i])
+			dst.AppendString(ansiReset)
+			dst.AppendByte('\n')
+			dst.AppendString(seq)
+			src = src[i+1:]
+		default:
+			dst.AppendBytes(src)
+			src = nil
+		}
+	}
+	dst.AppendString(ansiReset)
+}
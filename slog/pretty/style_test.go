@@ -0,0 +1,88 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestStyleSequence(t *testing.T) {
+	tests := []struct {
+		style Style
+		want  string
+	}{
+		{style: Style{}, want: ""},
+		{style: Style{Bold: true}, want: "\033[1m"},
+		{style: Style{Foreground: ColorRed}, want: "\033[31m"},
+		{style: Style{Foreground: ColorWhite, Background: ColorRed, Underline: true}, want: "\033[4;37;41m"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.sequence(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestStyleAppendStyled(t *testing.T) {
+	style := &Style{Background: ColorRed}
+	buf := newBuffer(0)
+	style.appendStyled(buf, []byte("a \033[2mb\033[0m c\n  d"))
+
+	want := "\033[41ma \033[2mb\033[0m\033[41m c\033[0m\n\033[41m  d\033[0m"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHandlerRecordStyle(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{
+		OmitTime: true,
+		RecordStyle: func(record slog.Record) *Style {
+			var audit bool
+			record.Attrs(func(attr slog.Attr) bool {
+				if attr.Key == "audit" {
+					audit = attr.Value.Bool()
+					return false
+				}
+				return true
+			})
+			if audit {
+				return &Style{Background: ColorRed}
+			}
+			return nil
+		},
+	}))
+	l.Info("normal")
+	l.Info("audited", "audit", true)
+
+	want := "\033[1;36mINF\033[0m normal\n" +
+		"\033[41m\033[1;36mINF\033[0m\033[41m audited \033[2maudit=\033[0m\033[41mtrue\033[0m\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,389 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateColors maps colour names usable in templates to ANSI sequences.
+var templateColors = map[string]string{
+	"bold":    "\033[1m",
+	"faint":   ansiFaint,
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+	"white":   "\033[37m",
+}
+
+// TemplateRecord is the data passed to templates used by a handler created
+// with [NewTemplateHandler]. The fields and attributes are the values after
+// [Options.ReplaceAttr] is applied.
+type TemplateRecord struct {
+	// Time is the time of the record.
+	Time time.Time
+
+	// Level is the level of the record.
+	Level slog.Level
+
+	// Message is the log message.
+	Message string
+
+	// Source is the source code position of the log statement.
+	// Source is nil unless [Options.AddSource] is enabled.
+	Source *slog.Source
+
+	h         *handler
+	attrs     []slog.Attr // flattened attributes
+	replaced  []slog.Attr // record attributes, for FormattedAttrs
+	goroutine slog.Attr
+
+	// The built-in attributes after ReplaceAttr, with an empty key if they
+	// were removed.
+	timeAttr, levelAttr, sourceAttr slog.Attr
+}
+
+// Attrs returns the handler and record attributes. Attributes in groups are
+// flattened, with keys qualified by their group names (e.g. "group.key").
+func (r *TemplateRecord) Attrs() []slog.Attr {
+	return r.attrs
+}
+
+// Attr returns the value of the attribute with the given qualified key, or
+// nil if the record has no such attribute.
+func (r *TemplateRecord) Attr(key string) any {
+	for i := len(r.attrs) - 1; i >= 0; i-- {
+		if r.attrs[i].Key == key {
+			return r.attrs[i].Value.Any()
+		}
+	}
+	return nil
+}
+
+// FormattedTime returns the time formatted by [Options.TimeFormatter], or an
+// empty string if it was removed by [Options.ReplaceAttr].
+func (r *TemplateRecord) FormattedTime() string {
+	return r.formatBuiltin(r.timeAttr, func(buf *Buffer, v slog.Value) bool {
+		if v.Kind() != slog.KindTime {
+			return false
+		}
+		r.h.opts.TimeFormatter(buf, v.Time())
+		return true
+	})
+}
+
+// FormattedLevel returns the level formatted by [Options.LevelFormatter], or
+// an empty string if it was removed by [Options.ReplaceAttr].
+func (r *TemplateRecord) FormattedLevel() string {
+	return r.formatBuiltin(r.levelAttr, func(buf *Buffer, v slog.Value) bool {
+		level, ok := v.Any().(slog.Level)
+		if ok {
+			r.h.opts.LevelFormatter(buf, level)
+		}
+		return ok
+	})
+}
+
+// FormattedSource returns the source formatted by [Options.SourceFormatter],
+// or an empty string if the source is not available.
+func (r *TemplateRecord) FormattedSource() string {
+	return r.formatBuiltin(r.sourceAttr, func(buf *Buffer, v slog.Value) bool {
+		src, ok := v.Any().(*slog.Source)
+		if ok {
+			r.h.opts.SourceFormatter(buf, src)
+		}
+		return ok
+	})
+}
+
+// FormattedAttrs returns the attributes formatted in the same way as by the
+// handler returned by [NewHandler].
+func (r *TemplateRecord) FormattedAttrs() string {
+	return r.format(func(buf *Buffer) {
+		var record slog.Record
+		record.AddAttrs(r.replaced...)
+		r.h.appendRecordAttrs(buf, record, r.goroutine)
+		if buf.Len() > 0 {
+			buf.Truncate(buf.Len() - r.h.trailingSeparator(buf))
+		}
+	})
+}
+
+// formatBuiltin formats the value of a built-in attribute with fn, or as a
+// value if fn reports that it is not of the expected type, such as when it
+// was changed by [Options.ReplaceAttr].
+func (r *TemplateRecord) formatBuiltin(attr slog.Attr, fn func(buf *Buffer, v slog.Value) bool) string {
+	if attr.Key == "" {
+		return ""
+	}
+	return r.format(func(buf *Buffer) {
+		if !fn(buf, attr.Value) {
+			appendValue(buf, attr.Value, false)
+		}
+	})
+}
+
+func (r *TemplateRecord) format(fn func(buf *Buffer)) string {
+	buf := r.h.bufferPool.Acquire()
+	defer r.h.bufferPool.Free(buf)
+	fn(buf)
+	return buf.String()
+}
+
+// templateHandler is a [slog.Handler] that formats records using a template.
+//
+// ReplaceAttr is called exactly once for each attribute, by the template
+// handler. The attributes are then formatted by fh, a handler without
+// ReplaceAttr.
+type templateHandler struct {
+	h     *handler
+	fh    *handler
+	tmpl  *template.Template
+	attrs []slog.Attr
+}
+
+// NewTemplateHandler returns a [slog.Handler] that formats each record by
+// executing the given [text/template] with a [*TemplateRecord]. The template
+// is parsed once, and an error is returned if it is invalid.
+//
+// The following functions are available in addition to the standard template
+// functions:
+//
+//   - color NAME TEXT: wraps TEXT in an ANSI colour sequence, unless colours
+//     are disabled. NAME is one of bold, faint, red, green, yellow, blue,
+//     magenta, cyan or white.
+//   - pad WIDTH TEXT: pads TEXT with spaces on the right to WIDTH columns.
+//   - padLeft WIDTH TEXT: pads TEXT with spaces on the left to WIDTH columns.
+//
+// For example:
+//
+//	{{.FormattedTime}} {{pad 5 .FormattedLevel}} {{color "bold" .Message}} {{.FormattedAttrs}}
+//
+// A trailing newline is added to the output if the template does not end
+// with one. The banner set with [Options.Banner] is formatted with the
+// template.
+func NewTemplateHandler(w io.Writer, text string, opts *Options) (slog.Handler, error) {
+	h := initHandler(w, opts)
+	tmpl, err := template.New("record").Funcs(templateFuncs(!h.opts.DisableColor)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	fopts := *h.opts
+	fopts.ReplaceAttr = nil
+	fh := h.clone()
+	fh.opts = &fopts
+
+	var t slog.Handler = &templateHandler{h: h, fh: fh, tmpl: tmpl}
+	t = t.WithAttrs(processAttrs(h.opts))
+	if h.opts.Banner != nil {
+		_ = WriteBanner(context.Background(), t, h.opts.Banner)
+	}
+	return t, nil
+}
+
+func templateFuncs(color bool) template.FuncMap {
+	return template.FuncMap{
+		"color": func(name string, v any) (string, error) {
+			s := fmt.Sprint(v)
+			seq, ok := templateColors[name]
+			if !ok {
+				return "", fmt.Errorf("unknown colour %q", name)
+			}
+			if !color {
+				return s, nil
+			}
+			return seq + s + ansiReset, nil
+		},
+		"pad": func(width int, v any) string {
+			s := fmt.Sprint(v)
+			return s + strings.Repeat(" ", max(width-visibleWidthBytes([]byte(s)), 0))
+		},
+		"padLeft": func(width int, v any) string {
+			s := fmt.Sprint(v)
+			return strings.Repeat(" ", max(width-visibleWidthBytes([]byte(s)), 0)) + s
+		},
+	}
+}
+
+// Enabled implements [slog.Handler.Enabled].
+func (t *templateHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return t.h.Enabled(ctx, level)
+}
+
+// Handle implements [slog.Handler.Handle].
+func (t *templateHandler) Handle(_ context.Context, record slog.Record) error {
+	data := &TemplateRecord{
+		Time:    record.Time.Round(0),
+		Level:   record.Level,
+		Message: record.Message,
+		h:       t.fh,
+		attrs:   slices.Clip(t.attrs),
+	}
+	if !t.h.opts.OmitTime && !data.Time.IsZero() {
+		data.timeAttr = slog.Time(slog.TimeKey, data.Time)
+	}
+	data.levelAttr = slog.Any(slog.LevelKey, data.Level)
+	if t.h.opts.AddSource {
+		data.Source = recordSource(record)
+		if data.Source != nil {
+			data.sourceAttr = slog.Any(slog.SourceKey, data.Source)
+		}
+	}
+	if rep := t.h.opts.ReplaceAttr; rep != nil {
+		t.replaceBuiltins(data, rep)
+	}
+
+	if t.h.opts.IncludeGoroutineID {
+		data.goroutine = t.h.replaceAttr(slog.Uint64(GoroutineKey, goroutineID()), nil)
+		data.attrs = t.h.flattenGroupAttr(data.attrs, data.goroutine, nil)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		attr = t.h.replaceAttr(attr, t.h.groups)
+		data.attrs = t.h.flattenAttr(data.attrs, attr)
+		data.replaced = append(data.replaced, attr)
+		return true
+	})
+
+	buf := t.h.bufferPool.Acquire()
+	defer t.h.bufferPool.Free(buf)
+	if err := t.tmpl.Execute(buf, data); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	if buf.Len() == 0 || buf.buf[buf.Len()-1] != '\n' {
+		buf.AppendByte('\n')
+	}
+
+	return t.h.write(record.Level, buf)
+}
+
+// replaceBuiltins calls rep for the built-in attributes of the record, like
+// the base handler does.
+func (t *templateHandler) replaceBuiltins(data *TemplateRecord, rep ReplaceAttrFunc) {
+	if data.timeAttr.Key != "" {
+		data.timeAttr = rep(nil, data.timeAttr)
+		if data.timeAttr.Key == "" {
+			data.Time = time.Time{}
+		} else if data.timeAttr.Value.Kind() == slog.KindTime {
+			data.Time = data.timeAttr.Value.Time()
+		}
+	}
+
+	data.levelAttr = rep(nil, data.levelAttr)
+	if level, ok := data.levelAttr.Value.Any().(slog.Level); ok && data.levelAttr.Key != "" {
+		data.Level = level
+	}
+
+	if data.Source != nil {
+		data.sourceAttr = rep(nil, data.sourceAttr)
+		src, _ := data.sourceAttr.Value.Any().(*slog.Source)
+		if data.sourceAttr.Key == "" || src != nil {
+			data.Source = src
+		}
+	}
+
+	msg := rep(nil, slog.String(slog.MessageKey, data.Message))
+	data.Message = ""
+	if msg.Key != "" {
+		data.Message = msg.Value.String()
+	}
+}
+
+// WithAttrs implements [slog.Handler.WithAttrs].
+func (t *templateHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return t
+	}
+	flat := slices.Clip(t.attrs)
+	replaced := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		attr = t.h.replaceAttr(attr, t.h.groups)
+		flat = t.h.flattenAttr(flat, attr)
+		replaced = append(replaced, attr)
+	}
+	return &templateHandler{
+		h:     t.h,
+		fh:    t.fh.WithAttrs(replaced).(*handler),
+		tmpl:  t.tmpl,
+		attrs: flat,
+	}
+}
+
+// WithGroup implements [slog.Handler.WithGroup].
+func (t *templateHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return t
+	}
+	return &templateHandler{
+		h:     t.h.WithGroup(name).(*handler),
+		fh:    t.fh.WithGroup(name).(*handler),
+		tmpl:  t.tmpl,
+		attrs: t.attrs,
+	}
+}
+
+// Flush implements [Flusher].
+func (t *templateHandler) Flush() error {
+	return t.h.Flush()
+}
+
+// Close implements [io.Closer].
+func (t *templateHandler) Close() error {
+	return t.h.Close()
+}
+
+// flattenAttr appends the attribute to dst, flattening groups into attributes
+// with qualified keys. The attribute must already be replaced with
+// [handler.replaceAttr].
+func (h *handler) flattenAttr(dst []slog.Attr, attr slog.Attr) []slog.Attr {
+	return h.flattenGroupAttr(dst, attr, h.groups)
+}
+
+func (h *handler) flattenGroupAttr(dst []slog.Attr, attr slog.Attr, groups []string) []slog.Attr {
+	if attr.Equal(emptyAttr) {
+		return dst
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			groups = append(slices.Clip(groups), attr.Key)
+		}
+		for _, a := range attr.Value.Group() {
+			dst = h.flattenGroupAttr(dst, a, groups)
+		}
+		return dst
+	}
+	attr.Key = qualifiedKey(attr.Key, groups)
+	return append(dst, attr)
+}
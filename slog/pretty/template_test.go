@@ -0,0 +1,155 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTemplateHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h, err := NewTemplateHandler(buf,
+		`{{pad 4 .FormattedLevel}}|{{.Message}}|{{.Attr "req.id"}}|{{.FormattedAttrs}}`,
+		&Options{DisableColor: true},
+	)
+	if err != nil {
+		t.Fatalf("NewTemplateHandler() = %v", err)
+	}
+	l := slog.New(h).WithGroup("req").With("id", 42)
+	l.Info("hello", "path", "/")
+
+	want := "INF |hello|42|req.id=42 req.path=/\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateHandlerColor(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h, err := NewTemplateHandler(buf, `{{padLeft 7 (color "red" .Message)}}`, &Options{
+		TimeFormatter: func(*Buffer, time.Time) {},
+	})
+	if err != nil {
+		t.Fatalf("NewTemplateHandler() = %v", err)
+	}
+	slog.New(h).Info("hello")
+
+	want := "  \033[31mhello\033[0m\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateHandlerInvalid(t *testing.T) {
+	if _, err := NewTemplateHandler(new(bytes.Buffer), "{{.Message", nil); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
+
+func TestTemplateHandlerReplaceAttr(t *testing.T) {
+	buf := new(bytes.Buffer)
+	calls := make(map[string]int)
+	h, err := NewTemplateHandler(buf,
+		`{{.FormattedTime}}|{{.FormattedLevel}}|{{.Message}}|{{.Attr "g.a"}}|{{.FormattedAttrs}}`,
+		&Options{
+			DisableColor: true,
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				calls[a.Key]++
+				switch {
+				case a.Key == slog.LevelKey:
+					return slog.Attr{}
+				case a.Key == slog.TimeKey:
+					return slog.String(a.Key, "now")
+				case a.Value.Kind() == slog.KindString:
+					// Not idempotent, so calling it twice would be visible
+					return slog.String(a.Key, a.Value.String()+"!")
+				}
+				return a
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewTemplateHandler() = %v", err)
+	}
+	slog.New(h).WithGroup("g").With("a", "x").Info("hi", "b", "y")
+
+	want := "now||hi!|x!|g.a=x! g.b=y!\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for key, n := range calls {
+		if n != 1 {
+			t.Errorf("ReplaceAttr called %d times for %q, want once", n, key)
+		}
+	}
+	if len(calls) != 5 {
+		t.Errorf("ReplaceAttr calls = %v, want time, level, msg, a and b", calls)
+	}
+}
+
+func TestTemplateHandlerOmitTime(t *testing.T) {
+	var keys []string
+	opts := &Options{
+		DisableColor: true,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			keys = append(keys, a.Key)
+			return a
+		},
+	}
+	tests := []struct {
+		name     string
+		omitTime bool
+		time     time.Time
+	}{
+		{name: "OmitTime", omitTime: true, time: time.Now()},
+		{name: "zero time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys = nil
+			opts.OmitTime = tt.omitTime
+			buf := new(bytes.Buffer)
+			h, err := NewTemplateHandler(buf, `{{.FormattedTime}}|{{.Message}}`, opts)
+			if err != nil {
+				t.Fatalf("NewTemplateHandler() = %v", err)
+			}
+			if err := h.Handle(context.Background(), slog.NewRecord(tt.time, slog.LevelInfo, "hi", 0)); err != nil {
+				t.Fatalf("Handle() = %v", err)
+			}
+
+			if got, want := buf.String(), "|hi\n"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+			if slices.Contains(keys, slog.TimeKey) {
+				t.Errorf("ReplaceAttr called with keys %v, want no time", keys)
+			}
+		})
+	}
+}
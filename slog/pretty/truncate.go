@@ -0,0 +1,138 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"unicode/utf8"
+)
+
+// AutoWidth can be used as [Options.MaxWidth] to clip lines at the width of
+// the terminal, as detected by [TerminalWidth].
+const AutoWidth = -1
+
+// ellipsis is written at the end of clipped lines.
+const ellipsis = "…"
+
+// TerminalWidth returns the width of the terminal that w writes to, in
+// columns. If w is not a terminal, the COLUMNS environment variable is used.
+// TerminalWidth returns 0 if the width cannot be determined.
+func TerminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok {
+		if width := terminalWidth(f); width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// clipLines writes each line of src to dst, clipping lines that are wider
+// than width columns and ending them with an ellipsis. ANSI escape sequences
+// are copied but do not count towards the width, and a reset sequence is
+// written after the ellipsis if the line contained any escape sequences.
+func clipLines(dst *Buffer, src []byte, width int) {
+	for len(src) > 0 {
+		line, rest, found := bytes.Cut(src, []byte{'\n'})
+		clipLine(dst, line, width)
+		if found {
+			dst.AppendByte('\n')
+		}
+		src = rest
+	}
+}
+
+func clipLine(dst *Buffer, line []byte, width int) {
+	if visibleWidthBytes(line) <= width {
+		dst.AppendBytes(line)
+		return
+	}
+
+	limit := width - stringWidth(ellipsis)
+	cur := 0
+	clipped := false
+	escaped := false
+	for i := 0; i < len(line); {
+		if n := escapeLen(line[i:]); n > 0 {
+			// Escape sequences are kept, even after the line is clipped,
+			// so that colours are reset correctly.
+			escaped = true
+			dst.AppendBytes(line[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRune(line[i:])
+		i += size
+		if clipped {
+			continue
+		}
+		rw := runeWidth(r)
+		if cur+rw > limit {
+			clipped = true
+			dst.AppendString(ellipsis)
+			continue
+		}
+		cur += rw
+		dst.AppendBytes(line[i-size : i])
+	}
+	if escaped {
+		dst.AppendString(ansiReset)
+	}
+}
+
+// visibleWidthBytes returns the display width of b, ignoring ANSI escape
+// sequences.
+func visibleWidthBytes(b []byte) int {
+	width := 0
+	for i := 0; i < len(b); {
+		if n := escapeLen(b[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// escapeLen returns the length of the ANSI CSI escape sequence at the start
+// of b, or 0 if b does not start with an escape sequence.
+func escapeLen(b []byte) int {
+	if len(b) < 2 || b[0] != '\033' || b[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7E {
+			return i + 1
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,88 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestClipLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{name: "short", in: "hello\n", width: 10, want: "hello\n"},
+		{name: "exact", in: "hello\n", width: 5, want: "hello\n"},
+		{name: "clipped", in: "hello world\n", width: 8, want: "hello w…\n"},
+		{name: "multiple lines", in: "abcdef\nab\n", width: 4, want: "abc…\nab\n"},
+		{name: "wide runes", in: "日本語です\n", width: 6, want: "日本…\n"},
+		{
+			name:  "ansi",
+			in:    "\033[1mbold\033[0m text here\n",
+			width: 8,
+			want:  "\033[1mbold\033[0m te…\033[0m\n",
+		},
+		{
+			name:  "ansi after clip",
+			in:    "\033[31mred text\033[0m \033[1mmore\033[0m\n",
+			width: 6,
+			want:  "\033[31mred t…\033[0m\033[1m\033[0m\033[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer(0)
+			clipLines(buf, []byte(tt.in), tt.width)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerMaxWidth(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(NewHandler(buf, &Options{
+		DisableColor: true,
+		OmitTime:     true,
+		MaxWidth:     16,
+	}))
+	l.Info("hello", "key", "along_value")
+
+	if got, want := buf.String(), "INF hello key=a…\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTerminalWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "123")
+	if got := TerminalWidth(new(bytes.Buffer)); got != 123 {
+		t.Errorf("TerminalWidth() = %d, want 123", got)
+	}
+}
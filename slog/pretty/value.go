@@ -0,0 +1,101 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// appendAny writes an arbitrary value to the buffer.
+//
+// Common types are handled directly to avoid the allocations made by
+// [fmt.Sprint], which is only used as a fallback.
+//
+// nolint: cyclop
+func appendAny(buf *Buffer, v any, quote bool) {
+	if v == nil {
+		appendString(buf, "<nil>", quote)
+		return
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		appendString(buf, "<nil>", quote)
+		return
+	}
+
+	// Methods may panic, recover in the same way as the fmt package.
+	start := buf.Len()
+	defer func() {
+		if r := recover(); r != nil {
+			buf.Truncate(start)
+			appendString(buf, fmt.Sprintf("!PANIC: %v", r), quote)
+		}
+	}()
+
+	switch x := v.(type) {
+	case encoding.TextMarshaler:
+		b, err := x.MarshalText()
+		if err != nil {
+			appendString(buf, "!ERROR:"+err.Error(), quote)
+			return
+		}
+		appendString(buf, string(b), quote)
+		return
+	case error:
+		appendString(buf, x.Error(), quote)
+		return
+	case fmt.Stringer:
+		appendString(buf, x.String(), quote)
+		return
+	case json.Marshaler:
+		b, err := x.MarshalJSON()
+		if err != nil {
+			appendString(buf, "!ERROR:"+err.Error(), quote)
+			return
+		}
+		appendString(buf, string(b), quote)
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		buf.AppendBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.AppendInt(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.AppendUint(rv.Uint())
+	case reflect.Float32:
+		buf.AppendFloat(rv.Float(), 32)
+	case reflect.Float64:
+		buf.AppendFloat(rv.Float(), 64)
+	case reflect.String:
+		appendString(buf, rv.String(), quote)
+	default:
+		appendString(buf, fmt.Sprint(v), quote)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+type testStringer struct{}
+
+func (*testStringer) String() string { return "stringer" }
+
+type testJSON struct{}
+
+func (testJSON) MarshalJSON() ([]byte, error) { return []byte(`[1,2]`), nil }
+
+type testPanic struct{}
+
+func (testPanic) String() string { panic("boom") }
+
+type testInt int16
+
+func TestAppendAny(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{name: "nil", in: nil, want: "<nil>"},
+		{name: "nil pointer", in: (*testStringer)(nil), want: "<nil>"},
+		{name: "text marshaler", in: netip.MustParseAddr("127.0.0.1"), want: "127.0.0.1"},
+		{name: "error", in: errors.New("oops"), want: "oops"},
+		{name: "stringer", in: &testStringer{}, want: "stringer"},
+		{name: "json marshaler", in: testJSON{}, want: "[1,2]"},
+		{name: "panic", in: testPanic{}, want: `"!PANIC: boom"`},
+		{name: "int kind", in: testInt(-7), want: "-7"},
+		{name: "uint8", in: uint8(7), want: "7"},
+		{name: "float32", in: float32(1.5), want: "1.5"},
+		{name: "struct", in: struct{ A int }{1}, want: "{1}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newBuffer(0)
+			appendAny(buf, tt.in, true)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("appendAny(%#v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkAppendAny(b *testing.B) {
+	values := []any{errors.New("oops"), testInt(42), &testStringer{}, float32(1.5)}
+	buf := newBuffer(defaultBufferSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, v := range values {
+			appendAny(buf, v, true)
+		}
+		buf.Reset()
+	}
+}
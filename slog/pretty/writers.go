@@ -0,0 +1,121 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// levelWriter is a writer used for records at or above a level.
+type levelWriter struct {
+	level slog.Level
+	w     io.Writer
+	mu    *sync.Mutex
+}
+
+// newLevelWriters returns the level writers for the given map, sorted by
+// level. Writers that are used for multiple levels, or are the same as the
+// handler's writer, share a mutex.
+func newLevelWriters(m map[slog.Level]io.Writer, w io.Writer, mu *sync.Mutex) []levelWriter {
+	if len(m) == 0 {
+		return nil
+	}
+
+	mutexes := []levelWriter{{w: w, mu: mu}}
+	mutexFor := func(w io.Writer) *sync.Mutex {
+		if w != nil && reflect.TypeOf(w).Comparable() {
+			for _, lw := range mutexes {
+				if lw.w == w {
+					return lw.mu
+				}
+			}
+		}
+		mu := new(sync.Mutex)
+		mutexes = append(mutexes, levelWriter{w: w, mu: mu})
+		return mu
+	}
+
+	writers := make([]levelWriter, 0, len(m))
+	for level, w := range m {
+		writers = append(writers, levelWriter{level: level, w: w, mu: mutexFor(w)})
+	}
+	slices.SortFunc(writers, func(a, b levelWriter) int {
+		return int(a.level) - int(b.level)
+	})
+	return writers
+}
+
+// writer returns the writer and mutex to use for records of the given level.
+func (h *handler) writer(level slog.Level) (io.Writer, *sync.Mutex) {
+	for i := len(h.levelWriters) - 1; i >= 0; i-- {
+		if lw := h.levelWriters[i]; level >= lw.level {
+			return lw.w, lw.mu
+		}
+	}
+	return h.w, h.mu
+}
+
+// write writes the buffer to the writer for the given level, clipping lines
+// to [Options.MaxWidth] if enabled.
+func (h *handler) write(level slog.Level, buf *Buffer) error {
+	if h.maxWidth > 0 {
+		clipped := h.bufferPool.Acquire()
+		defer h.bufferPool.Free(clipped)
+		clipLines(clipped, buf.buf, h.maxWidth)
+		buf = clipped
+	}
+
+	w, mu := h.writer(level)
+	mu.Lock()
+	defer mu.Unlock()
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// eachWriter calls fn for each distinct writer used by the handler, while
+// holding the writer's mutex.
+func (h *handler) eachWriter(fn func(w io.Writer) error) error {
+	seen := []*sync.Mutex{h.mu}
+	err := withLock(h.mu, h.w, fn)
+	for _, lw := range h.levelWriters {
+		if slices.Contains(seen, lw.mu) {
+			continue
+		}
+		seen = append(seen, lw.mu)
+		err = errors.Join(err, withLock(lw.mu, lw.w, fn))
+	}
+	return err
+}
+
+func withLock(mu *sync.Mutex, w io.Writer, fn func(w io.Writer) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+	return fn(w)
+}
@@ -0,0 +1,87 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pretty
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestHandlerLevelWriters(t *testing.T) {
+	def, debug, errs := new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)
+	l := slog.New(NewHandler(def, &Options{
+		Level:        slog.LevelDebug,
+		DisableColor: true,
+		OmitTime:     true,
+		LevelWriters: map[slog.Level]io.Writer{
+			slog.LevelDebug: debug,
+			slog.LevelInfo:  def,
+			slog.LevelError: errs,
+		},
+	}))
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+	l.Log(context.Background(), LevelFatal, "fatal")
+
+	tests := []struct {
+		name string
+		buf  *bytes.Buffer
+		want string
+	}{
+		{name: "default", buf: def, want: "INF info\nWRN warn\n"},
+		{name: "debug", buf: debug, want: "DBG debug\n"},
+		{name: "error", buf: errs, want: "ERR error\nFTL fatal\n"},
+	}
+	for _, tt := range tests {
+		if got := tt.buf.String(); got != tt.want {
+			t.Errorf("%s writer: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewLevelWritersSharedMutex(t *testing.T) {
+	def, other := new(bytes.Buffer), new(bytes.Buffer)
+	h := newHandler(def, &Options{
+		LevelWriters: map[slog.Level]io.Writer{
+			slog.LevelDebug: other,
+			slog.LevelInfo:  def,
+			slog.LevelWarn:  other,
+		},
+	})
+	if len(h.levelWriters) != 3 {
+		t.Fatalf("got %d level writers, want 3", len(h.levelWriters))
+	}
+	if h.levelWriters[1].mu != h.mu {
+		t.Error("expected the handler's writer to share the handler's mutex")
+	}
+	if h.levelWriters[0].mu != h.levelWriters[2].mu {
+		t.Error("expected writers used for multiple levels to share a mutex")
+	}
+}
@@ -0,0 +1,272 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package bloom provides a Bloom filter, a compact set that can report false
+positives but never false negatives.
+
+	f := bloom.NewWithEstimates(1_000_000, 0.01)
+	f.AddString("alice")
+	f.TestString("alice") // true
+	f.TestString("bob")   // false, or true with a 1% probability
+
+Filters use a stable hash, so they can be serialised with MarshalBinary and
+exchanged between processes.
+*/
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+var (
+	// ErrIncompatible is returned when merging filters of different sizes or
+	// numbers of hash functions.
+	ErrIncompatible = errors.New("bloom: filters are incompatible")
+
+	// ErrInvalidData is returned when unmarshalling data that is not a
+	// filter.
+	ErrInvalidData = errors.New("bloom: invalid data")
+)
+
+// MaxK is the maximum number of hash functions of a filter.
+const MaxK = 1024
+
+// OptimalM returns the number of bits for a filter holding n items with a
+// false positive rate of p. p is clamped to the range (0, 1), so a p of zero
+// or less is treated as the smallest positive rate.
+func OptimalM(n uint, p float64) uint {
+	n = max(n, 1)
+	if p >= 1 {
+		return 1
+	}
+	if !(p > 0) {
+		p = math.SmallestNonzeroFloat64
+	}
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	return uint(max(m, 1))
+}
+
+// OptimalK returns the number of hash functions for a filter of m bits
+// holding n items, which is at most [MaxK].
+func OptimalK(m, n uint) uint {
+	n = max(n, 1)
+	return uint(min(max(math.Round(float64(m)/float64(n)*math.Ln2), 1), MaxK))
+}
+
+// FalsePositiveRate returns the expected false positive rate of a filter of
+// m bits using k hash functions, once it holds n items.
+func FalsePositiveRate(m, k, n uint) float64 {
+	if m == 0 {
+		return 1
+	}
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
+
+// Filter is a Bloom filter. A Filter is not safe for concurrent use.
+type Filter struct {
+	bits  []uint64
+	m     uint64
+	k     uint64
+	count uint64
+}
+
+// New returns a filter of m bits using k hash functions. m and k are at
+// least one, and k is at most [MaxK].
+func New(m, k uint) *Filter {
+	m = max(m, 1)
+	return &Filter{
+		bits: make([]uint64, (m+63)/64),
+		m:    uint64(m),
+		k:    uint64(min(max(k, 1), MaxK)),
+	}
+}
+
+// NewWithEstimates returns a filter sized to hold n items with a false
+// positive rate of p.
+func NewWithEstimates(n uint, p float64) *Filter {
+	m := OptimalM(n, p)
+	return New(m, OptimalK(m, n))
+}
+
+// M returns the number of bits of the filter.
+func (f *Filter) M() uint {
+	return uint(f.m)
+}
+
+// K returns the number of hash functions of the filter.
+func (f *Filter) K() uint {
+	return uint(f.k)
+}
+
+// Count returns the number of items added to the filter. Items added more
+// than once are counted each time, and merged filters add their counts.
+func (f *Filter) Count() uint {
+	return uint(f.count)
+}
+
+// FalsePositiveRate returns the expected false positive rate of the filter,
+// given the number of items added to it.
+func (f *Filter) FalsePositiveRate() float64 {
+	return FalsePositiveRate(f.M(), f.K(), f.Count())
+}
+
+// Add adds data to the filter.
+func (f *Filter) Add(data []byte) {
+	h1, h2 := hash(data)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.count++
+}
+
+// AddString adds s to the filter.
+func (f *Filter) AddString(s string) {
+	f.Add([]byte(s))
+}
+
+// Test reports whether data may have been added to the filter. If false,
+// data has definitely not been added.
+func (f *Filter) Test(data []byte) bool {
+	h1, h2 := hash(data)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TestString reports whether s may have been added to the filter.
+func (f *Filter) TestString(s string) bool {
+	return f.Test([]byte(s))
+}
+
+// Merge adds the items of other to f, so that f holds the union of both
+// filters. The filters must have the same size and number of hash
+// functions, or [ErrIncompatible] is returned.
+func (f *Filter) Merge(other *Filter) error {
+	if f.m != other.m || f.k != other.k {
+		return fmt.Errorf("%w: %d bits and %d hashes, and %d bits and %d hashes",
+			ErrIncompatible, f.m, f.k, other.m, other.k)
+	}
+	for i, w := range other.bits {
+		f.bits[i] |= w
+	}
+	f.count += other.count
+	return nil
+}
+
+// Union returns a new filter that holds the union of a and b.
+func Union(a, b *Filter) (*Filter, error) {
+	u := a.Clone()
+	if err := u.Merge(b); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Clone returns a copy of the filter.
+func (f *Filter) Clone() *Filter {
+	c := *f
+	c.bits = append([]uint64(nil), f.bits...)
+	return &c
+}
+
+// FillRatio returns the ratio of bits that are set.
+func (f *Filter) FillRatio() float64 {
+	var set int
+	for _, w := range f.bits {
+		set += bits.OnesCount64(w)
+	}
+	return float64(set) / float64(f.m)
+}
+
+// Reset removes every item from the filter.
+func (f *Filter) Reset() {
+	clear(f.bits)
+	f.count = 0
+}
+
+// binaryVersion is the version of the binary encoding.
+const binaryVersion = 1
+
+// headerSize is the size of the binary encoding before the bits: the
+// version, m, k and count.
+const headerSize = 1 + 8 + 8 + 8
+
+// MarshalBinary encodes the filter. The encoding is stable across
+// processes and architectures.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, headerSize, headerSize+8*len(f.bits))
+	b[0] = binaryVersion
+	binary.BigEndian.PutUint64(b[1:], f.m)
+	binary.BigEndian.PutUint64(b[9:], f.k)
+	binary.BigEndian.PutUint64(b[17:], f.count)
+	for _, w := range f.bits {
+		b = binary.BigEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a filter encoded by MarshalBinary.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || data[0] != binaryVersion {
+		return ErrInvalidData
+	}
+	m := binary.BigEndian.Uint64(data[1:])
+	k := binary.BigEndian.Uint64(data[9:])
+	count := binary.BigEndian.Uint64(data[17:])
+	data = data[headerSize:]
+	n := uint64(len(data) / 8)
+	if k == 0 || k > MaxK || len(data)%8 != 0 || m == 0 || m > 64*n || m <= 64*(n-1) {
+		return ErrInvalidData
+	}
+
+	words := make([]uint64, n)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint64(data[8*i:])
+	}
+	*f = Filter{bits: words, m: m, k: k, count: count}
+	return nil
+}
+
+// hash returns two 64-bit hashes of data, which are combined to derive the
+// k hash functions.
+func hash(data []byte) (uint64, uint64) {
+	h := fnv.New128a()
+	_, _ = h.Write(data)
+	sum := h.Sum(nil)
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	// An odd h2 makes every hash function distinct.
+	h2 := binary.BigEndian.Uint64(sum[8:]) | 1
+	return h1, h2
+}
@@ -0,0 +1,135 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestSizing(t *testing.T) {
+	m := OptimalM(1000, 0.01)
+	if m < 9500 || m > 9600 {
+		t.Errorf("OptimalM(1000, 0.01) = %d, want about 9586", m)
+	}
+	if k := OptimalK(m, 1000); k != 7 {
+		t.Errorf("OptimalK() = %d, want 7", k)
+	}
+	if p := FalsePositiveRate(m, 7, 1000); p < 0.009 || p > 0.011 {
+		t.Errorf("FalsePositiveRate() = %v, want about 0.01", p)
+	}
+
+	for _, p := range []float64{0, -1, math.NaN()} {
+		f := NewWithEstimates(1000, p)
+		if f.M() <= m || f.K() > MaxK {
+			t.Errorf("NewWithEstimates(1000, %v) = %d bits, %d hashes", p, f.M(), f.K())
+		}
+	}
+	if m := OptimalM(1000, 1); m != 1 {
+		t.Errorf("OptimalM(1000, 1) = %d, want 1", m)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	const n = 10000
+	f := NewWithEstimates(n, 0.01)
+	for i := 0; i < n; i++ {
+		f.AddString(strconv.Itoa(i))
+	}
+	if f.Count() != n {
+		t.Errorf("Count() = %d, want %d", f.Count(), n)
+	}
+	for i := 0; i < n; i++ {
+		if !f.TestString(strconv.Itoa(i)) {
+			t.Fatalf("TestString(%d) = false for an added item", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := n; i < 2*n; i++ {
+		if f.TestString(strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / n; rate > 0.02 {
+		t.Errorf("false positive rate = %v, want about 0.01", rate)
+	}
+
+	f.Reset()
+	if f.TestString("0") || f.FillRatio() != 0 {
+		t.Error("filter not empty after Reset")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b := New(1000, 4), New(1000, 4)
+	a.AddString("a")
+	b.AddString("b")
+
+	u, err := Union(a, b)
+	if err != nil {
+		t.Fatalf("Union() error = %v", err)
+	}
+	if !u.TestString("a") || !u.TestString("b") || u.Count() != 2 {
+		t.Error("union does not hold both items")
+	}
+	if a.TestString("b") {
+		t.Error("Union() modified its arguments")
+	}
+
+	if err := a.Merge(New(1000, 5)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Merge() error = %v, want %v", err, ErrIncompatible)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f := New(100, 3)
+	f.AddString("hello")
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	var g Filter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if g.M() != 100 || g.K() != 3 || g.Count() != 1 || !g.TestString("hello") {
+		t.Errorf("UnmarshalBinary() = %d bits, %d hashes, %d items, want a copy", g.M(), g.K(), g.Count())
+	}
+
+	hugeK := bytes.Clone(data)
+	binary.BigEndian.PutUint64(hugeK[9:], 1<<63)
+	tests := [][]byte{nil, {2}, data[:len(data)-1], hugeK}
+	for _, data := range tests {
+		if err := g.UnmarshalBinary(data); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("UnmarshalBinary(%v) error = %v, want %v", data, err, ErrInvalidData)
+		}
+	}
+}
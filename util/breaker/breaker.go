@@ -0,0 +1,394 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package breaker implements a circuit breaker, which stops calling an
+operation that keeps failing to give it time to recover.
+
+	b := breaker.New(&breaker.Options{
+		Trip: breaker.FailureRate(0.5, 20),
+	})
+	v, err := breaker.Execute(ctx, b, op)
+
+A breaker starts closed, allowing all calls. Once its [TripFunc] reports
+that too many calls failed, it opens and rejects calls with [ErrOpen]. After
+a timeout it becomes half-open, allowing a limited number of probe calls,
+and closes again if they succeed.
+*/
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOpen is returned when a call is rejected because the breaker is
+	// open.
+	ErrOpen = errors.New("breaker: circuit open")
+
+	// ErrTooManyProbes is returned when a call is rejected because the
+	// breaker is half-open and the maximum number of probe calls are already
+	// running.
+	ErrTooManyProbes = errors.New("breaker: too many probe calls")
+)
+
+// Default values used when [Options] fields are zero.
+const (
+	DefaultOpenTimeout         = time.Minute
+	DefaultConsecutiveFailures = 5
+)
+
+// State is the state of a [Breaker].
+type State int
+
+const (
+	// StateClosed allows all calls.
+	StateClosed State = iota
+	// StateOpen rejects all calls.
+	StateOpen
+	// StateHalfOpen allows a limited number of probe calls.
+	StateHalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Counts are the numbers of calls made in the current state, or since the
+// counts were last cleared with [Options.Interval].
+type Counts struct {
+	Requests             int
+	Successes            int
+	Failures             int
+	ConsecutiveSuccesses int
+	ConsecutiveFailures  int
+}
+
+// FailureRate returns the ratio of failed calls to all calls that finished.
+func (c Counts) FailureRate() float64 {
+	total := c.Successes + c.Failures
+	if total == 0 {
+		return 0
+	}
+	return float64(c.Failures) / float64(total)
+}
+
+func (c *Counts) success() {
+	c.Successes++
+	c.ConsecutiveSuccesses++
+	c.ConsecutiveFailures = 0
+}
+
+func (c *Counts) failure() {
+	c.Failures++
+	c.ConsecutiveFailures++
+	c.ConsecutiveSuccesses = 0
+}
+
+// TripFunc decides whether a closed breaker opens, given the counts after a
+// call failed.
+type TripFunc func(c Counts) bool
+
+// ConsecutiveFailures returns a [TripFunc] that opens the breaker after n
+// consecutive failures.
+func ConsecutiveFailures(n int) TripFunc {
+	return func(c Counts) bool {
+		return c.ConsecutiveFailures >= n
+	}
+}
+
+// FailureRate returns a [TripFunc] that opens the breaker once at least rate
+// of the calls failed, after at least minCalls calls finished.
+func FailureRate(rate float64, minCalls int) TripFunc {
+	return func(c Counts) bool {
+		return c.Successes+c.Failures >= minCalls && c.FailureRate() >= rate
+	}
+}
+
+// Options configure a [Breaker].
+type Options struct {
+	// Trip decides when the breaker opens. Defaults to
+	// ConsecutiveFailures(DefaultConsecutiveFailures).
+	Trip TripFunc
+
+	// Interval is how often the counts are cleared while the breaker is
+	// closed. If zero, the counts are only cleared when the state changes.
+	Interval time.Duration
+
+	// OpenTimeout is how long the breaker stays open before becoming
+	// half-open. Defaults to [DefaultOpenTimeout].
+	OpenTimeout time.Duration
+
+	// MaxProbes is the number of probe calls allowed while the breaker is
+	// half-open. The breaker closes once this many probe calls have
+	// succeeded. Defaults to 1.
+	MaxProbes int
+
+	// IsFailure decides whether an error counts as a failure. By default,
+	// all errors are failures. Calls cancelled with [context.Canceled] are
+	// not counted, and are not passed to IsFailure.
+	IsFailure func(err error) bool
+
+	// OnStateChange is called after the state of the breaker changes.
+	OnStateChange func(from, to State)
+}
+
+// Breaker is a circuit breaker. A Breaker is safe for concurrent use.
+type Breaker struct {
+	trip          TripFunc
+	interval      time.Duration
+	openTimeout   time.Duration
+	maxProbes     int
+	isFailure     func(err error) bool
+	onStateChange func(from, to State)
+	now           func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	counts     Counts
+	generation uint64
+	expiry     time.Time
+}
+
+// New returns a closed [Breaker] configured with opts. If opts is nil, the
+// default options are used.
+func New(opts *Options) *Breaker {
+	return newBreaker(opts, time.Now)
+}
+
+// newBreaker is like [New], using now to get the current time.
+func newBreaker(opts *Options, now func() time.Time) *Breaker {
+	if opts == nil {
+		opts = &Options{}
+	}
+	b := &Breaker{
+		trip:          opts.Trip,
+		interval:      opts.Interval,
+		openTimeout:   opts.OpenTimeout,
+		maxProbes:     opts.MaxProbes,
+		isFailure:     opts.IsFailure,
+		onStateChange: opts.OnStateChange,
+		now:           now,
+	}
+	if b.trip == nil {
+		b.trip = ConsecutiveFailures(DefaultConsecutiveFailures)
+	}
+	if b.openTimeout <= 0 {
+		b.openTimeout = DefaultOpenTimeout
+	}
+	if b.maxProbes <= 0 {
+		b.maxProbes = 1
+	}
+	if b.isFailure == nil {
+		b.isFailure = isFailure
+	}
+	b.toState(StateClosed, b.now())
+	return b
+}
+
+// isFailure is the default [Options.IsFailure].
+func isFailure(err error) bool {
+	return err != nil
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	state, changed := b.update(b.now())
+	b.mu.Unlock()
+	b.notify(changed)
+	return state
+}
+
+// Counts returns the counts of the current state.
+func (b *Breaker) Counts() Counts {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.counts
+}
+
+// Allow reports whether a call may be made. If it may, done must be called
+// with the result of the call, so that the breaker can count it. Otherwise,
+// [ErrOpen] or [ErrTooManyProbes] is returned.
+//
+// Allow is useful when a call cannot be wrapped in a function, otherwise
+// [Breaker.Do] or [Execute] are simpler.
+func (b *Breaker) Allow() (func(err error), error) {
+	b.mu.Lock()
+	state, changed := b.update(b.now())
+	var err error
+	switch {
+	case state == StateOpen:
+		err = ErrOpen
+	case state == StateHalfOpen && b.counts.Requests >= b.maxProbes:
+		err = ErrTooManyProbes
+	default:
+		b.counts.Requests++
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(changed)
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			b.done(generation, err)
+		})
+	}, nil
+}
+
+// done counts the result of a call allowed in the given generation.
+func (b *Breaker) done(generation uint64, err error) {
+	b.mu.Lock()
+	now := b.now()
+	state, changed := b.update(now)
+	if generation != b.generation {
+		// The state changed since the call was allowed
+		b.mu.Unlock()
+		b.notify(changed)
+		return
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// The caller gave up, so the call says nothing about the backend.
+		// Release its request, so that a probe can be made in its place.
+		b.counts.Requests--
+	case !b.isFailure(err):
+		b.counts.success()
+		if state == StateHalfOpen && b.counts.ConsecutiveSuccesses >= b.maxProbes {
+			changed = append(changed, b.toState(StateClosed, now))
+		}
+	default:
+		b.counts.failure()
+		if state == StateHalfOpen || b.trip(b.counts) {
+			changed = append(changed, b.toState(StateOpen, now))
+		}
+	}
+	b.mu.Unlock()
+	b.notify(changed)
+}
+
+// Do calls fn if the breaker allows it, and counts its result. If the
+// context is done, its error is returned without calling fn.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := Execute(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Execute is like [Breaker.Do], but for functions that return a value.
+func Execute[T any](ctx context.Context, b *Breaker, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	done, err := b.Allow()
+	if err != nil {
+		return zero, err
+	}
+	v, err := fn(ctx)
+	done(err)
+	return v, err
+}
+
+// Reset closes the breaker and clears its counts.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	from := b.state
+	t := b.toState(StateClosed, b.now())
+	b.mu.Unlock()
+	if from != StateClosed {
+		b.notify([]transition{t})
+	}
+}
+
+// transition is a change of state, which is passed to OnStateChange after
+// the lock is released.
+type transition struct {
+	from, to State
+}
+
+// update changes the state if its expiry has passed, and returns the current
+// state and the changes made. The lock must be held.
+func (b *Breaker) update(now time.Time) (State, []transition) {
+	if b.expiry.IsZero() || now.Before(b.expiry) {
+		return b.state, nil
+	}
+	switch b.state {
+	case StateClosed:
+		b.toState(StateClosed, now)
+		return b.state, nil
+	case StateOpen:
+		return b.state, []transition{b.toState(StateHalfOpen, now)}
+	default:
+		return b.state, nil
+	}
+}
+
+// toState changes the state, clearing the counts. The lock must be held.
+func (b *Breaker) toState(state State, now time.Time) transition {
+	t := transition{from: b.state, to: state}
+	b.state = state
+	b.counts = Counts{}
+	b.generation++
+	switch {
+	case state == StateOpen:
+		b.expiry = now.Add(b.openTimeout)
+	case state == StateClosed && b.interval > 0:
+		b.expiry = now.Add(b.interval)
+	default:
+		b.expiry = time.Time{}
+	}
+	return t
+}
+
+// notify calls OnStateChange for the changes. The lock must not be held.
+func (b *Breaker) notify(changes []transition) {
+	if b.onStateChange == nil {
+		return
+	}
+	for _, t := range changes {
+		if t.from != t.to {
+			b.onStateChange(t.from, t.to)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+// testBreaker returns a breaker with a fake clock, advanced with the
+// returned function.
+func testBreaker(opts *Options) (*Breaker, func(d time.Duration)) {
+	now := time.Now()
+	b := newBreaker(opts, func() time.Time { return now })
+	return b, func(d time.Duration) { now = now.Add(d) }
+}
+
+func fail(context.Context) error {
+	return errTest
+}
+
+func succeed(context.Context) error {
+	return nil
+}
+
+func TestBreakerConsecutiveFailures(t *testing.T) {
+	var changes []string
+	b, advance := testBreaker(&Options{
+		Trip:        ConsecutiveFailures(3),
+		OpenTimeout: time.Minute,
+		OnStateChange: func(from, to State) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_ = b.Do(ctx, fail)
+	}
+	_ = b.Do(ctx, succeed)
+	for i := 0; i < 3; i++ {
+		if err := b.Do(ctx, fail); !errors.Is(err, errTest) {
+			t.Fatalf("Do() error = %v, want %v", err, errTest)
+		}
+	}
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %v, want %v", got, StateOpen)
+	}
+	if err := b.Do(ctx, succeed); !errors.Is(err, ErrOpen) {
+		t.Errorf("Do() error = %v, want %v", err, ErrOpen)
+	}
+
+	advance(time.Minute)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want %v", got, StateHalfOpen)
+	}
+	if err := b.Do(ctx, succeed); err != nil {
+		t.Errorf("Do() error = %v", err)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() = %v, want %v", got, StateClosed)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if !slices.Equal(changes, want) {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestBreakerCancelledProbe(t *testing.T) {
+	b, advance := testBreaker(&Options{Trip: ConsecutiveFailures(1), OpenTimeout: time.Minute})
+	ctx := context.Background()
+	_ = b.Do(ctx, fail)
+	advance(time.Minute)
+
+	cancelled := func(context.Context) error { return context.Canceled }
+	if err := b.Do(ctx, cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() error = %v, want %v", err, context.Canceled)
+	}
+	if got := b.State(); got != StateHalfOpen {
+		t.Errorf("State() after cancelled probe = %v, want %v", got, StateHalfOpen)
+	}
+	if got := b.Counts(); got != (Counts{}) {
+		t.Errorf("Counts() after cancelled probe = %+v, want zero", got)
+	}
+	// The probe slot was released
+	if err := b.Do(ctx, succeed); err != nil {
+		t.Errorf("Do() error = %v", err)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() = %v, want %v", got, StateClosed)
+	}
+}
+
+func TestBreakerFailureRate(t *testing.T) {
+	b, _ := testBreaker(&Options{Trip: FailureRate(0.5, 4)})
+	ctx := context.Background()
+	for _, op := range []func(context.Context) error{fail, succeed, fail} {
+		_ = b.Do(ctx, op)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("State() before minimum calls = %v, want %v", got, StateClosed)
+	}
+	_ = b.Do(ctx, succeed)
+	_ = b.Do(ctx, fail)
+	if got := b.State(); got != StateOpen {
+		t.Errorf("State() = %v, want %v", got, StateOpen)
+	}
+}
+
+func TestBreakerHalfOpenProbes(t *testing.T) {
+	b, advance := testBreaker(&Options{Trip: ConsecutiveFailures(1), MaxProbes: 2})
+	ctx := context.Background()
+	_ = b.Do(ctx, fail)
+	advance(DefaultOpenTimeout)
+
+	done1, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	done2, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrTooManyProbes) {
+		t.Errorf("Allow() error = %v, want %v", err, ErrTooManyProbes)
+	}
+
+	done1(nil)
+	if got := b.State(); got != StateHalfOpen {
+		t.Errorf("State() after one probe = %v, want %v", got, StateHalfOpen)
+	}
+	done2(errTest)
+	if got := b.State(); got != StateOpen {
+		t.Errorf("State() after failed probe = %v, want %v", got, StateOpen)
+	}
+}
+
+func TestBreakerInterval(t *testing.T) {
+	b, advance := testBreaker(&Options{Trip: ConsecutiveFailures(2), Interval: time.Minute})
+	ctx := context.Background()
+	_ = b.Do(ctx, fail)
+	advance(time.Minute)
+	_ = b.Do(ctx, fail)
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() = %v, want %v", got, StateClosed)
+	}
+	if got := b.Counts(); got.Failures != 1 {
+		t.Errorf("Counts().Failures = %d, want 1", got.Failures)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	b := New(nil)
+	v, err := Execute(context.Background(), b, func(context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || v != 42 {
+		t.Errorf("Execute() = %v, %v, want 42, nil", v, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	_, err = Execute(ctx, b, func(context.Context) (int, error) {
+		called = true
+		return 0, nil
+	})
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("Execute() with done context = %v, called = %v", err, called)
+	}
+
+	for i := 0; i < DefaultConsecutiveFailures; i++ {
+		_ = b.Do(context.Background(), func(context.Context) error {
+			return context.Canceled
+		})
+	}
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() after cancellations = %v, want %v", got, StateClosed)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package broadcast provides a broadcaster that sends each published value to
+every subscriber, such as configuration changes or shutdown notifications.
+*/
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned when a value is published after
+// [Broadcaster.Close] was called.
+var ErrClosed = errors.New("broadcast: broadcaster is closed")
+
+// SlowPolicy decides what happens when a subscriber's buffer is full.
+type SlowPolicy int
+
+const (
+	// SlowBlock makes Publish wait until the subscriber has space.
+	SlowBlock SlowPolicy = iota
+	// SlowDropValue skips the value for the subscriber.
+	SlowDropValue
+	// SlowDisconnect unsubscribes the subscriber, closing its channel.
+	SlowDisconnect
+)
+
+// Options configure a [Broadcaster].
+type Options struct {
+	// Buffer is the size of each subscriber's channel buffer. Defaults to
+	// zero, which makes every send wait for the subscriber.
+	Buffer int
+
+	// SlowPolicy decides what happens when a subscriber's buffer is full.
+	// Defaults to [SlowBlock].
+	SlowPolicy SlowPolicy
+}
+
+// Broadcaster sends each published value to every subscriber. A Broadcaster
+// is safe for concurrent use.
+type Broadcaster[T any] struct {
+	buffer int
+	policy SlowPolicy
+
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// mu is held while publishing, so that every subscriber receives the
+	// values in the same order.
+	mu     sync.Mutex
+	subs   map[*Subscription[T]]struct{}
+	closed bool
+}
+
+// New returns a new Broadcaster.
+func New[T any](opts *Options) *Broadcaster[T] {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Broadcaster[T]{
+		buffer:  max(opts.Buffer, 0),
+		policy:  opts.SlowPolicy,
+		closing: make(chan struct{}),
+		subs:    make(map[*Subscription[T]]struct{}),
+	}
+}
+
+// Subscription receives the values published by a [Broadcaster].
+type Subscription[T any] struct {
+	b        *Broadcaster[T]
+	ch       chan T
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// C returns the channel that receives the values. The channel is closed
+// once the subscription is closed, the subscriber is disconnected for being
+// slow, or the broadcaster is closed.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Close unsubscribes from the broadcaster, and closes the channel.
+func (s *Subscription[T]) Close() {
+	s.doneOnce.Do(func() { close(s.done) })
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.b.removeLocked(s)
+}
+
+// Subscribe returns a new subscription, which receives the values published
+// after Subscribe returns. If the broadcaster is closed, the channel of the
+// subscription is already closed.
+func (b *Broadcaster[T]) Subscribe() *Subscription[T] {
+	s := &Subscription[T]{
+		b:    b,
+		ch:   make(chan T, b.buffer),
+		done: make(chan struct{}),
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.ch)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Publish sends v to every subscriber. When a subscriber is slow, Publish
+// follows the [SlowPolicy] of the broadcaster. If ctx is done while waiting
+// for a subscriber, the remaining subscribers do not receive v and the
+// context's error is returned.
+func (b *Broadcaster[T]) Publish(ctx context.Context, v T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	for s := range b.subs {
+		select {
+		case s.ch <- v:
+			continue
+		default:
+		}
+
+		switch b.policy {
+		case SlowDropValue:
+		case SlowDisconnect:
+			b.removeLocked(s)
+		default:
+			select {
+			case s.ch <- v:
+			case <-s.done:
+				// Unsubscribing, removed by Close.
+			case <-b.closing:
+				return ErrClosed
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of subscribers.
+func (b *Broadcaster[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close closes the channel of every subscriber. Values published after
+// Close return [ErrClosed].
+func (b *Broadcaster[T]) Close() {
+	b.closeOnce.Do(func() { close(b.closing) })
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		b.removeLocked(s)
+	}
+}
+
+// removeLocked removes s and closes its channel. b.mu must be held.
+func (b *Broadcaster[T]) removeLocked(s *Subscription[T]) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func recvAll[T any](ch <-chan T) []T {
+	var values []T
+	for v := range ch {
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	b := New[int](&Options{Buffer: 3})
+	s1, s2 := b.Subscribe(), b.Subscribe()
+	if n := b.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if err := b.Publish(ctx, i); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	b.Close()
+
+	want := []int{1, 2, 3}
+	if got := recvAll(s1.C()); !slices.Equal(got, want) {
+		t.Errorf("s1 received %v, want %v", got, want)
+	}
+	if got := recvAll(s2.C()); !slices.Equal(got, want) {
+		t.Errorf("s2 received %v, want %v", got, want)
+	}
+	if err := b.Publish(ctx, 4); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish() after Close error = %v, want %v", err, ErrClosed)
+	}
+	if _, ok := <-b.Subscribe().C(); ok {
+		t.Error("Subscribe() after Close returned an open channel")
+	}
+}
+
+func TestSlowPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy SlowPolicy
+		want   []int
+	}{
+		{name: "drop value", policy: SlowDropValue, want: []int{1, 3}},
+		{name: "disconnect", policy: SlowDisconnect, want: []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			b := New[int](&Options{Buffer: 1, SlowPolicy: tt.policy})
+			s := b.Subscribe()
+
+			_ = b.Publish(ctx, 1)
+			_ = b.Publish(ctx, 2)
+			got := []int{<-s.C()}
+			_ = b.Publish(ctx, 3)
+			b.Close()
+			got = append(got, recvAll(s.C())...)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("received %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishBlock(t *testing.T) {
+	b := New[int](nil)
+	s := b.Subscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Publish(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Publish() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- b.Publish(context.Background(), 2)
+	}()
+	if v := <-s.C(); v != 2 {
+		t.Errorf("received %d, want 2", v)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Publish() error = %v", err)
+	}
+}
+
+func TestCloseUnblocksPublish(t *testing.T) {
+	b := New[int](nil)
+	s := b.Subscribe()
+
+	done := make(chan error)
+	go func() {
+		done <- b.Publish(context.Background(), 1)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	s.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Publish() error = %v", err)
+	}
+	if n := b.Len(); n != 0 {
+		t.Errorf("Len() = %d, want 0", n)
+	}
+
+	s = b.Subscribe()
+	go func() {
+		done <- b.Publish(context.Background(), 1)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	b.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish() error = %v, want %v", err, ErrClosed)
+	}
+	s.Close()
+}
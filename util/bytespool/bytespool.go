@@ -0,0 +1,184 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package bytespool provides a pool of byte slices in power-of-two size
+classes, so that buffers of varying sizes can be reused without keeping
+large buffers for small uses.
+
+	buf := bytespool.Get(n)
+	defer bytespool.Put(buf)
+*/
+package bytespool
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"unsafe"
+
+	"hypera.dev/lib/util/pool"
+)
+
+// Default values used by [New].
+const (
+	DefaultMinSize = 64
+	DefaultMaxSize = 64 << 10
+)
+
+// Options configure a [Pool].
+type Options struct {
+	// MinSize is the capacity of the smallest size class, rounded up to a
+	// power of two. Defaults to [DefaultMinSize].
+	MinSize int
+
+	// MaxSize is the capacity of the largest size class, rounded up to a
+	// power of two. Larger slices are allocated, and dropped when put back.
+	// Defaults to [DefaultMaxSize].
+	MaxSize int
+}
+
+// ClassStats are the statistics of a size class.
+type ClassStats struct {
+	// Size is the capacity of the slices in the class.
+	Size int
+
+	pool.Stats
+}
+
+// Stats are the statistics of a [Pool].
+type Stats struct {
+	// Classes are the statistics of each size class, smallest first.
+	Classes []ClassStats
+
+	// Oversize is the number of slices requested that were larger than the
+	// largest class.
+	Oversize uint64
+
+	// Foreign is the number of slices put back that were not the capacity
+	// of a size class, and were dropped.
+	Foreign uint64
+}
+
+// Pool is a pool of byte slices. A Pool is safe for concurrent use.
+type Pool struct {
+	minShift int
+
+	// classes hold a pointer to the first byte of each slice rather than a
+	// *[]byte, so that Put does not allocate a slice header. The capacity of
+	// the slice is known from its class.
+	classes []*pool.Pool[*byte]
+
+	oversize atomic.Uint64
+	foreign  atomic.Uint64
+}
+
+// New returns a Pool configured with opts. If opts is nil, the default
+// options are used.
+func New(opts *Options) *Pool {
+	if opts == nil {
+		opts = &Options{}
+	}
+	minSize := opts.MinSize
+	if minSize <= 0 {
+		minSize = DefaultMinSize
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	minShift := shift(minSize)
+	maxShift := max(shift(maxSize), minShift)
+
+	p := &Pool{minShift: minShift}
+	for s := minShift; s <= maxShift; s++ {
+		size := 1 << s
+		p.classes = append(p.classes, pool.New(&pool.Options[*byte]{
+			New: func() *byte {
+				return unsafe.SliceData(make([]byte, size))
+			},
+		}))
+	}
+	return p
+}
+
+// Get returns a slice of length n, with a capacity of at least n. Its
+// contents are not zeroed.
+func (p *Pool) Get(n int) []byte {
+	i := shift(n) - p.minShift
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(p.classes) {
+		p.oversize.Add(1)
+		return make([]byte, n)
+	}
+	b := unsafe.Slice(p.classes[i].Get(), 1<<(p.minShift+i))
+	return b[:n]
+}
+
+// Put returns b to the pool, to be reused by Get. b must not be used after
+// Put is called. Slices whose capacity is not that of a size class, such as
+// slices that were not returned by Get, are dropped.
+func (p *Pool) Put(b []byte) {
+	c := cap(b)
+	i := shift(c) - p.minShift
+	if c == 0 || c&(c-1) != 0 || i < 0 || i >= len(p.classes) {
+		p.foreign.Add(1)
+		return
+	}
+	p.classes[i].Put(unsafe.SliceData(b))
+}
+
+// Stats returns the statistics of the pool.
+func (p *Pool) Stats() Stats {
+	stats := Stats{
+		Classes:  make([]ClassStats, len(p.classes)),
+		Oversize: p.oversize.Load(),
+		Foreign:  p.foreign.Load(),
+	}
+	for i, c := range p.classes {
+		stats.Classes[i] = ClassStats{Size: 1 << (p.minShift + i), Stats: c.Stats()}
+	}
+	return stats
+}
+
+// shift returns the smallest s such that 1<<s >= n.
+func shift(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+var defaultPool = New(nil)
+
+// Get returns a slice of length n from the default pool. See [Pool.Get].
+func Get(n int) []byte {
+	return defaultPool.Get(n)
+}
+
+// Put returns b to the default pool. See [Pool.Put].
+func Put(b []byte) {
+	defaultPool.Put(b)
+}
@@ -0,0 +1,110 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package bytespool
+
+import (
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	p := New(&Options{MinSize: 100, MaxSize: 1000})
+	tests := []struct {
+		n       int
+		wantCap int
+	}{
+		{n: 0, wantCap: 128},
+		{n: 1, wantCap: 128},
+		{n: 128, wantCap: 128},
+		{n: 129, wantCap: 256},
+		{n: 1024, wantCap: 1024},
+		{n: 1025, wantCap: 1025},
+	}
+	for _, tt := range tests {
+		b := p.Get(tt.n)
+		if len(b) != tt.n || cap(b) != tt.wantCap {
+			t.Errorf("Get(%d) = len %d, cap %d, want len %d, cap %d", tt.n, len(b), cap(b), tt.n, tt.wantCap)
+		}
+	}
+}
+
+func TestPut(t *testing.T) {
+	p := New(&Options{MinSize: 64, MaxSize: 256})
+	b := p.Get(100)
+	b[0] = 'x'
+	p.Put(b)
+	p.Put(make([]byte, 100))  // not a size class
+	p.Put(make([]byte, 1024)) // larger than the largest class
+	p.Put(nil)
+
+	// Reuse is not guaranteed by sync.Pool, so only check the statistics
+	// add up.
+	b = p.Get(120)
+	if len(b) != 120 || cap(b) != 128 {
+		t.Errorf("Get(120) = len %d, cap %d, want len 120, cap 128", len(b), cap(b))
+	}
+	p.Get(1000)
+
+	stats := p.Stats()
+	if len(stats.Classes) != 3 || stats.Classes[1].Size != 128 {
+		t.Fatalf("Stats().Classes = %+v, want 3 classes from 64 to 256", stats.Classes)
+	}
+	if c := stats.Classes[1]; c.Hits+c.Misses != 2 {
+		t.Errorf("class 128 stats = %+v, want 2 gets", c)
+	}
+	if stats.Oversize != 1 || stats.Foreign != 3 {
+		t.Errorf("Stats() = %d oversize, %d foreign, want 1, 3", stats.Oversize, stats.Foreign)
+	}
+}
+
+func TestDefaultPool(t *testing.T) {
+	b := Get(10)
+	if len(b) != 10 || cap(b) != DefaultMinSize {
+		t.Errorf("Get(10) = len %d, cap %d, want len 10, cap %d", len(b), cap(b), DefaultMinSize)
+	}
+	Put(b)
+}
+
+func BenchmarkGetPut(b *testing.B) {
+	p := New(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := p.Get(1000)
+		p.Put(buf)
+	}
+}
+
+func TestGetPutAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random with the race detector")
+	}
+	p := New(nil)
+	p.Put(p.Get(1000))
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Put(p.Get(1000))
+	})
+	if allocs != 0 {
+		t.Errorf("Get and Put allocated %v times, want 0", allocs)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package cache provides generic in-memory caches.
+*/
+package cache
+
+import "fmt"
+
+// EvictReason is the reason an entry was removed from a cache.
+type EvictReason int
+
+const (
+	// EvictCapacity is the reason for entries evicted to make room for
+	// others.
+	EvictCapacity EvictReason = iota
+	// EvictExpired is the reason for entries removed because they expired.
+	EvictExpired
+	// EvictDeleted is the reason for entries that were deleted, replaced or
+	// purged.
+	EvictDeleted
+)
+
+// String returns the name of the reason.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	default:
+		return fmt.Sprintf("EvictReason(%d)", int(r))
+	}
+}
+
+// Stats are the statistics of a cache.
+type Stats struct {
+	// Hits is the number of lookups that found an entry.
+	Hits uint64
+
+	// Misses is the number of lookups that did not find an entry.
+	Misses uint64
+
+	// Evictions is the number of entries evicted for capacity or because
+	// they expired.
+	Evictions uint64
+}
+
+// HitRatio returns the ratio of lookups that found an entry, or zero if
+// there were no lookups.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// evicted is an entry removed from a cache, whose eviction callback is
+// called once the lock is released.
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
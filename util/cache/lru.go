@@ -0,0 +1,297 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Options configure an [LRU] cache.
+type Options[K comparable, V any] struct {
+	// MaxEntries is the maximum number of entries. If zero, the number of
+	// entries is not limited.
+	MaxEntries int
+
+	// MaxCost is the maximum total cost of the entries. If zero, the cost
+	// is not limited.
+	MaxCost int64
+
+	// Cost returns the cost of an entry, such as its size in bytes. If nil,
+	// each entry costs one.
+	Cost func(key K, value V) int64
+
+	// TTL is how long entries are kept after being set. If zero, entries do
+	// not expire, unless set with [LRU.SetWithTTL].
+	TTL time.Duration
+
+	// OnEvict is called after an entry is removed, with the reason it was
+	// removed. It is called without holding the lock of the cache.
+	OnEvict func(key K, value V, reason EvictReason)
+}
+
+// lruEntry is an entry of an LRU cache, in a circular list.
+type lruEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	cost    int64
+	expires time.Time
+
+	prev, next *lruEntry[K, V]
+}
+
+// LRU is a cache that evicts the least recently used entries once it is
+// full. An LRU is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	maxEntries int
+	maxCost    int64
+	costFn     func(key K, value V) int64
+	ttl        time.Duration
+	onEvict    func(key K, value V, reason EvictReason)
+	now        func() time.Time
+
+	mu      sync.Mutex
+	items   map[K]*lruEntry[K, V]
+	root    lruEntry[K, V] // root.next is the most recently used entry
+	cost    int64
+	stats   Stats
+	evicted []evicted[K, V]
+}
+
+// NewLRU returns an LRU cache configured with opts. If opts is nil, the
+// cache is not limited.
+func NewLRU[K comparable, V any](opts *Options[K, V]) *LRU[K, V] {
+	if opts == nil {
+		opts = &Options[K, V]{}
+	}
+	c := &LRU[K, V]{
+		maxEntries: opts.MaxEntries,
+		maxCost:    opts.MaxCost,
+		costFn:     opts.Cost,
+		ttl:        opts.TTL,
+		onEvict:    opts.OnEvict,
+		now:        time.Now,
+		items:      make(map[K]*lruEntry[K, V]),
+	}
+	c.root.next = &c.root
+	c.root.prev = &c.root
+	return c
+}
+
+// Get returns the value of key, and reports whether it was found. The entry
+// becomes the most recently used.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	e, ok := c.lookup(key)
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	c.stats.Hits++
+	c.moveToFront(e)
+	return e.value, true
+}
+
+// Peek returns the value of key without changing its recency or the
+// statistics, and reports whether it was found.
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	e, ok := c.lookup(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set sets the value of key, using the default TTL. The entry becomes the
+// most recently used.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL sets the value of key, expiring after ttl. If ttl is zero, the
+// entry does not expire. The entry becomes the most recently used.
+func (c *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	cost := int64(1)
+	if c.costFn != nil {
+		cost = c.costFn(key, value)
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+
+	if e, ok := c.items[key]; ok {
+		if c.onEvict != nil {
+			c.evicted = append(c.evicted, evicted[K, V]{key: key, value: e.value, reason: EvictDeleted})
+		}
+		c.cost += cost - e.cost
+		e.value, e.cost, e.expires = value, cost, expires
+		c.moveToFront(e)
+	} else {
+		e := &lruEntry[K, V]{key: key, value: value, cost: cost, expires: expires}
+		c.items[key] = e
+		c.cost += cost
+		c.insertFront(e)
+	}
+
+	for c.full() {
+		c.remove(c.root.prev, EvictCapacity)
+	}
+}
+
+// Delete deletes key, and reports whether it was found.
+func (c *LRU[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.unlock()
+	e, ok := c.items[key]
+	if ok {
+		c.remove(e, EvictDeleted)
+	}
+	return ok
+}
+
+// Purge deletes every entry.
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.unlock()
+	for c.root.next != &c.root {
+		c.remove(c.root.next, EvictDeleted)
+	}
+}
+
+// Len returns the number of entries, including expired entries that have
+// not been removed yet.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Cost returns the total cost of the entries.
+func (c *LRU[K, V]) Cost() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cost
+}
+
+// Keys returns the keys of the entries that have not expired, from the most
+// to the least recently used.
+func (c *LRU[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	keys := make([]K, 0, len(c.items))
+	for e := c.root.next; e != &c.root; e = e.next {
+		if !e.expired(now) {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// Stats returns the statistics of the cache.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// lookup returns the entry of key, removing it if it has expired. c.mu
+// must be held.
+func (c *LRU[K, V]) lookup(key K) (*lruEntry[K, V], bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if e.expired(c.now()) {
+		c.remove(e, EvictExpired)
+		return nil, false
+	}
+	return e, true
+}
+
+// full reports whether the cache exceeds its limits. An entry that exceeds
+// MaxCost on its own is evicted too. c.mu must be held.
+func (c *LRU[K, V]) full() bool {
+	if len(c.items) == 0 {
+		return false
+	}
+	return (c.maxEntries > 0 && len(c.items) > c.maxEntries) || (c.maxCost > 0 && c.cost > c.maxCost)
+}
+
+// remove removes an entry, queueing its eviction callback. c.mu must be
+// held.
+func (c *LRU[K, V]) remove(e *lruEntry[K, V], reason EvictReason) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+	delete(c.items, e.key)
+	c.cost -= e.cost
+	if reason != EvictDeleted {
+		c.stats.Evictions++
+	}
+	if c.onEvict != nil {
+		c.evicted = append(c.evicted, evicted[K, V]{key: e.key, value: e.value, reason: reason})
+	}
+}
+
+func (c *LRU[K, V]) insertFront(e *lruEntry[K, V]) {
+	e.prev = &c.root
+	e.next = c.root.next
+	c.root.next.prev = e
+	c.root.next = e
+}
+
+func (c *LRU[K, V]) moveToFront(e *lruEntry[K, V]) {
+	if c.root.next == e {
+		return
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	c.insertFront(e)
+}
+
+// unlock releases c.mu, then calls the eviction callback for the entries
+// that were removed.
+func (c *LRU[K, V]) unlock() {
+	evicted := c.evicted
+	c.evicted = nil
+	c.mu.Unlock()
+	for _, e := range evicted {
+		c.onEvict(e.key, e.value, e.reason)
+	}
+}
+
+func (e *lruEntry[K, V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
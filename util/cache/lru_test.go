@@ -0,0 +1,166 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cache
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+type eviction struct {
+	key    string
+	reason EvictReason
+}
+
+func TestLRU(t *testing.T) {
+	var evictions []eviction
+	c := NewLRU(&Options[string, int]{
+		MaxEntries: 2,
+		OnEvict: func(key string, _ int, reason EvictReason) {
+			evictions = append(evictions, eviction{key, reason})
+		},
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if v, ok := c.Get("a"); v != 1 || !ok {
+		t.Errorf("Get(a) = %d, %t, want 1, true", v, ok)
+	}
+	c.Set("c", 3) // evicts b
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) found the least recently used entry")
+	}
+	if got := c.Keys(); !slices.Equal(got, []string{"c", "a"}) {
+		t.Errorf("Keys() = %v, want [c a]", got)
+	}
+
+	c.Set("a", 10)
+	if !c.Delete("c") || c.Delete("c") {
+		t.Error("Delete(c) did not delete c once")
+	}
+	want := []eviction{{"b", EvictCapacity}, {"a", EvictDeleted}, {"c", EvictDeleted}}
+	if !slices.Equal(evictions, want) {
+		t.Errorf("evictions = %v, want %v", evictions, want)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit, 1 miss and 1 eviction", stats)
+	}
+	if r := stats.HitRatio(); r != 0.5 {
+		t.Errorf("HitRatio() = %v, want 0.5", r)
+	}
+
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("Len() after Purge = %d, want 0", c.Len())
+	}
+}
+
+func TestLRUCost(t *testing.T) {
+	c := NewLRU(&Options[string, string]{
+		MaxCost: 10,
+		Cost:    func(_ string, v string) int64 { return int64(len(v)) },
+	})
+	c.Set("a", "1234")
+	c.Set("b", "1234")
+	c.Set("c", "1234") // evicts a
+	if c.Cost() != 8 || c.Len() != 2 {
+		t.Errorf("Cost(), Len() = %d, %d, want 8, 2", c.Cost(), c.Len())
+	}
+	if _, ok := c.Peek("a"); ok {
+		t.Error("Peek(a) found an entry evicted for cost")
+	}
+
+	c.Set("b", "1") // replacing updates the cost
+	if c.Cost() != 5 {
+		t.Errorf("Cost() = %d, want 5", c.Cost())
+	}
+
+	c.Set("big", "12345678901")
+	if c.Len() != 0 || c.Cost() != 0 {
+		t.Errorf("Len(), Cost() = %d, %d, an entry over MaxCost must be evicted", c.Len(), c.Cost())
+	}
+}
+
+func TestLRUTTL(t *testing.T) {
+	clock := newFakeClock()
+	var expired []string
+	c := NewLRU(&Options[string, int]{
+		TTL: time.Minute,
+		OnEvict: func(key string, _ int, reason EvictReason) {
+			if reason == EvictExpired {
+				expired = append(expired, key)
+			}
+		},
+	})
+	c.now = clock.now
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("c", 3, 0)
+	clock.advance(2 * time.Minute)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) found an expired entry")
+	}
+	if got := c.Keys(); !slices.Equal(got, []string{"c", "b"}) {
+		t.Errorf("Keys() = %v, want [c b]", got)
+	}
+	clock.advance(24 * time.Hour)
+	if _, ok := c.Peek("b"); ok {
+		t.Error("Peek(b) found an expired entry")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("Get(c) did not find an entry without TTL")
+	}
+	if !slices.Equal(expired, []string{"a", "b"}) {
+		t.Errorf("expired = %v, want [a b]", expired)
+	}
+}
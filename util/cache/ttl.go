@@ -0,0 +1,239 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TTLOptions configure a [TTL] cache.
+type TTLOptions[K comparable, V any] struct {
+	// TTL is how long entries are kept after being set. If zero, entries do
+	// not expire, unless set with [TTL.SetWithTTL].
+	TTL time.Duration
+
+	// Sliding makes getting an entry extend its expiry by its TTL, so that
+	// entries expire once they have not been used for their TTL.
+	Sliding bool
+
+	// SweepInterval is how often expired entries are removed in the
+	// background. If zero, expired entries are only removed when they are
+	// looked up, or when [TTL.Sweep] is called.
+	SweepInterval time.Duration
+
+	// OnExpire is called after an expired entry is removed. It is called
+	// without holding the lock of the cache.
+	OnExpire func(key K, value V)
+}
+
+// ttlEntry is an entry of a TTL cache.
+type ttlEntry[V any] struct {
+	value V
+	ttl   time.Duration
+	// expires is the expiry time in Unix nanoseconds, or zero if the entry
+	// does not expire. It is updated by Get when expiration is sliding.
+	expires atomic.Int64
+}
+
+func (e *ttlEntry[V]) expired(now int64) bool {
+	expires := e.expires.Load()
+	return expires != 0 && now >= expires
+}
+
+// TTL is a cache whose entries expire after a time. Get only takes a read
+// lock, so that concurrent lookups do not contend. A TTL cache is safe for
+// concurrent use, and must be closed to stop its janitor.
+type TTL[K comparable, V any] struct {
+	ttl      time.Duration
+	sliding  bool
+	onExpire func(key K, value V)
+	now      func() time.Time
+
+	mu    sync.RWMutex
+	items map[K]*ttlEntry[V]
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewTTL returns a TTL cache configured with opts. If opts is nil, entries
+// only expire when set with [TTL.SetWithTTL].
+func NewTTL[K comparable, V any](opts *TTLOptions[K, V]) *TTL[K, V] {
+	if opts == nil {
+		opts = &TTLOptions[K, V]{}
+	}
+	c := &TTL[K, V]{
+		ttl:      opts.TTL,
+		sliding:  opts.Sliding,
+		onExpire: opts.OnExpire,
+		now:      time.Now,
+		items:    make(map[K]*ttlEntry[V]),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if opts.SweepInterval > 0 {
+		go c.janitor(opts.SweepInterval)
+	} else {
+		close(c.done)
+	}
+	return c
+}
+
+// Get returns the value of key, and reports whether it was found. If
+// expiration is sliding, the expiry of the entry is extended.
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	now := c.now().UnixNano()
+	c.mu.RLock()
+	e, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		c.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+	if e.expired(now) {
+		c.misses.Add(1)
+		c.removeExpired(key, e, now)
+		var zero V
+		return zero, false
+	}
+	if c.sliding && e.ttl > 0 {
+		e.expires.Store(now + int64(e.ttl))
+	}
+	c.hits.Add(1)
+	return e.value, true
+}
+
+// Set sets the value of key, using the default TTL.
+func (c *TTL[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL sets the value of key, expiring after ttl. If ttl is zero, the
+// entry does not expire.
+func (c *TTL[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	e := &ttlEntry[V]{value: value, ttl: max(ttl, 0)}
+	if ttl > 0 {
+		e.expires.Store(c.now().Add(ttl).UnixNano())
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = e
+}
+
+// Delete deletes key, and reports whether it was found.
+func (c *TTL[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.items[key]
+	delete(c.items, key)
+	return ok
+}
+
+// Len returns the number of entries, including expired entries that have
+// not been removed yet.
+func (c *TTL[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Stats returns the statistics of the cache.
+func (c *TTL[K, V]) Stats() Stats {
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// Sweep removes the expired entries, and returns how many were removed.
+func (c *TTL[K, V]) Sweep() int {
+	now := c.now().UnixNano()
+	var removed []evicted[K, V]
+	c.mu.Lock()
+	for key, e := range c.items {
+		if e.expired(now) {
+			delete(c.items, key)
+			removed = append(removed, evicted[K, V]{key: key, value: e.value, reason: EvictExpired})
+		}
+	}
+	c.mu.Unlock()
+
+	c.evictions.Add(uint64(len(removed)))
+	if c.onExpire != nil {
+		for _, e := range removed {
+			c.onExpire(e.key, e.value)
+		}
+	}
+	return len(removed)
+}
+
+// Close stops the janitor, and waits for it to return. The cache can still
+// be used after Close, but expired entries are only removed when they are
+// looked up or swept.
+func (c *TTL[K, V]) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+	<-c.done
+}
+
+// removeExpired removes the entry of key, if it is still e and has expired.
+func (c *TTL[K, V]) removeExpired(key K, e *ttlEntry[V], now int64) {
+	c.mu.Lock()
+	if c.items[key] != e || !e.expired(now) {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.items, key)
+	c.mu.Unlock()
+
+	c.evictions.Add(1)
+	if c.onExpire != nil {
+		c.onExpire(key, e.value)
+	}
+}
+
+// janitor sweeps the cache every interval, until the cache is closed.
+func (c *TTL[K, V]) janitor(interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Sweep()
+		case <-c.stop:
+			return
+		}
+	}
+}
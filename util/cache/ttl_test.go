@@ -0,0 +1,140 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cache
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	clock := newFakeClock()
+	var expired []string
+	c := NewTTL(&TTLOptions[string, int]{
+		TTL:      time.Minute,
+		OnExpire: func(key string, _ int) { expired = append(expired, key) },
+	})
+	defer c.Close()
+	c.now = clock.now
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("c", 3, 0)
+	if v, ok := c.Get("a"); v != 1 || !ok {
+		t.Errorf("Get(a) = %d, %t, want 1, true", v, ok)
+	}
+
+	clock.advance(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) found an expired entry")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+
+	clock.advance(time.Hour)
+	if n := c.Sweep(); n != 1 {
+		t.Errorf("Sweep() = %d, want 1", n)
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("Get(c) did not find an entry without TTL")
+	}
+	if !slices.Equal(expired, []string{"a", "b"}) {
+		t.Errorf("expired = %v, want [a b]", expired)
+	}
+
+	if !c.Delete("c") || c.Delete("c") {
+		t.Error("Delete(c) did not delete c once")
+	}
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 2 {
+		t.Errorf("Stats() = %+v, want 2 hits, 1 miss and 2 evictions", stats)
+	}
+}
+
+func TestTTLSliding(t *testing.T) {
+	clock := newFakeClock()
+	c := NewTTL(&TTLOptions[string, int]{TTL: time.Minute, Sliding: true})
+	c.now = clock.now
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	for i := 0; i < 5; i++ {
+		clock.advance(40 * time.Second)
+		if _, ok := c.Get("a"); !ok {
+			t.Fatalf("Get(a) #%d did not find a used entry", i)
+		}
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) found an unused expired entry")
+	}
+}
+
+func TestTTLJanitor(t *testing.T) {
+	var mu sync.Mutex
+	var expired []string
+	c := NewTTL(&TTLOptions[string, int]{
+		TTL:           time.Millisecond,
+		SweepInterval: time.Millisecond,
+		OnExpire: func(key string, _ int) {
+			mu.Lock()
+			defer mu.Unlock()
+			expired = append(expired, key)
+		},
+	})
+	c.Set("a", 1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	c.Close()
+	c.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(expired, []string{"a"}) {
+		t.Errorf("expired = %v, want [a]", expired)
+	}
+}
+
+func TestTTLConcurrent(t *testing.T) {
+	c := NewTTL(&TTLOptions[int, int]{TTL: time.Millisecond, Sliding: true, SweepInterval: time.Millisecond})
+	defer c.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Set(j%10, j)
+				c.Get(j % 10)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,78 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package chans
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBatchSize(t *testing.T) {
+	var got [][]int
+	for batch := range Batch(context.Background(), source(1, 2, 3, 4, 5), 2, 0) {
+		got = append(got, batch)
+	}
+	want := [][]int{{1, 2}, {3, 4}, {5}}
+	if !slices.EqualFunc(got, want, slices.Equal[[]int]) {
+		t.Errorf("Batch() = %v, want %v", got, want)
+	}
+}
+
+func TestBatchWait(t *testing.T) {
+	in := make(chan int)
+	out := Batch(context.Background(), in, 10, 10*time.Millisecond)
+	in <- 1
+	in <- 2
+	select {
+	case batch := <-out:
+		if !slices.Equal(batch, []int{1, 2}) {
+			t.Errorf("batch = %v, want [1 2]", batch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("batch not sent after maxWait")
+	}
+
+	in <- 3
+	close(in)
+	if batch := <-out; !slices.Equal(batch, []int{3}) {
+		t.Errorf("last batch = %v, want [3]", batch)
+	}
+	if _, ok := <-out; ok {
+		t.Error("channel not closed")
+	}
+}
+
+func TestBatchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	out := Batch(ctx, in, 10, 0)
+	in <- 1
+	cancel()
+	if batch, ok := <-out; ok {
+		t.Errorf("received %v after cancellation", batch)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package chans implements combinators for channels, such as merging and
+broadcasting.
+
+Every function takes a context. Once it is done, the goroutines started by
+the function stop and close their output channels, so that they don't leak
+even if the output channels are not read until they are closed.
+*/
+package chans
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// OrDone returns a channel that receives the values from in, and is closed
+// when in is closed or ctx is done.
+func OrDone[T any](ctx context.Context, in <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok || !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Merge returns a channel that receives the values from all of the input
+// channels, and is closed when all of them are closed or ctx is done. The
+// order of values from different channels is not defined.
+func Merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func() {
+			defer wg.Done()
+			for {
+				v, ok := recv(ctx, in)
+				if !ok || !send(ctx, out, v) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// FanOut returns n channels that share the values from in, with each value
+// sent to whichever channel is read first. This spreads work across n
+// workers. The channels are closed when in is closed or ctx is done. FanOut
+// panics if n is not positive.
+func FanOut[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	if n <= 0 {
+		panic("chans: non-positive n for FanOut")
+	}
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+		go func() {
+			defer close(outs[i])
+			for {
+				v, ok := recv(ctx, in)
+				if !ok || !send(ctx, outs[i], v) {
+					return
+				}
+			}
+		}()
+	}
+	return result
+}
+
+// Broadcast returns n channels that each receive every value from in. A
+// value is only received from in once it was sent to every channel, so the
+// slowest reader limits the others. The channels are closed when in is
+// closed or ctx is done.
+func Broadcast[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		for {
+			v, ok := recv(ctx, in)
+			if !ok || !sendAll(ctx, outs, v) {
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// Tee returns two channels that each receive every value from in, like
+// [Broadcast] with two channels.
+func Tee[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
+	outs := Broadcast(ctx, in, 2)
+	return outs[0], outs[1]
+}
+
+// Drain receives and discards values from ch until it is closed or ctx is
+// done, and returns the number of values that were discarded. It can be
+// used to let the sender of an abandoned channel finish.
+func Drain[T any](ctx context.Context, ch <-chan T) int {
+	n := 0
+	for {
+		if _, ok := recv(ctx, ch); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// recv receives a value from ch, returning false if ch is closed or ctx is
+// done.
+func recv[T any](ctx context.Context, ch <-chan T) (T, bool) {
+	select {
+	case v, ok := <-ch:
+		return v, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// send sends v to ch, returning false if ctx is done first.
+func send[T any](ctx context.Context, ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendAll sends v to every channel in any order, returning false if ctx is
+// done first.
+func sendAll[T any](ctx context.Context, chs []chan T, v T) bool {
+	cases := make([]reflect.SelectCase, len(chs)+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	send := reflect.ValueOf(&v).Elem()
+	for i, ch := range chs {
+		cases[i+1] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch), Send: send}
+	}
+	for remaining := len(chs); remaining > 0; remaining-- {
+		chosen, _, _ := reflect.Select(cases)
+		if chosen == 0 {
+			return false
+		}
+		// Ignore the channel once the value was sent to it
+		cases[chosen].Chan = reflect.Value{}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package chans
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// source returns a channel that receives the values and is then closed.
+func source(values ...int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for _, v := range values {
+			ch <- v
+		}
+	}()
+	return ch
+}
+
+// collect receives all values from ch until it is closed.
+func collect(ch <-chan int) []int {
+	var values []int
+	for v := range ch {
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestOrDone(t *testing.T) {
+	if got := collect(OrDone(context.Background(), source(1, 2, 3))); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("OrDone() = %v, want [1 2 3]", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := OrDone(ctx, make(chan int))
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("received value after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Error("channel not closed after cancellation")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	got := collect(Merge(context.Background(), source(1, 2), source(3), source(4, 5)))
+	slices.Sort(got)
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	outs := FanOut(context.Background(), source(1, 2, 3, 4, 5, 6), 3)
+	var mu sync.Mutex
+	var got []int
+	var wg sync.WaitGroup
+	for _, out := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values := collect(out)
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, values...)
+		}()
+	}
+	wg.Wait()
+	slices.Sort(got)
+	if want := []int{1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("FanOut() = %v, want %v", got, want)
+	}
+}
+
+func TestFanOutNonPositive(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("FanOut(0) did not panic")
+		}
+	}()
+	FanOut(context.Background(), source(1), 0)
+}
+
+func TestBroadcast(t *testing.T) {
+	outs := Broadcast(context.Background(), source(1, 2, 3), 3)
+	results := make([][]int, len(outs))
+	var wg sync.WaitGroup
+	for i, out := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = collect(out)
+		}()
+	}
+	wg.Wait()
+	for i, got := range results {
+		if !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("Broadcast()[%d] = %v, want [1 2 3]", i, got)
+		}
+	}
+}
+
+func TestTee(t *testing.T) {
+	a, b := Tee(context.Background(), source(1, 2))
+	var gotB []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gotB = collect(b)
+	}()
+	gotA := collect(a)
+	<-done
+	if !slices.Equal(gotA, []int{1, 2}) || !slices.Equal(gotB, []int{1, 2}) {
+		t.Errorf("Tee() = %v, %v, want [1 2], [1 2]", gotA, gotB)
+	}
+}
+
+func TestBroadcastCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	outs := Broadcast(ctx, source(1, 2, 3), 2)
+	<-outs[0]
+	cancel()
+	for _, out := range outs {
+		for range out {
+			// Wait for the channel to be closed
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	if n := Drain(context.Background(), source(1, 2, 3)); n != 3 {
+		t.Errorf("Drain() = %d, want 3", n)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package closer provides a shutdown stack, which closes resources in the
+reverse order they were added.
+
+	c := closer.New(&closer.Options{Timeout: 5 * time.Second})
+	defer c.Close(context.Background())
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	c.Add(db)
+*/
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is wrapped by the error of an item that did not close within
+// the timeout.
+var ErrTimeout = errors.New("closer: close timed out")
+
+// Options configure a [Closer].
+type Options struct {
+	// Timeout is the maximum time to wait for each item to close. If zero,
+	// items are waited for until the context passed to Close is done.
+	Timeout time.Duration
+}
+
+// Closer closes the items added to it in the reverse order they were added.
+// A Closer is safe for concurrent use.
+type Closer struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	items  []func() error
+	closed bool
+}
+
+// New returns a new Closer.
+func New(opts *Options) *Closer {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Closer{timeout: opts.Timeout}
+}
+
+// Add adds c to the closer. If the closer has been closed, c is closed
+// immediately and its error is discarded.
+func (c *Closer) Add(closer io.Closer) {
+	c.AddFunc(closer.Close)
+}
+
+// AddFunc adds fn to the closer. If the closer has been closed, fn is called
+// immediately and its error is discarded.
+func (c *Closer) AddFunc(fn func() error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		_ = fn()
+		return
+	}
+	c.items = append(c.items, fn)
+	c.mu.Unlock()
+}
+
+// Close closes the items in the reverse order they were added, and returns
+// their errors joined. An item that does not close within the timeout is
+// left running in the background, and its error wraps [ErrTimeout]. If ctx
+// is done, the remaining items are not closed and the context's error is
+// returned with the others. Calling Close again has no effect.
+func (c *Closer) Close(ctx context.Context) error {
+	c.mu.Lock()
+	items := c.items
+	c.items = nil
+	c.closed = true
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(items) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := c.closeItem(ctx, items[i]); err != nil {
+			errs = append(errs, fmt.Errorf("closer: item %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeItem calls fn, waiting for the timeout or until ctx is done.
+func (c *Closer) closeItem(ctx context.Context, fn func() error) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrTimeout
+		}
+		return ctx.Err()
+	}
+}
+
+// Len returns the number of items waiting to be closed.
+func (c *Closer) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+type contextKey struct{}
+
+// WithCloser returns a copy of ctx that carries c.
+func WithCloser(ctx context.Context, c *Closer) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the closer carried by ctx, or nil.
+func FromContext(ctx context.Context) *Closer {
+	c, _ := ctx.Value(contextKey{}).(*Closer)
+	return c
+}
+
+// Register adds c to the closer carried by ctx, and reports whether ctx
+// carries a closer.
+func Register(ctx context.Context, c io.Closer) bool {
+	return RegisterFunc(ctx, c.Close)
+}
+
+// RegisterFunc adds fn to the closer carried by ctx, and reports whether ctx
+// carries a closer.
+func RegisterFunc(ctx context.Context, fn func() error) bool {
+	c := FromContext(ctx)
+	if c == nil {
+		return false
+	}
+	c.AddFunc(fn)
+	return true
+}
@@ -0,0 +1,139 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package closer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestClose(t *testing.T) {
+	c := New(nil)
+	var order []int
+	for i := 0; i < 3; i++ {
+		c.AddFunc(func() error {
+			order = append(order, i)
+			if i == 1 {
+				return errTest
+			}
+			return nil
+		})
+	}
+	c.Add(closerFunc(func() error {
+		order = append(order, 3)
+		return nil
+	}))
+	if n := c.Len(); n != 4 {
+		t.Errorf("Len() = %d, want 4", n)
+	}
+
+	if err := c.Close(context.Background()); !errors.Is(err, errTest) {
+		t.Errorf("Close() error = %v, want %v", err, errTest)
+	}
+	if want := []int{3, 2, 1, 0}; !slices.Equal(order, want) {
+		t.Errorf("close order = %v, want %v", order, want)
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	closed := false
+	c.AddFunc(func() error {
+		closed = true
+		return nil
+	})
+	if !closed {
+		t.Error("AddFunc() after Close did not close the item")
+	}
+}
+
+func TestCloseTimeout(t *testing.T) {
+	c := New(&Options{Timeout: 10 * time.Millisecond})
+	release := make(chan struct{})
+	defer close(release)
+	closed := false
+	c.AddFunc(func() error {
+		closed = true
+		return nil
+	})
+	c.AddFunc(func() error {
+		<-release
+		return nil
+	})
+
+	if err := c.Close(context.Background()); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Close() error = %v, want %v", err, ErrTimeout)
+	}
+	if !closed {
+		t.Error("item after timed out item not closed")
+	}
+}
+
+func TestCloseContext(t *testing.T) {
+	c := New(nil)
+	closed := false
+	c.AddFunc(func() error {
+		closed = true
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Close(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Close() error = %v, want %v", err, context.Canceled)
+	}
+	if closed {
+		t.Error("item closed after context was done")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if RegisterFunc(context.Background(), func() error { return nil }) {
+		t.Error("RegisterFunc() = true without closer")
+	}
+
+	c := New(nil)
+	ctx := WithCloser(context.Background(), c)
+	if FromContext(ctx) != c {
+		t.Error("FromContext() did not return the closer")
+	}
+	if !Register(ctx, closerFunc(func() error { return nil })) {
+		t.Error("Register() = false with closer")
+	}
+	if n := c.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+}
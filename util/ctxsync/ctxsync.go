@@ -0,0 +1,72 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package ctxsync provides mutexes that stop waiting when a context is done.
+
+Mutexes can record the goroutine holding them, which is included in the
+error returned when acquiring the lock fails, to help diagnose deadlocks.
+*/
+package ctxsync
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Holder describes the holder of a lock.
+type Holder struct {
+	// Since is the time the lock was acquired.
+	Since time.Time
+
+	// Stack is the stack trace of the goroutine that acquired the lock.
+	Stack []byte
+}
+
+func newHolder() *Holder {
+	return &Holder{Since: time.Now(), Stack: debug.Stack()}
+}
+
+// LockError is returned when the context is done before a lock is acquired.
+type LockError struct {
+	// Err is the context's error.
+	Err error
+
+	// Holder is the holder of the lock at the time, if the mutex records
+	// holders and the lock was held for writing.
+	Holder *Holder
+}
+
+func (e *LockError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("ctxsync: lock not acquired: %v", e.Err)
+	}
+	return fmt.Sprintf("ctxsync: lock not acquired: %v; held for %s by\n\n%s",
+		e.Err, time.Since(e.Holder.Since).Round(time.Millisecond), e.Holder.Stack)
+}
+
+func (e *LockError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,115 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ctxsync
+
+import (
+	"context"
+	"sync"
+)
+
+// Mutex is a mutual exclusion lock that can stop waiting when a context is
+// done. The zero value is an unlocked mutex.
+//
+// A Mutex must not be copied after first use.
+type Mutex struct {
+	// RecordHolder makes the mutex record the stack trace of the goroutine
+	// holding it. Recording has a cost, so it is disabled by default.
+	RecordHolder bool
+
+	once   sync.Once
+	ch     chan struct{}
+	mu     sync.Mutex
+	holder *Holder
+}
+
+func (m *Mutex) init() {
+	m.once.Do(func() {
+		m.ch = make(chan struct{}, 1)
+	})
+}
+
+// Lock locks m, waiting until it is available or ctx is done. If ctx is
+// done first, a [*LockError] is returned.
+func (m *Mutex) Lock(ctx context.Context) error {
+	m.init()
+	select {
+	case m.ch <- struct{}{}:
+		m.locked()
+		return nil
+	default:
+	}
+
+	select {
+	case m.ch <- struct{}{}:
+		m.locked()
+		return nil
+	case <-ctx.Done():
+		return &LockError{Err: ctx.Err(), Holder: m.Holder()}
+	}
+}
+
+// TryLock tries to lock m without waiting, and reports whether it
+// succeeded.
+func (m *Mutex) TryLock() bool {
+	m.init()
+	select {
+	case m.ch <- struct{}{}:
+		m.locked()
+		return true
+	default:
+		return false
+	}
+}
+
+// Unlock unlocks m. It panics if m is not locked.
+func (m *Mutex) Unlock() {
+	m.init()
+	m.mu.Lock()
+	m.holder = nil
+	m.mu.Unlock()
+	select {
+	case <-m.ch:
+	default:
+		panic("ctxsync: unlock of unlocked mutex")
+	}
+}
+
+// Holder returns the holder of m, or nil if m is not locked or does not
+// record holders.
+func (m *Mutex) Holder() *Holder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.holder
+}
+
+func (m *Mutex) locked() {
+	if !m.RecordHolder {
+		return
+	}
+	h := newHolder()
+	m.mu.Lock()
+	m.holder = h
+	m.mu.Unlock()
+}
@@ -0,0 +1,95 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ctxsync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMutex(t *testing.T) {
+	ctx := context.Background()
+	var m Mutex
+	var n int
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Lock(ctx); err != nil {
+				t.Errorf("Lock() error = %v", err)
+				return
+			}
+			n++
+			m.Unlock()
+		}()
+	}
+	wg.Wait()
+	if n != 50 {
+		t.Errorf("n = %d, want 50", n)
+	}
+}
+
+func TestMutexContext(t *testing.T) {
+	m := Mutex{RecordHolder: true}
+	if !m.TryLock() {
+		t.Fatal("TryLock() = false, want true")
+	}
+	if m.TryLock() {
+		t.Fatal("TryLock() = true while locked")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.Lock(ctx)
+	var lerr *LockError
+	if !errors.As(err, &lerr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Lock() error = %v, want LockError wrapping %v", err, context.DeadlineExceeded)
+	}
+	if lerr.Holder == nil || len(lerr.Holder.Stack) == 0 {
+		t.Error("LockError.Holder not recorded")
+	}
+
+	m.Unlock()
+	if m.Holder() != nil {
+		t.Error("Holder() != nil after Unlock")
+	}
+	if err := m.Lock(context.Background()); err != nil {
+		t.Errorf("Lock() error = %v", err)
+	}
+}
+
+func TestMutexUnlockPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Unlock() of unlocked mutex did not panic")
+		}
+	}()
+	var m Mutex
+	m.Unlock()
+}
@@ -0,0 +1,180 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ctxsync
+
+import (
+	"context"
+	"sync"
+)
+
+// RWMutex is a reader/writer mutual exclusion lock that can stop waiting
+// when a context is done. Like [sync.RWMutex], a waiting writer blocks new
+// readers. The zero value is an unlocked mutex.
+//
+// A RWMutex must not be copied after first use.
+type RWMutex struct {
+	// RecordHolder makes the mutex record the stack trace of the goroutine
+	// holding it for writing. Recording has a cost, so it is disabled by
+	// default.
+	RecordHolder bool
+
+	mu      sync.Mutex
+	readers int
+	writer  bool
+	waiting int
+	holder  *Holder
+
+	// changed is closed and replaced when the lock is released.
+	changed chan struct{}
+}
+
+// Lock locks rw for writing, waiting until it is available or ctx is done.
+// If ctx is done first, a [*LockError] is returned.
+func (rw *RWMutex) Lock(ctx context.Context) error {
+	rw.mu.Lock()
+	rw.waiting++
+	defer func() {
+		rw.waiting--
+		rw.mu.Unlock()
+	}()
+	for {
+		if !rw.writer && rw.readers == 0 {
+			rw.lockLocked()
+			return nil
+		}
+		if err := rw.wait(ctx); err != nil {
+			// Readers blocked by this writer may continue.
+			rw.notifyLocked()
+			return err
+		}
+	}
+}
+
+// TryLock tries to lock rw for writing without waiting, and reports whether
+// it succeeded.
+func (rw *RWMutex) TryLock() bool {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.writer || rw.readers > 0 {
+		return false
+	}
+	rw.lockLocked()
+	return true
+}
+
+// Unlock unlocks rw for writing. It panics if rw is not locked for writing.
+func (rw *RWMutex) Unlock() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if !rw.writer {
+		panic("ctxsync: unlock of unlocked RWMutex")
+	}
+	rw.writer = false
+	rw.holder = nil
+	rw.notifyLocked()
+}
+
+// RLock locks rw for reading, waiting until it is available or ctx is done.
+// If ctx is done first, a [*LockError] is returned.
+func (rw *RWMutex) RLock(ctx context.Context) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	for {
+		if !rw.writer && rw.waiting == 0 {
+			rw.readers++
+			return nil
+		}
+		if err := rw.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// TryRLock tries to lock rw for reading without waiting, and reports
+// whether it succeeded.
+func (rw *RWMutex) TryRLock() bool {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.writer || rw.waiting > 0 {
+		return false
+	}
+	rw.readers++
+	return true
+}
+
+// RUnlock undoes a single call to RLock. It panics if rw is not locked for
+// reading.
+func (rw *RWMutex) RUnlock() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.readers == 0 {
+		panic("ctxsync: RUnlock of unlocked RWMutex")
+	}
+	rw.readers--
+	if rw.readers == 0 {
+		rw.notifyLocked()
+	}
+}
+
+// Holder returns the holder of rw for writing, or nil if rw is not locked
+// for writing or does not record holders.
+func (rw *RWMutex) Holder() *Holder {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return rw.holder
+}
+
+func (rw *RWMutex) lockLocked() {
+	rw.writer = true
+	if rw.RecordHolder {
+		rw.holder = newHolder()
+	}
+}
+
+// wait waits for the lock to be released, or for ctx to be done. rw.mu must
+// be held, and is held again when wait returns.
+func (rw *RWMutex) wait(ctx context.Context) error {
+	if rw.changed == nil {
+		rw.changed = make(chan struct{})
+	}
+	changed := rw.changed
+	rw.mu.Unlock()
+	defer rw.mu.Lock()
+
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return &LockError{Err: ctx.Err(), Holder: rw.Holder()}
+	}
+}
+
+// notifyLocked wakes every waiting goroutine. rw.mu must be held.
+func (rw *RWMutex) notifyLocked() {
+	if rw.changed != nil {
+		close(rw.changed)
+		rw.changed = nil
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ctxsync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRWMutex(t *testing.T) {
+	ctx := context.Background()
+	var rw RWMutex
+	var n int
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := rw.Lock(ctx); err != nil {
+				t.Errorf("Lock() error = %v", err)
+				return
+			}
+			n++
+			rw.Unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			if err := rw.RLock(ctx); err != nil {
+				t.Errorf("RLock() error = %v", err)
+				return
+			}
+			_ = n
+			rw.RUnlock()
+		}()
+	}
+	wg.Wait()
+	if n != 50 {
+		t.Errorf("n = %d, want 50", n)
+	}
+}
+
+func TestRWMutexReaders(t *testing.T) {
+	var rw RWMutex
+	if !rw.TryRLock() || !rw.TryRLock() {
+		t.Fatal("TryRLock() = false, want true")
+	}
+	if rw.TryLock() {
+		t.Fatal("TryLock() = true while read locked")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rw.Lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Lock() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// A reader may lock again once the waiting writer gave up.
+	if !rw.TryRLock() {
+		t.Fatal("TryRLock() = false after writer gave up")
+	}
+	rw.RUnlock()
+	rw.RUnlock()
+	rw.RUnlock()
+	if !rw.TryLock() {
+		t.Fatal("TryLock() = false after RUnlock")
+	}
+}
+
+func TestRWMutexWriterPreference(t *testing.T) {
+	rw := RWMutex{RecordHolder: true}
+	if err := rw.RLock(context.Background()); err != nil {
+		t.Fatalf("RLock() error = %v", err)
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		if err := rw.Lock(context.Background()); err != nil {
+			t.Errorf("Lock() error = %v", err)
+		}
+		close(locked)
+	}()
+	for {
+		rw.mu.Lock()
+		waiting := rw.waiting
+		rw.mu.Unlock()
+		if waiting > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if rw.TryRLock() {
+		t.Fatal("TryRLock() = true while a writer is waiting")
+	}
+	rw.RUnlock()
+	<-locked
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := rw.RLock(ctx)
+	var lerr *LockError
+	if !errors.As(err, &lerr) || lerr.Holder == nil {
+		t.Errorf("RLock() error = %v, want LockError with holder", err)
+	}
+	rw.Unlock()
+}
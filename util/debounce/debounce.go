@@ -0,0 +1,170 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package debounce coalesces bursts of calls into a single call, made once the
+calls have stopped for a while. This is useful for events that arrive in
+bursts, such as file changes triggering a config reload.
+
+	reload := debounce.New(func(path string) { ... }, time.Second, nil)
+	for event := range events {
+		reload.Call(event.Path)
+	}
+*/
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Options configure a [Debouncer].
+type Options struct {
+	// Leading calls the function at the start of a burst, as well as at the
+	// end.
+	Leading bool
+
+	// DisableTrailing disables calling the function at the end of a burst.
+	// It should be used with Leading.
+	DisableTrailing bool
+}
+
+// Debouncer calls a function once calls to [Debouncer.Call] have stopped
+// for a wait duration, with the value of the last call. A Debouncer is safe
+// for concurrent use, and the function is never called concurrently.
+type Debouncer[T any] struct {
+	f        func(T)
+	wait     time.Duration
+	leading  bool
+	trailing bool
+	callMu   sync.Mutex
+
+	mu         sync.Mutex
+	timer      *time.Timer
+	generation uint64
+	pending    bool
+	value      T
+}
+
+// New returns a [Debouncer] that calls f once calls have stopped for wait.
+// If opts is nil, the default options are used.
+func New[T any](f func(T), wait time.Duration, opts *Options) *Debouncer[T] {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Debouncer[T]{
+		f:        f,
+		wait:     wait,
+		leading:  opts.Leading,
+		trailing: !opts.DisableTrailing,
+	}
+}
+
+// Call records a call with v, delaying the call to the function until wait
+// has passed without another call.
+func (d *Debouncer[T]) Call(v T) {
+	d.mu.Lock()
+	leading := d.timer == nil && d.leading
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	if !leading && d.trailing {
+		d.pending = true
+		d.value = v
+	}
+	d.generation++
+	generation := d.generation
+	d.timer = time.AfterFunc(d.wait, func() {
+		d.fire(generation)
+	})
+	d.mu.Unlock()
+
+	if leading {
+		d.call(v)
+	}
+}
+
+// fire ends a burst, calling the function if a call is pending.
+func (d *Debouncer[T]) fire(generation uint64) {
+	d.mu.Lock()
+	if generation != d.generation {
+		d.mu.Unlock()
+		return
+	}
+	d.timer = nil
+	d.flush()
+}
+
+// Flush ends the current burst immediately, calling the function if a call
+// is pending.
+func (d *Debouncer[T]) Flush() {
+	d.mu.Lock()
+	d.stop()
+	d.flush()
+}
+
+// flush calls the function if a call is pending. The lock must be held, and
+// is released.
+func (d *Debouncer[T]) flush() {
+	pending, v := d.pending, d.value
+	d.pending = false
+	var zero T
+	d.value = zero
+	d.mu.Unlock()
+	if pending {
+		d.call(v)
+	}
+}
+
+// Cancel ends the current burst without calling the function.
+func (d *Debouncer[T]) Cancel() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stop()
+	d.pending = false
+	var zero T
+	d.value = zero
+}
+
+// Pending reports whether a call to the function is pending.
+func (d *Debouncer[T]) Pending() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pending
+}
+
+// stop stops the timer of the current burst. The lock must be held.
+func (d *Debouncer[T]) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.generation++
+}
+
+func (d *Debouncer[T]) call(v T) {
+	d.callMu.Lock()
+	defer d.callMu.Unlock()
+	d.f(v)
+}
@@ -0,0 +1,92 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package debounce
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the values a function is called with.
+type recorder struct {
+	mu     sync.Mutex
+	values []int
+}
+
+func (r *recorder) call(v int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = append(r.values, v)
+}
+
+func (r *recorder) get() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.values)
+}
+
+func TestDebouncer(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *Options
+		want []int
+	}{
+		{name: "trailing", want: []int{3}},
+		{name: "leading", opts: &Options{Leading: true}, want: []int{1, 3}},
+		{name: "leading only", opts: &Options{Leading: true, DisableTrailing: true}, want: []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := new(recorder)
+			d := New(r.call, 20*time.Millisecond, tt.opts)
+			for i := 1; i <= 3; i++ {
+				d.Call(i)
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(60 * time.Millisecond)
+			if got := r.get(); !slices.Equal(got, tt.want) {
+				t.Errorf("calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebouncerFlushCancel(t *testing.T) {
+	r := new(recorder)
+	d := New(r.call, time.Hour, nil)
+	d.Call(1)
+	if !d.Pending() {
+		t.Error("Pending() = false, want true")
+	}
+	d.Flush()
+	d.Call(2)
+	d.Cancel()
+	d.Flush()
+	if got := r.get(); !slices.Equal(got, []int{1}) {
+		t.Errorf("calls = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package eventbus provides a typed in-process event bus, so that packages can
+react to each other's events without depending on each other.
+
+	bus := eventbus.New(nil)
+	sub := eventbus.Subscribe(bus, func(ctx context.Context, e UserCreated) error { ... })
+	defer sub.Cancel()
+	err := eventbus.Publish(ctx, bus, UserCreated{ID: id})
+
+Handlers are chosen by the type of the event, so a handler for an interface
+type does not receive events of the types implementing it.
+*/
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"hypera.dev/lib/util/internal/panics"
+)
+
+// PanicError is the error of a handler that panicked, holding the value
+// passed to panic and the stack trace.
+type PanicError = panics.Error
+
+// Options configure a [Bus].
+type Options struct {
+	// OnAsyncError is called with the event when an asynchronous handler
+	// returns an error or panics. If nil, the error is discarded.
+	OnAsyncError func(event any, err error)
+}
+
+// Bus delivers published events to the handlers subscribed to their type.
+// A Bus is safe for concurrent use.
+type Bus struct {
+	onAsyncError func(event any, err error)
+
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]*Subscription
+	wg       sync.WaitGroup
+}
+
+// New returns a new Bus.
+func New(opts *Options) *Bus {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Bus{
+		onAsyncError: opts.OnAsyncError,
+		handlers:     make(map[reflect.Type][]*Subscription),
+	}
+}
+
+// Subscription is a handler subscribed to a [Bus].
+type Subscription struct {
+	bus       *Bus
+	typ       reflect.Type
+	async     bool
+	handle    func(ctx context.Context, event any) error
+	cancelled atomic.Bool
+}
+
+// Cancel unsubscribes the handler. Events published after Cancel returns
+// are not delivered to it, but asynchronous deliveries that already started
+// are not interrupted.
+func (s *Subscription) Cancel() {
+	if s.cancelled.Swap(true) {
+		return
+	}
+	b := s.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := slices.DeleteFunc(slices.Clone(b.handlers[s.typ]), func(other *Subscription) bool {
+		return other == s
+	})
+	if len(subs) == 0 {
+		delete(b.handlers, s.typ)
+	} else {
+		b.handlers[s.typ] = subs
+	}
+}
+
+// Subscribe subscribes handler to events of type T. The handler is called
+// synchronously by [Publish], and its error is returned to the publisher.
+func Subscribe[T any](b *Bus, handler func(ctx context.Context, event T) error) *Subscription {
+	return subscribe(b, handler, false)
+}
+
+// SubscribeAsync subscribes handler to events of type T. The handler is
+// called in a new goroutine, with a context that is not cancelled when the
+// publisher's context is. Its error is passed to [Options.OnAsyncError].
+func SubscribeAsync[T any](b *Bus, handler func(ctx context.Context, event T) error) *Subscription {
+	return subscribe(b, handler, true)
+}
+
+func subscribe[T any](b *Bus, handler func(ctx context.Context, event T) error, async bool) *Subscription {
+	s := &Subscription{
+		bus:   b,
+		typ:   reflect.TypeFor[T](),
+		async: async,
+		handle: func(ctx context.Context, event any) error {
+			return handler(ctx, event.(T))
+		},
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[s.typ] = append(b.handlers[s.typ], s)
+	return s
+}
+
+// Publish delivers event to the handlers subscribed to type T, in the order
+// they subscribed. Asynchronous handlers are started first, then synchronous
+// handlers are called. A handler that panics does not stop the others, and
+// its error is a [*PanicError]. The errors of the synchronous handlers are
+// joined and returned.
+func Publish[T any](ctx context.Context, b *Bus, event T) error {
+	b.mu.RLock()
+	subs := b.handlers[reflect.TypeFor[T]()]
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		if !s.async || s.cancelled.Load() {
+			continue
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			if err := s.call(context.WithoutCancel(ctx), event); err != nil && b.onAsyncError != nil {
+				b.onAsyncError(event, err)
+			}
+		}()
+	}
+
+	var errs []error
+	for _, s := range subs {
+		if s.async || s.cancelled.Load() {
+			continue
+		}
+		if err := s.call(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// call calls the handler, converting a panic to a [*PanicError].
+func (s *Subscription) call(ctx context.Context, event any) error {
+	return panics.Call(func() error {
+		return s.handle(ctx, event)
+	})
+}
+
+// Wait waits for the asynchronous handlers that have been started to
+// return.
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
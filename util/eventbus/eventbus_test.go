@@ -0,0 +1,150 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type created struct{ ID int }
+
+type deleted struct{ ID int }
+
+var errTest = errors.New("test error")
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	b := New(nil)
+
+	var got []string
+	Subscribe(b, func(_ context.Context, e created) error {
+		got = append(got, "first")
+		return nil
+	})
+	Subscribe(b, func(_ context.Context, e created) error {
+		got = append(got, "second")
+		return nil
+	})
+	Subscribe(b, func(_ context.Context, e deleted) error {
+		got = append(got, "deleted")
+		return nil
+	})
+
+	if err := Publish(ctx, b, created{ID: 1}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if want := []string{"first", "second"}; !slices.Equal(got, want) {
+		t.Errorf("handlers called = %v, want %v", got, want)
+	}
+	if err := Publish(ctx, b, "unrelated"); err != nil {
+		t.Errorf("Publish() without handlers error = %v", err)
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	b := New(nil)
+	called := false
+	Subscribe(b, func(context.Context, created) error { panic("boom") })
+	Subscribe(b, func(context.Context, created) error { return errTest })
+	Subscribe(b, func(context.Context, created) error {
+		called = true
+		return nil
+	})
+
+	err := Publish(context.Background(), b, created{})
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Errorf("Publish() error = %v, want PanicError", err)
+	}
+	if !errors.Is(err, errTest) {
+		t.Errorf("Publish() error = %v, want %v", err, errTest)
+	}
+	if !called {
+		t.Error("handler after failing handlers not called")
+	}
+}
+
+func TestSubscribeAsync(t *testing.T) {
+	var mu sync.Mutex
+	var asyncErrs []error
+	b := New(&Options{
+		OnAsyncError: func(_ any, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			asyncErrs = append(asyncErrs, err)
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var ids []int
+	SubscribeAsync(b, func(ctx context.Context, e created) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		ids = append(ids, e.ID)
+		return nil
+	})
+	SubscribeAsync(b, func(context.Context, created) error { return errTest })
+
+	if err := Publish(ctx, b, created{ID: 7}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	cancel()
+	b.Wait()
+
+	if !slices.Equal(ids, []int{7}) {
+		t.Errorf("async handler received %v, want [7]", ids)
+	}
+	if len(asyncErrs) != 1 || !errors.Is(asyncErrs[0], errTest) {
+		t.Errorf("async errors = %v, want [%v]", asyncErrs, errTest)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx := context.Background()
+	b := New(nil)
+	var calls int
+	sub := Subscribe(b, func(context.Context, created) error {
+		calls++
+		return nil
+	})
+
+	_ = Publish(ctx, b, created{})
+	sub.Cancel()
+	sub.Cancel()
+	_ = Publish(ctx, b, created{})
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if len(b.handlers) != 0 {
+		t.Errorf("handlers = %v, want none", b.handlers)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package future provides typed futures, which hold the result of an
+asynchronous operation that can be awaited and combined.
+
+	user := future.Go(func() (*User, error) { return fetchUser(id) })
+	name := future.Map(user, func(u *User) string { return u.Name })
+	v, err := name.Await(ctx)
+*/
+package future
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"hypera.dev/lib/util/internal/panics"
+)
+
+// PanicError is the error of a future whose function panicked, holding the
+// value passed to panic and the stack trace.
+type PanicError = panics.Error
+
+// Future is the result of an asynchronous operation. A Future is resolved
+// once, and is safe for concurrent use.
+type Future[T any] struct {
+	once sync.Once
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// New returns an unresolved future, and a function that resolves it. Only
+// the first call to resolve has an effect.
+func New[T any]() (*Future[T], func(v T, err error)) {
+	f := &Future[T]{done: make(chan struct{})}
+	return f, f.resolve
+}
+
+// Go calls fn in a new goroutine, and returns a future resolved with its
+// result. If fn panics, the future's error is a [*PanicError].
+func Go[T any](fn func() (T, error)) *Future[T] {
+	f, resolve := New[T]()
+	go func() {
+		var v T
+		err := panics.Call(func() error {
+			var err error
+			v, err = fn()
+			return err
+		})
+		resolve(v, err)
+	}()
+	return f
+}
+
+// Value returns a future resolved with v.
+func Value[T any](v T) *Future[T] {
+	f, resolve := New[T]()
+	resolve(v, nil)
+	return f
+}
+
+// Error returns a future resolved with err.
+func Error[T any](err error) *Future[T] {
+	f, resolve := New[T]()
+	var zero T
+	resolve(zero, err)
+	return f
+}
+
+func (f *Future[T]) resolve(v T, err error) {
+	f.once.Do(func() {
+		f.val, f.err = v, err
+		close(f.done)
+	})
+}
+
+// Done returns a channel that is closed once the future is resolved.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await waits for the future to be resolved and returns its result. If ctx
+// is done first, the context's error is returned.
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Result returns the result of the future without waiting. ok is false if
+// the future has not been resolved.
+func (f *Future[T]) Result() (T, bool, error) {
+	select {
+	case <-f.done:
+		return f.val, true, f.err
+	default:
+		var zero T
+		return zero, false, nil
+	}
+}
+
+// Then returns a future resolved with the result of calling fn with the
+// value of f. If f fails, fn is not called and the returned future fails
+// with the same error.
+func Then[T, U any](f *Future[T], fn func(v T) (U, error)) *Future[U] {
+	return Go(func() (U, error) {
+		<-f.done
+		if f.err != nil {
+			var zero U
+			return zero, f.err
+		}
+		return fn(f.val)
+	})
+}
+
+// Map is like [Then], for functions that cannot fail.
+func Map[T, U any](f *Future[T], fn func(v T) U) *Future[U] {
+	return Then(f, func(v T) (U, error) {
+		return fn(v), nil
+	})
+}
+
+// All returns a future resolved with the values of every future, in order.
+// If any of the futures fails, the returned future fails with its error
+// without waiting for the others.
+func All[T any](fs ...*Future[T]) *Future[[]T] {
+	all, resolve := New[[]T]()
+	if len(fs) == 0 {
+		resolve([]T{}, nil)
+		return all
+	}
+
+	var mu sync.Mutex
+	values := make([]T, len(fs))
+	remaining := len(fs)
+	for i, f := range fs {
+		go func() {
+			<-f.done
+			if f.err != nil {
+				resolve(nil, f.err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			values[i] = f.val
+			remaining--
+			if remaining == 0 {
+				resolve(values, nil)
+			}
+		}()
+	}
+	return all
+}
+
+// Any returns a future resolved with the value of the first future to
+// succeed. If every future fails, the returned future fails with all of
+// their errors joined.
+func Any[T any](fs ...*Future[T]) *Future[T] {
+	first, resolve := New[T]()
+	if len(fs) == 0 {
+		var zero T
+		resolve(zero, errors.New("future: Any called without futures"))
+		return first
+	}
+
+	var mu sync.Mutex
+	errs := make([]error, len(fs))
+	remaining := len(fs)
+	for i, f := range fs {
+		go func() {
+			<-f.done
+			if f.err == nil {
+				resolve(f.val, nil)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			errs[i] = f.err
+			remaining--
+			if remaining == 0 {
+				var zero T
+				resolve(zero, errors.Join(errs...))
+			}
+		}()
+	}
+	return first
+}
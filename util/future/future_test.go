@@ -0,0 +1,149 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package future
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+func TestGo(t *testing.T) {
+	ctx := context.Background()
+
+	v, err := Go(func() (int, error) { return 42, nil }).Await(ctx)
+	if v != 42 || err != nil {
+		t.Errorf("Await() = %d, %v, want 42, nil", v, err)
+	}
+
+	_, err = Go(func() (int, error) { return 0, errTest }).Await(ctx)
+	if !errors.Is(err, errTest) {
+		t.Errorf("Await() error = %v, want %v", err, errTest)
+	}
+
+	_, err = Go(func() (int, error) { panic("boom") }).Await(ctx)
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Errorf("Await() error = %v, want PanicError", err)
+	}
+}
+
+func TestAwaitContext(t *testing.T) {
+	f, resolve := New[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.Await(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Await() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, ok, _ := f.Result(); ok {
+		t.Error("Result() ok = true before resolve")
+	}
+
+	resolve(1, nil)
+	resolve(2, nil)
+	if v, ok, err := f.Result(); v != 1 || !ok || err != nil {
+		t.Errorf("Result() = %d, %t, %v, want 1, true, nil", v, ok, err)
+	}
+}
+
+func TestThen(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := Map(Value(21), func(v int) string { return strconv.Itoa(v * 2) }).Await(ctx)
+	if s != "42" || err != nil {
+		t.Errorf("Map() = %q, %v, want \"42\", nil", s, err)
+	}
+
+	called := false
+	_, err = Then(Error[int](errTest), func(int) (string, error) {
+		called = true
+		return "", nil
+	}).Await(ctx)
+	if !errors.Is(err, errTest) || called {
+		t.Errorf("Then() error = %v, called = %t, want %v, false", err, called, errTest)
+	}
+}
+
+func TestAll(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		futures []*Future[int]
+		want    []int
+		wantErr error
+	}{
+		{
+			name:    "empty",
+			futures: nil,
+			want:    []int{},
+		},
+		{
+			name:    "values",
+			futures: []*Future[int]{Value(1), Go(func() (int, error) { return 2, nil }), Value(3)},
+			want:    []int{1, 2, 3},
+		},
+		{
+			name:    "error",
+			futures: []*Future[int]{Value(1), Error[int](errTest), func() *Future[int] { f, _ := New[int](); return f }()},
+			wantErr: errTest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := All(tt.futures...).Await(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("All() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("All() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAny(t *testing.T) {
+	ctx := context.Background()
+	pending, _ := New[int]()
+
+	v, err := Any(Error[int](errTest), pending, Value(2)).Await(ctx)
+	if v != 2 || err != nil {
+		t.Errorf("Any() = %d, %v, want 2, nil", v, err)
+	}
+
+	errOther := errors.New("other")
+	_, err = Any(Error[int](errTest), Error[int](errOther)).Await(ctx)
+	if !errors.Is(err, errTest) || !errors.Is(err, errOther) {
+		t.Errorf("Any() error = %v, want both errors", err)
+	}
+
+	if _, err = Any[int]().Await(ctx); err == nil {
+		t.Error("Any() error = nil, want error")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package gate provides synchronisation primitives for start-up ordering, such
+as waiting until migrations have completed before serving requests.
+*/
+package gate
+
+import (
+	"context"
+	"sync"
+)
+
+// Gate is closed until it is opened, after which it stays open. The zero
+// value is a closed gate, and a Gate is safe for concurrent use.
+type Gate struct {
+	initOnce sync.Once
+	openOnce sync.Once
+	ch       chan struct{}
+}
+
+func (g *Gate) init() {
+	g.initOnce.Do(func() {
+		g.ch = make(chan struct{})
+	})
+}
+
+// Open opens the gate, releasing every waiting goroutine. Calling Open on
+// an open gate has no effect.
+func (g *Gate) Open() {
+	g.init()
+	g.openOnce.Do(func() { close(g.ch) })
+}
+
+// Done returns a channel that is closed once the gate is opened.
+func (g *Gate) Done() <-chan struct{} {
+	g.init()
+	return g.ch
+}
+
+// IsOpen reports whether the gate is open.
+func (g *Gate) IsOpen() bool {
+	select {
+	case <-g.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits for the gate to be opened. If ctx is done first, the context's
+// error is returned.
+func (g *Gate) Wait(ctx context.Context) error {
+	select {
+	case <-g.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Latch is a gate that opens once it has been counted down a number of
+// times. A Latch is safe for concurrent use.
+type Latch struct {
+	gate Gate
+
+	mu    sync.Mutex
+	count int
+}
+
+// NewLatch returns a latch that opens after n calls to [Latch.CountDown].
+// If n is zero or less, the latch is already open.
+func NewLatch(n int) *Latch {
+	l := &Latch{count: n}
+	if n <= 0 {
+		l.gate.Open()
+	}
+	return l
+}
+
+// CountDown decrements the count of the latch, opening it once the count
+// reaches zero. Calling CountDown on an open latch has no effect.
+func (l *Latch) CountDown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.count <= 0 {
+		return
+	}
+	l.count--
+	if l.count == 0 {
+		l.gate.Open()
+	}
+}
+
+// Count returns the remaining count of the latch.
+func (l *Latch) Count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count
+}
+
+// Done returns a channel that is closed once the latch is open.
+func (l *Latch) Done() <-chan struct{} {
+	return l.gate.Done()
+}
+
+// Wait waits for the latch to open. If ctx is done first, the context's
+// error is returned.
+func (l *Latch) Wait(ctx context.Context) error {
+	return l.gate.Wait(ctx)
+}
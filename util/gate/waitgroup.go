@@ -0,0 +1,91 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package gate
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"hypera.dev/lib/util/internal/panics"
+)
+
+// PanicError is the error of a goroutine that panicked, holding the value
+// passed to panic and the stack trace.
+type PanicError = panics.Error
+
+// ErrWaitGroup waits for a collection of goroutines, and collects their
+// errors. Unlike an errgroup, a failing goroutine does not cancel the
+// others. The zero value is ready to use, and an ErrWaitGroup is safe for
+// concurrent use.
+type ErrWaitGroup struct {
+	wg sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// Go calls fn in a new goroutine. If fn panics, its error is a
+// [*PanicError].
+func (g *ErrWaitGroup) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := panics.Call(fn); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for every goroutine started by Go to return, and returns their
+// errors joined, in the order they returned.
+func (g *ErrWaitGroup) Wait() error {
+	g.wg.Wait()
+	return g.err()
+}
+
+// WaitContext is like Wait, but stops waiting once ctx is done, returning
+// the context's error joined with the errors collected so far.
+func (g *ErrWaitGroup) WaitContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return g.err()
+	case <-ctx.Done():
+		return errors.Join(ctx.Err(), g.err())
+	}
+}
+
+func (g *ErrWaitGroup) err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
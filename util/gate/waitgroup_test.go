@@ -0,0 +1,80 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package gate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+func TestErrWaitGroup(t *testing.T) {
+	var g ErrWaitGroup
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() on empty group error = %v", err)
+	}
+
+	done := 0
+	g.Go(func() error { return errTest })
+	g.Go(func() error { panic("boom") })
+	g.Go(func() error {
+		time.Sleep(10 * time.Millisecond)
+		done++
+		return nil
+	})
+
+	err := g.Wait()
+	if !errors.Is(err, errTest) {
+		t.Errorf("Wait() error = %v, want %v", err, errTest)
+	}
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Errorf("Wait() error = %v, want PanicError", err)
+	}
+	if done != 1 {
+		t.Error("Wait() returned before every goroutine")
+	}
+}
+
+func TestErrWaitGroupContext(t *testing.T) {
+	var g ErrWaitGroup
+	release := make(chan struct{})
+	defer close(release)
+	g.Go(func() error { return errTest })
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := g.WaitContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errTest) {
+		t.Errorf("WaitContext() error = %v, want %v and %v", err, context.DeadlineExceeded, errTest)
+	}
+}
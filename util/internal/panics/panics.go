@@ -0,0 +1,70 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package panics converts panics to errors, so that a panicking goroutine can
+report its failure instead of crashing the program.
+*/
+package panics
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Error describes a function that panicked.
+type Error struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Run calls fn, returning an [*Error] if it panics or nil otherwise.
+func Run(fn func()) *Error {
+	var perr *Error
+	func() {
+		defer func() {
+			if v := recover(); v != nil {
+				perr = &Error{Value: v, Stack: debug.Stack()}
+			}
+		}()
+		fn()
+	}()
+	return perr
+}
+
+// Call calls fn and returns its error, or an [*Error] if it panics.
+func Call(fn func() error) error {
+	var err error
+	if perr := Run(func() { err = fn() }); perr != nil {
+		return perr
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package panics
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	if perr := Run(func() {}); perr != nil {
+		t.Errorf("Run() = %v, want nil", perr)
+	}
+	perr := Run(func() { panic("boom") })
+	if perr == nil || perr.Value != "boom" || len(perr.Stack) == 0 {
+		t.Errorf("Run() = %+v, want the panic value and stack", perr)
+	}
+}
+
+func TestCall(t *testing.T) {
+	errTest := errors.New("test")
+	if err := Call(func() error { return errTest }); !errors.Is(err, errTest) {
+		t.Errorf("Call() = %v, want %v", err, errTest)
+	}
+	var perr *Error
+	if err := Call(func() error { panic("boom") }); !errors.As(err, &perr) || perr.Value != "boom" {
+		t.Errorf("Call() = %v, want an *Error", err)
+	}
+}
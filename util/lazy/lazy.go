@@ -0,0 +1,139 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package lazy provides lazy initialisation of values that can fail.
+
+Unlike [sync.OnceValues], a failed initialisation is not memoized, so it is
+tried again by the next call, and a memoized value can be reset.
+*/
+package lazy
+
+import (
+	"context"
+	"sync"
+
+	"hypera.dev/lib/util/retry"
+)
+
+// Options configure a [Lazy].
+type Options struct {
+	// Retry makes Get retry failed initialisation using [retry.DoValue]
+	// with these options. If nil, initialisation is attempted once per call
+	// to Get.
+	Retry []retry.Option
+}
+
+// Lazy is a value that is initialised by the first call to [Lazy.Get].
+// Successful initialisation is memoized until [Lazy.Reset] is called.
+// A Lazy is safe for concurrent use.
+type Lazy[T any] struct {
+	init  func(ctx context.Context) (T, error)
+	retry []retry.Option
+
+	// sem is held while initialising, so that waiting callers can stop
+	// waiting when their context is done.
+	sem chan struct{}
+
+	mu    sync.Mutex
+	val   T
+	ok    bool
+	reset uint64
+}
+
+// New returns a Lazy that is initialised using init.
+func New[T any](init func(ctx context.Context) (T, error), opts *Options) *Lazy[T] {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Lazy[T]{
+		init:  init,
+		retry: opts.Retry,
+		sem:   make(chan struct{}, 1),
+	}
+}
+
+// Get returns the value, initialising it if needed. Only one call
+// initialises the value at a time, and concurrent callers wait for it. If
+// initialisation fails, the error is returned and the next call tries again.
+func (l *Lazy[T]) Get(ctx context.Context) (T, error) {
+	if v, ok := l.Peek(); ok {
+		return v, nil
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+		defer func() { <-l.sem }()
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+
+	l.mu.Lock()
+	if l.ok {
+		defer l.mu.Unlock()
+		return l.val, nil
+	}
+	reset := l.reset
+	l.mu.Unlock()
+
+	var v T
+	var err error
+	if l.retry != nil {
+		v, err = retry.DoValue(ctx, l.init, l.retry...)
+	} else {
+		v, err = l.init(ctx)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Don't memoize a value initialised before a call to Reset.
+	if l.reset == reset {
+		l.val, l.ok = v, true
+	}
+	return v, nil
+}
+
+// Peek returns the memoized value without initialising it. ok is false if
+// the value has not been initialised.
+func (l *Lazy[T]) Peek() (T, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.val, l.ok
+}
+
+// Reset invalidates the memoized value, so that the next call to Get
+// initialises it again. An initialisation in progress when Reset is called
+// is not memoized.
+func (l *Lazy[T]) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var zero T
+	l.val, l.ok = zero, false
+	l.reset++
+}
@@ -0,0 +1,150 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package lazy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hypera.dev/lib/util/retry"
+)
+
+var errTest = errors.New("test error")
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	l := New(func(context.Context) (int, error) {
+		return int(calls.Add(1)), nil
+	}, nil)
+
+	if _, ok := l.Peek(); ok {
+		t.Error("Peek() ok = true before Get")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := l.Get(ctx); v != 1 || err != nil {
+				t.Errorf("Get() = %d, %v, want 1, nil", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	l.Reset()
+	if v, err := l.Get(ctx); v != 2 || err != nil {
+		t.Errorf("Get() after Reset = %d, %v, want 2, nil", v, err)
+	}
+}
+
+func TestGetError(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	l := New(func(context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errTest
+		}
+		return "ok", nil
+	}, nil)
+
+	if _, err := l.Get(ctx); !errors.Is(err, errTest) {
+		t.Errorf("Get() error = %v, want %v", err, errTest)
+	}
+	if v, err := l.Get(ctx); v != "ok" || err != nil {
+		t.Errorf("Get() = %q, %v, want \"ok\", nil", v, err)
+	}
+}
+
+func TestGetRetry(t *testing.T) {
+	var calls int
+	l := New(func(context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errTest
+		}
+		return calls, nil
+	}, &Options{
+		Retry: []retry.Option{retry.WithBackoff(retry.N(5, time.Millisecond))},
+	})
+
+	if v, err := l.Get(context.Background()); v != 3 || err != nil {
+		t.Errorf("Get() = %d, %v, want 3, nil", v, err)
+	}
+}
+
+func TestGetContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	l := New(func(context.Context) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	}, nil)
+
+	go l.Get(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+}
+
+func TestResetDuringInit(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	l := New(func(context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return int(calls.Load()), nil
+	}, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Get(context.Background())
+	}()
+	<-started
+	l.Reset()
+	close(release)
+	<-done
+
+	if _, ok := l.Peek(); ok {
+		t.Error("Peek() ok = true, value initialised before Reset was memoized")
+	}
+}
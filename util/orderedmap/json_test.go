@@ -0,0 +1,104 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	m := New[string, any]()
+	m.Set("z", 1)
+	m.Set("a", []int{1, 2})
+	m.Set("m", map[string]int{"x": 1})
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"z":1,"a":[1,2],"m":{"x":1}}`; string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+
+	ints := New[int, bool]()
+	ints.Set(10, true)
+	ints.Set(-2, false)
+	b, _ = json.Marshal(ints)
+	if want := `{"10":true,"-2":false}`; string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+
+	if b, _ := json.Marshal(New[string, int]()); string(b) != "{}" {
+		t.Errorf("Marshal() of empty map = %s, want {}", b)
+	}
+
+	// Maps in struct fields are encoded when the struct is not addressable.
+	type config struct {
+		Env Map[string, string] `json:"env"`
+	}
+	var c config
+	c.Env.Set("B", "1")
+	c.Env.Set("A", "2")
+	b, _ = json.Marshal(c)
+	if want := `{"env":{"B":"1","A":"2"}}`; string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var m Map[string, json.RawMessage]
+	if err := json.Unmarshal([]byte(`{"b": 1, "a": {"nested": true}, "c": null}`), &m); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got := m.Keys(); !slices.Equal(got, []string{"b", "a", "c"}) {
+		t.Errorf("Keys() = %v, want [b a c]", got)
+	}
+	if v, _ := m.Get("a"); string(v) != `{"nested": true}` {
+		t.Errorf("Get(a) = %s", v)
+	}
+
+	var addrs Map[netip.Addr, uint8]
+	if err := json.Unmarshal([]byte(`{"10.0.0.1": 1, "::1": 2}`), &addrs); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if v, _ := addrs.Get(netip.MustParseAddr("::1")); v != 2 {
+		t.Errorf("Get(::1) = %d, want 2", v)
+	}
+
+	tests := []string{`[]`, `{"a": "x"}`, `{"a": 1`}
+	for _, data := range tests {
+		var m Map[string, int]
+		if err := json.Unmarshal([]byte(data), &m); err == nil {
+			t.Errorf("Unmarshal(%s) error = nil, want error", data)
+		}
+	}
+	var ints Map[uint8, int]
+	if err := json.Unmarshal([]byte(`{"300": 1}`), &ints); err == nil {
+		t.Error("Unmarshal() of out of range key error = nil, want error")
+	}
+}
@@ -0,0 +1,170 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package orderedmap provides a map that remembers the order keys were
+inserted in, and keeps that order when encoded as JSON.
+*/
+package orderedmap
+
+// Pair is a key and value in a [Map].
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+
+	prev, next *Pair[K, V]
+}
+
+// Next returns the next pair, or nil if p is the last pair.
+func (p *Pair[K, V]) Next() *Pair[K, V] {
+	return p.next
+}
+
+// Prev returns the previous pair, or nil if p is the first pair.
+func (p *Pair[K, V]) Prev() *Pair[K, V] {
+	return p.prev
+}
+
+// Map is a map that iterates in insertion order. Getting, setting and
+// deleting keys take constant time. The zero value is an empty map ready to
+// use. Like a built-in map, a Map is not safe for concurrent use.
+type Map[K comparable, V any] struct {
+	pairs       map[K]*Pair[K, V]
+	front, back *Pair[K, V]
+}
+
+// New returns an empty Map.
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{}
+}
+
+// Len returns the number of keys in the map.
+func (m *Map[K, V]) Len() int {
+	return len(m.pairs)
+}
+
+// Get returns the value of key, and reports whether it was found.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if p, ok := m.pairs[key]; ok {
+		return p.Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has reports whether the map contains key.
+func (m *Map[K, V]) Has(key K) bool {
+	_, ok := m.pairs[key]
+	return ok
+}
+
+// Set sets the value of key. A new key is added after the existing keys,
+// while an existing key keeps its position.
+func (m *Map[K, V]) Set(key K, value V) {
+	if p, ok := m.pairs[key]; ok {
+		p.Value = value
+		return
+	}
+	if m.pairs == nil {
+		m.pairs = make(map[K]*Pair[K, V])
+	}
+	p := &Pair[K, V]{Key: key, Value: value, prev: m.back}
+	if m.back != nil {
+		m.back.next = p
+	} else {
+		m.front = p
+	}
+	m.back = p
+	m.pairs[key] = p
+}
+
+// Delete deletes key, and reports whether it was found.
+func (m *Map[K, V]) Delete(key K) bool {
+	p, ok := m.pairs[key]
+	if !ok {
+		return false
+	}
+	delete(m.pairs, key)
+	if p.prev != nil {
+		p.prev.next = p.next
+	} else {
+		m.front = p.next
+	}
+	if p.next != nil {
+		p.next.prev = p.prev
+	} else {
+		m.back = p.prev
+	}
+	p.prev, p.next = nil, nil
+	return true
+}
+
+// Front returns the first pair, or nil if the map is empty.
+func (m *Map[K, V]) Front() *Pair[K, V] {
+	return m.front
+}
+
+// Back returns the last pair, or nil if the map is empty.
+func (m *Map[K, V]) Back() *Pair[K, V] {
+	return m.back
+}
+
+// Range calls fn for each key and value in order, until fn returns false.
+// fn may delete the current key.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	for p := m.front; p != nil; {
+		next := p.next
+		if !fn(p.Key, p.Value) {
+			return
+		}
+		p = next
+	}
+}
+
+// Keys returns the keys in order.
+func (m *Map[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.pairs))
+	for p := m.front; p != nil; p = p.next {
+		keys = append(keys, p.Key)
+	}
+	return keys
+}
+
+// Values returns the values in order.
+func (m *Map[K, V]) Values() []V {
+	values := make([]V, 0, len(m.pairs))
+	for p := m.front; p != nil; p = p.next {
+		values = append(values, p.Value)
+	}
+	return values
+}
+
+// Clone returns a copy of the map. The values are copied shallowly.
+func (m *Map[K, V]) Clone() *Map[K, V] {
+	c := New[K, V]()
+	for p := m.front; p != nil; p = p.next {
+		c.Set(p.Key, p.Value)
+	}
+	return c
+}
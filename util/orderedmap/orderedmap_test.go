@@ -0,0 +1,101 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package orderedmap
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	var m Map[string, int]
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Set("a", 4)
+
+	if got := m.Keys(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("Keys() = %v, want [c a b]", got)
+	}
+	if got := m.Values(); !slices.Equal(got, []int{1, 4, 3}) {
+		t.Errorf("Values() = %v, want [1 4 3]", got)
+	}
+	if v, ok := m.Get("a"); v != 4 || !ok {
+		t.Errorf("Get(a) = %d, %t, want 4, true", v, ok)
+	}
+	if _, ok := m.Get("z"); ok || m.Has("z") {
+		t.Error("Get(z) found a missing key")
+	}
+
+	for _, key := range []string{"a", "c", "b"} {
+		if !m.Delete(key) {
+			t.Errorf("Delete(%s) = false, want true", key)
+		}
+	}
+	if m.Delete("a") {
+		t.Error("Delete(a) = true for a deleted key")
+	}
+	if m.Len() != 0 || m.Front() != nil || m.Back() != nil {
+		t.Error("map not empty after deleting every key")
+	}
+
+	m.Set("d", 5)
+	if m.Front() != m.Back() || m.Front().Key != "d" {
+		t.Error("Front() and Back() must be the only key")
+	}
+}
+
+func TestMapIteration(t *testing.T) {
+	m := New[int, string]()
+	for i := 0; i < 5; i++ {
+		m.Set(i, "")
+	}
+
+	var keys []int
+	m.Range(func(k int, _ string) bool {
+		if k%2 == 1 {
+			m.Delete(k)
+		}
+		keys = append(keys, k)
+		return k < 3
+	})
+	if !slices.Equal(keys, []int{0, 1, 2, 3}) {
+		t.Errorf("Range() visited %v, want [0 1 2 3]", keys)
+	}
+
+	keys = nil
+	for p := m.Back(); p != nil; p = p.Prev() {
+		keys = append(keys, p.Key)
+	}
+	if !slices.Equal(keys, []int{4, 2, 0}) {
+		t.Errorf("reverse iteration = %v, want [4 2 0]", keys)
+	}
+
+	c := m.Clone()
+	c.Set(5, "")
+	if m.Len() != 3 || c.Len() != 4 {
+		t.Errorf("Clone() shares state: Len() = %d, %d", m.Len(), c.Len())
+	}
+}
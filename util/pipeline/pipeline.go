@@ -0,0 +1,225 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package pipeline connects stages that process values from channels
+concurrently, and shuts them down cleanly when one fails or the context is
+done.
+
+	parse := pipeline.Map(func(ctx context.Context, line string) (Record, error) { ... })
+	store := pipeline.Map(func(ctx context.Context, r Record) (int64, error) { ... })
+	err := pipeline.Run(ctx, pipeline.FromSlice(lines),
+		pipeline.Connect(parse, pipeline.Parallel(8, store)),
+		func(ctx context.Context, id int64) error { ... },
+	)
+*/
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"hypera.dev/lib/util/chans"
+)
+
+// Stage processes the values received from in, sending its results to the
+// first returned channel and its errors to the second. A stage must close
+// both channels once in is closed and it has finished, or once ctx is done.
+type Stage[I, O any] func(ctx context.Context, in <-chan I) (<-chan O, <-chan error)
+
+// Source produces the input of a pipeline. It must close the channel once
+// it has sent every value, or once ctx is done.
+type Source[T any] func(ctx context.Context) <-chan T
+
+// FromSlice returns a [Source] that sends the values.
+func FromSlice[T any](values []T) Source[T] {
+	return func(ctx context.Context) <-chan T {
+		out := make(chan T)
+		go func() {
+			defer close(out)
+			for _, v := range values {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// FromChan returns a [Source] that sends the values received from ch.
+func FromChan[T any](ch <-chan T) Source[T] {
+	return func(ctx context.Context) <-chan T {
+		return chans.OrDone(ctx, ch)
+	}
+}
+
+// Map returns a [Stage] that calls f for each value. If f returns an error,
+// it is sent to the error channel and no result is sent for the value.
+func Map[I, O any](f func(ctx context.Context, v I) (O, error)) Stage[I, O] {
+	return func(ctx context.Context, in <-chan I) (<-chan O, <-chan error) {
+		out := make(chan O)
+		errc := make(chan error)
+		go func() {
+			defer close(errc)
+			defer close(out)
+			for {
+				var v I
+				var ok bool
+				select {
+				case v, ok = <-in:
+					if !ok {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+
+				o, err := f(ctx, v)
+				if err != nil {
+					select {
+					case errc <- err:
+						continue
+					case <-ctx.Done():
+						return
+					}
+				}
+				select {
+				case out <- o:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out, errc
+	}
+}
+
+// Connect returns a [Stage] that sends the results of first to second.
+func Connect[A, B, C any](first Stage[A, B], second Stage[B, C]) Stage[A, C] {
+	return func(ctx context.Context, in <-chan A) (<-chan C, <-chan error) {
+		b, errs1 := first(ctx, in)
+		c, errs2 := second(ctx, b)
+		return c, merge(ctx, errs1, errs2)
+	}
+}
+
+// Parallel returns a [Stage] that runs n copies of s concurrently, sharing
+// the input between them. The order of the results is not preserved.
+func Parallel[I, O any](n int, s Stage[I, O]) Stage[I, O] {
+	n = max(n, 1)
+	return func(ctx context.Context, in <-chan I) (<-chan O, <-chan error) {
+		ins := chans.FanOut(ctx, in, n)
+		outs := make([]<-chan O, n)
+		errs := make([]<-chan error, n)
+		for i, in := range ins {
+			outs[i], errs[i] = s(ctx, in)
+		}
+		return merge(ctx, outs...), merge(ctx, errs...)
+	}
+}
+
+// merge is like [chans.Merge], but only closes the returned channel once
+// every input is closed, so that it stays open until the stages sending to
+// the inputs have stopped. Values received once ctx is done are dropped.
+func merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func() {
+			defer wg.Done()
+			for v := range in {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Run runs a pipeline, sending the values from src through s and calling
+// sink for each result. If a stage or sink returns an error, the pipeline
+// is shut down and the first error is returned. If ctx is done, the
+// pipeline is shut down and the context's error is returned. Run returns
+// once every stage has stopped.
+func Run[I, O any](ctx context.Context, src Source[I], s Stage[I, O], sink func(ctx context.Context, v O) error) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out, errc := s(runCtx, src(runCtx))
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	for out != nil || errc != nil {
+		select {
+		case v, ok := <-out:
+			if !ok {
+				out = nil
+				continue
+			}
+			if firstErr == nil {
+				if err := sink(runCtx, v); err != nil {
+					fail(err)
+				}
+			}
+		case err, ok := <-errc:
+			if !ok {
+				errc = nil
+				continue
+			}
+			fail(err)
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// Collect is like [Run], but returns the results of the pipeline.
+func Collect[I, O any](ctx context.Context, src Source[I], s Stage[I, O]) ([]O, error) {
+	var results []O
+	err := Run(ctx, src, s, func(_ context.Context, v O) error {
+		results = append(results, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,149 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+func double(_ context.Context, v int) (int, error) {
+	return v * 2, nil
+}
+
+func format(_ context.Context, v int) (string, error) {
+	return strconv.Itoa(v), nil
+}
+
+func TestCollect(t *testing.T) {
+	got, err := Collect(context.Background(), FromSlice([]int{1, 2, 3}), Connect(Map(double), Map(format)))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if want := []string{"2", "4", "6"}; !slices.Equal(got, want) {
+		t.Errorf("Collect() = %v, want %v", got, want)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	values := make([]int, 100)
+	for i := range values {
+		values[i] = i
+	}
+	got, err := Collect(context.Background(), FromSlice(values), Parallel(4, Map(double)))
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	slices.Sort(got)
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("Collect()[%d] = %d, want %d", i, v, i*2)
+		}
+	}
+}
+
+func TestRunStageError(t *testing.T) {
+	var calls atomic.Int32
+	fail := Map(func(_ context.Context, v int) (int, error) {
+		calls.Add(1)
+		if v == 3 {
+			return 0, errTest
+		}
+		return v, nil
+	})
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 1000; i++ {
+			ch <- i
+		}
+	}()
+
+	err := Run(context.Background(), FromChan(ch), Connect(fail, Map(double)), func(context.Context, int) error {
+		return nil
+	})
+	if !errors.Is(err, errTest) {
+		t.Errorf("Run() error = %v, want %v", err, errTest)
+	}
+	if calls.Load() == 1000 {
+		t.Error("pipeline not shut down after error")
+	}
+	for range ch {
+		// Let the producer finish
+	}
+}
+
+func TestRunSinkError(t *testing.T) {
+	err := Run(context.Background(), FromSlice([]int{1, 2, 3}), Map(double), func(_ context.Context, v int) error {
+		if v == 4 {
+			return errTest
+		}
+		return nil
+	})
+	if !errors.Is(err, errTest) {
+		t.Errorf("Run() error = %v, want %v", err, errTest)
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Run(ctx, FromSlice([]int{1, 2, 3}), Map(double), func(context.Context, int) error {
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRunWaitsForStages(t *testing.T) {
+	var running atomic.Int32
+	slow := Map(func(_ context.Context, v int) (int, error) {
+		running.Add(1)
+		defer running.Add(-1)
+		time.Sleep(20 * time.Millisecond)
+		return v, nil
+	})
+	fail := Map(func(context.Context, int) (int, error) {
+		return 0, errTest
+	})
+	err := Run(context.Background(), FromSlice([]int{1, 2, 3, 4}),
+		Connect(Parallel(2, slow), fail),
+		func(context.Context, int) error { return nil },
+	)
+	if !errors.Is(err, errTest) {
+		t.Errorf("Run() error = %v, want %v", err, errTest)
+	}
+	if n := running.Load(); n != 0 {
+		t.Errorf("%d stage functions still running after Run returned", n)
+	}
+}
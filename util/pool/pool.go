@@ -0,0 +1,156 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package pool provides a typed object pool with statistics, for reusing
+allocations such as buffers.
+*/
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Options configure a [Pool].
+type Options[T any] struct {
+	// New returns a new object. It is required.
+	New func() T
+
+	// Reset is called with each object returned to the pool, to clear its
+	// state before it is reused.
+	Reset func(v T)
+
+	// Size returns the size of an object, such as the capacity of a
+	// buffer. It is required for MaxSize.
+	Size func(v T) int
+
+	// MaxSize is the largest size of an object kept in the pool. Larger
+	// objects are dropped when returned, so that an occasional large object
+	// does not stay in memory. If zero, objects of any size are kept.
+	MaxSize int
+
+	// MaxCount is the maximum number of idle objects kept in the pool. If
+	// set, objects are kept in a fixed-size free list, which unlike a
+	// [sync.Pool] is not cleared by the garbage collector. If zero, a
+	// sync.Pool is used.
+	MaxCount int
+}
+
+// Stats are the statistics of a [Pool].
+type Stats struct {
+	// Hits is the number of objects that were reused from the pool.
+	Hits uint64
+
+	// Misses is the number of objects that had to be created.
+	Misses uint64
+
+	// Drops is the number of objects that were not returned to the pool,
+	// because they were too large or the pool was full.
+	Drops uint64
+}
+
+// Pool is a pool of objects of type T. A Pool is safe for concurrent use.
+type Pool[T any] struct {
+	newFn   func() T
+	reset   func(v T)
+	size    func(v T) int
+	maxSize int
+
+	pool sync.Pool
+	free chan T
+
+	gets   atomic.Uint64
+	misses atomic.Uint64
+	drops  atomic.Uint64
+}
+
+// New returns a Pool configured with opts. opts.New is required.
+func New[T any](opts *Options[T]) *Pool[T] {
+	p := &Pool[T]{
+		newFn:   opts.New,
+		reset:   opts.Reset,
+		size:    opts.Size,
+		maxSize: opts.MaxSize,
+	}
+	if opts.MaxCount > 0 {
+		p.free = make(chan T, opts.MaxCount)
+	} else {
+		p.pool.New = func() any {
+			return p.create()
+		}
+	}
+	return p
+}
+
+// Get returns an object from the pool, or a new object if the pool is
+// empty.
+func (p *Pool[T]) Get() T {
+	p.gets.Add(1)
+	if p.free == nil {
+		return p.pool.Get().(T)
+	}
+	select {
+	case v := <-p.free:
+		return v
+	default:
+		return p.create()
+	}
+}
+
+// Put resets v and returns it to the pool, unless it is too large or the
+// pool is full.
+func (p *Pool[T]) Put(v T) {
+	if p.maxSize > 0 && p.size != nil && p.size(v) > p.maxSize {
+		p.drops.Add(1)
+		return
+	}
+	if p.reset != nil {
+		p.reset(v)
+	}
+	if p.free == nil {
+		p.pool.Put(v)
+		return
+	}
+	select {
+	case p.free <- v:
+	default:
+		p.drops.Add(1)
+	}
+}
+
+// Stats returns the statistics of the pool.
+func (p *Pool[T]) Stats() Stats {
+	misses := p.misses.Load()
+	return Stats{
+		Hits:   p.gets.Load() - misses,
+		Misses: misses,
+		Drops:  p.drops.Load(),
+	}
+}
+
+func (p *Pool[T]) create() T {
+	p.misses.Add(1)
+	return p.newFn()
+}
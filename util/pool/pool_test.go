@@ -0,0 +1,89 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package pool
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func newBufferPool(maxCount int) *Pool[*bytes.Buffer] {
+	return New(&Options[*bytes.Buffer]{
+		New:      func() *bytes.Buffer { return bytes.NewBuffer(make([]byte, 0, 16)) },
+		Reset:    (*bytes.Buffer).Reset,
+		Size:     (*bytes.Buffer).Cap,
+		MaxSize:  64,
+		MaxCount: maxCount,
+	})
+}
+
+func TestPool(t *testing.T) {
+	p := newBufferPool(2)
+
+	a, b, c := p.Get(), p.Get(), p.Get()
+	a.WriteString("hello")
+	p.Put(a)
+	p.Put(b)
+	p.Put(c) // pool is full
+
+	if got := p.Get(); got != a || got.Len() != 0 {
+		t.Errorf("Get() = %p with %d bytes, want reset %p", got, got.Len(), a)
+	}
+
+	large := p.Get()
+	large.Grow(100)
+	p.Put(large) // too large
+
+	want := Stats{Hits: 2, Misses: 3, Drops: 2}
+	if got := p.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPoolSync(t *testing.T) {
+	p := newBufferPool(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b := p.Get()
+				if b.Len() != 0 {
+					t.Errorf("Get() returned a buffer that was not reset")
+				}
+				b.WriteString("data")
+				p.Put(b)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := p.Stats()
+	if stats.Hits+stats.Misses != 800 {
+		t.Errorf("Stats() = %+v, want 800 gets", stats)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package ptr provides helpers for working with pointers, such as the optional
+fields of API request and response structs.
+
+	req := &api.UpdateRequest{
+		Name:  ptr.To("example"),
+		Limit: ptr.To(10),
+	}
+	limit := ptr.Deref(resp.Limit, 100)
+*/
+package ptr
+
+// To returns a pointer to a copy of v.
+func To[T any](v T) *T {
+	return &v
+}
+
+// Deref returns the value p points to, or def if p is nil.
+func Deref[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// Value returns the value p points to, or the zero value of T if p is nil.
+func Value[T any](p *T) T {
+	var zero T
+	return Deref(p, zero)
+}
+
+// Equal reports whether p1 and p2 are both nil, or both point to equal
+// values.
+func Equal[T comparable](p1, p2 *T) bool {
+	if p1 == nil || p2 == nil {
+		return p1 == p2
+	}
+	return *p1 == *p2
+}
+
+// NonZero returns a pointer to a copy of v, or nil if v is the zero value of
+// T.
+func NonZero[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
+
+// Clone returns a pointer to a copy of the value p points to, or nil if p is
+// nil. The copy is shallow.
+func Clone[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// Coalesce returns the first pointer in ps that is not nil, or nil if they
+// are all nil.
+func Coalesce[T any](ps ...*T) *T {
+	for _, p := range ps {
+		if p != nil {
+			return p
+		}
+	}
+	return nil
+}
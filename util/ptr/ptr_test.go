@@ -0,0 +1,107 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ptr
+
+import (
+	"testing"
+)
+
+func TestTo(t *testing.T) {
+	v := 1
+	p := To(v)
+	if *p != 1 {
+		t.Errorf("*To(1) = %d, want 1", *p)
+	}
+	v = 2
+	if *p != 1 {
+		t.Errorf("*To(v) = %d after changing v, want 1", *p)
+	}
+}
+
+func TestDeref(t *testing.T) {
+	if got := Deref(To("a"), "b"); got != "a" {
+		t.Errorf("Deref(To(%q), %q) = %q, want %q", "a", "b", got, "a")
+	}
+	if got := Deref(nil, "b"); got != "b" {
+		t.Errorf("Deref(nil, %q) = %q, want %q", "b", got, "b")
+	}
+	if got := Value[int](nil); got != 0 {
+		t.Errorf("Value(nil) = %d, want 0", got)
+	}
+	if got := Value(To(3)); got != 3 {
+		t.Errorf("Value(To(3)) = %d, want 3", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name   string
+		p1, p2 *int
+		want   bool
+	}{
+		{name: "both nil", want: true},
+		{name: "first nil", p2: To(1), want: false},
+		{name: "second nil", p1: To(1), want: false},
+		{name: "equal", p1: To(1), p2: To(1), want: true},
+		{name: "not equal", p1: To(1), p2: To(2), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.p1, tt.p2); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNonZero(t *testing.T) {
+	if p := NonZero(""); p != nil {
+		t.Errorf("NonZero(%q) = %v, want nil", "", p)
+	}
+	if p := NonZero("a"); p == nil || *p != "a" {
+		t.Errorf("NonZero(%q) = %v, want pointer to %q", "a", p, "a")
+	}
+}
+
+func TestClone(t *testing.T) {
+	if p := Clone[int](nil); p != nil {
+		t.Errorf("Clone(nil) = %v, want nil", p)
+	}
+	p := To(1)
+	c := Clone(p)
+	if c == p || *c != 1 {
+		t.Errorf("Clone(%p) = %p (%d), want new pointer to 1", p, c, *c)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	a, b := To(1), To(2)
+	if got := Coalesce(nil, a, b); got != a {
+		t.Errorf("Coalesce(nil, a, b) = %p, want a (%p)", got, a)
+	}
+	if got := Coalesce[int](nil, nil); got != nil {
+		t.Errorf("Coalesce(nil, nil) = %p, want nil", got)
+	}
+}
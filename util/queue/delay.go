@@ -0,0 +1,139 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// delayed is an item of a [Delay] queue.
+type delayed[T any] struct {
+	v  T
+	at time.Time
+	// seq keeps items that are ready at the same time in the order they
+	// were pushed.
+	seq uint64
+}
+
+// Delay is a queue that holds each item until it is ready. Items are popped
+// in the order they become ready. A Delay is safe for concurrent use.
+type Delay[T any] struct {
+	mu    sync.Mutex
+	items *Priority[delayed[T]]
+	seq   uint64
+
+	// pushed is closed and replaced when an item is pushed.
+	pushed chan struct{}
+}
+
+// NewDelay returns an empty Delay queue.
+func NewDelay[T any]() *Delay[T] {
+	return &Delay[T]{
+		items: NewPriority(func(a, b delayed[T]) bool {
+			if a.at.Equal(b.at) {
+				return a.seq < b.seq
+			}
+			return a.at.Before(b.at)
+		}),
+	}
+}
+
+// Push adds v to the queue, to be ready at the given time.
+func (q *Delay[T]) Push(v T, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	q.items.Push(delayed[T]{v: v, at: at, seq: q.seq})
+	notify(&q.pushed)
+}
+
+// PushAfter adds v to the queue, to be ready after d.
+func (q *Delay[T]) PushAfter(v T, d time.Duration) {
+	q.Push(v, time.Now().Add(d))
+}
+
+// Pop removes and returns the first item to be ready, waiting until it is
+// ready or until ctx is done.
+func (q *Delay[T]) Pop(ctx context.Context) (T, error) {
+	for {
+		q.mu.Lock()
+		next, ok := q.items.Peek()
+		var wait time.Duration
+		if ok {
+			wait = time.Until(next.at)
+			if wait <= 0 {
+				q.items.Pop()
+				q.mu.Unlock()
+				return next.v, nil
+			}
+		}
+		pushed := waitChan(&q.pushed)
+		q.mu.Unlock()
+
+		var ready <-chan time.Time
+		var timer *time.Timer
+		if ok {
+			timer = time.NewTimer(wait)
+			ready = timer.C
+		}
+		select {
+		case <-ready:
+		case <-pushed:
+			// An earlier item may have been pushed.
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			var zero T
+			return zero, ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// TryPop removes and returns the first item if it is ready, and reports
+// whether there was one.
+func (q *Delay[T]) TryPop() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	next, ok := q.items.Peek()
+	if !ok || next.at.After(time.Now()) {
+		var zero T
+		return zero, false
+	}
+	q.items.Pop()
+	return next.v, true
+}
+
+// Len returns the number of items in the queue, ready or not.
+func (q *Delay[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len()
+}
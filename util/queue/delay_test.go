@@ -0,0 +1,84 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	ctx := context.Background()
+	q := NewDelay[string]()
+	now := time.Now()
+	q.Push("b", now.Add(20*time.Millisecond))
+	q.Push("a", now.Add(10*time.Millisecond))
+	q.Push("c", now.Add(20*time.Millisecond))
+	if n := q.Len(); n != 3 {
+		t.Errorf("Len() = %d, want 3", n)
+	}
+	if _, ok := q.TryPop(); ok {
+		t.Error("TryPop() = true before any item is ready")
+	}
+
+	for _, want := range []string{"a", "b", "c"} {
+		v, err := q.Pop(ctx)
+		if v != want || err != nil {
+			t.Fatalf("Pop() = %q, %v, want %q, nil", v, err, want)
+		}
+	}
+	if elapsed := time.Since(now); elapsed < 20*time.Millisecond {
+		t.Errorf("items popped after %v, before they were ready", elapsed)
+	}
+}
+
+func TestDelayEarlierPush(t *testing.T) {
+	q := NewDelay[int]()
+	q.PushAfter(2, time.Hour)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.PushAfter(1, 0)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if v, err := q.Pop(ctx); v != 1 || err != nil {
+		t.Errorf("Pop() = %d, %v, want 1, nil", v, err)
+	}
+	if v, ok := q.TryPop(); ok {
+		t.Errorf("TryPop() = %d, true, want nothing ready", v)
+	}
+}
+
+func TestDelayContext(t *testing.T) {
+	q := NewDelay[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Pop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pop() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package queue
+
+// Priority is a priority queue backed by a binary heap. The item that
+// compares lowest is popped first. A Priority is not safe for concurrent
+// use.
+type Priority[T any] struct {
+	less  func(a, b T) bool
+	items []T
+}
+
+// NewPriority returns a Priority that orders items using less, which
+// reports whether a must be popped before b.
+func NewPriority[T any](less func(a, b T) bool) *Priority[T] {
+	return &Priority[T]{less: less}
+}
+
+// Len returns the number of items in the queue.
+func (q *Priority[T]) Len() int {
+	return len(q.items)
+}
+
+// Push adds v to the queue.
+func (q *Priority[T]) Push(v T) {
+	q.items = append(q.items, v)
+	q.up(len(q.items) - 1)
+}
+
+// Peek returns the first item without removing it, and reports whether
+// there was one.
+func (q *Priority[T]) Peek() (T, bool) {
+	if len(q.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.items[0], true
+}
+
+// Pop removes and returns the first item, and reports whether there was
+// one.
+func (q *Priority[T]) Pop() (T, bool) {
+	if len(q.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	v := q.items[0]
+	last := len(q.items) - 1
+	q.items[0] = q.items[last]
+	var zero T
+	q.items[last] = zero
+	q.items = q.items[:last]
+	if last > 0 {
+		q.down(0)
+	}
+	return v, true
+}
+
+func (q *Priority[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.less(q.items[i], q.items[parent]) {
+			return
+		}
+		q.items[i], q.items[parent] = q.items[parent], q.items[i]
+		i = parent
+	}
+}
+
+func (q *Priority[T]) down(i int) {
+	n := len(q.items)
+	for {
+		first := i
+		if l := 2*i + 1; l < n && q.less(q.items[l], q.items[first]) {
+			first = l
+		}
+		if r := 2*i + 2; r < n && q.less(q.items[r], q.items[first]) {
+			first = r
+		}
+		if first == i {
+			return
+		}
+		q.items[i], q.items[first] = q.items[first], q.items[i]
+		i = first
+	}
+}
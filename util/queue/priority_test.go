@@ -0,0 +1,56 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package queue
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestPriority(t *testing.T) {
+	q := NewPriority(func(a, b int) bool { return a < b })
+	if _, ok := q.Pop(); ok {
+		t.Error("Pop() = true on empty queue")
+	}
+
+	values := rand.Perm(100)
+	for _, v := range values {
+		q.Push(v)
+	}
+	if v, ok := q.Peek(); v != 0 || !ok {
+		t.Errorf("Peek() = %d, %t, want 0, true", v, ok)
+	}
+
+	var got []int
+	for q.Len() > 0 {
+		v, _ := q.Pop()
+		got = append(got, v)
+	}
+	slices.Sort(values)
+	if !slices.Equal(got, values) {
+		t.Errorf("Pop() order = %v, want sorted", got)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package queue provides generic queues: a bounded blocking queue, which can
+be used to hand work from producers to consumers, a priority queue, and a
+delay queue that holds items until they are ready.
+*/
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when putting to a closed queue, or getting from
+	// a closed queue that has been drained.
+	ErrClosed = errors.New("queue: queue is closed")
+
+	// ErrFull is returned by [Queue.TryPut] when the queue is full.
+	ErrFull = errors.New("queue: queue is full")
+)
+
+// Queue is a bounded first-in, first-out queue. Put waits while the queue
+// is full, and Get waits while it is empty. A Queue is safe for concurrent
+// use.
+type Queue[T any] struct {
+	mu     sync.Mutex
+	items  []T
+	head   int
+	len    int
+	closed bool
+
+	// notEmpty and notFull are closed and replaced when an item is put or
+	// got, or when the queue is closed.
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+// New returns a Queue that holds up to capacity items. A capacity of zero
+// or less is treated as one.
+func New[T any](capacity int) *Queue[T] {
+	return &Queue[T]{items: make([]T, max(capacity, 1))}
+}
+
+// Put adds v to the back of the queue, waiting while the queue is full or
+// until ctx is done. If the queue is closed, [ErrClosed] is returned.
+func (q *Queue[T]) Put(ctx context.Context, v T) error {
+	q.mu.Lock()
+	for !q.closed && q.len == len(q.items) {
+		wait := waitChan(&q.notFull)
+		q.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		q.mu.Lock()
+	}
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	q.push(v)
+	return nil
+}
+
+// TryPut adds v to the back of the queue without waiting. If the queue is
+// full, [ErrFull] is returned, and if it is closed, [ErrClosed] is returned.
+func (q *Queue[T]) TryPut(v T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	if q.len == len(q.items) {
+		return ErrFull
+	}
+	q.push(v)
+	return nil
+}
+
+// Get removes and returns the item at the front of the queue, waiting while
+// the queue is empty or until ctx is done. Once the queue is closed, Get
+// returns the remaining items, then [ErrClosed].
+func (q *Queue[T]) Get(ctx context.Context) (T, error) {
+	q.mu.Lock()
+	for !q.closed && q.len == 0 {
+		wait := waitChan(&q.notEmpty)
+		q.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		q.mu.Lock()
+	}
+	defer q.mu.Unlock()
+	if q.len == 0 {
+		var zero T
+		return zero, ErrClosed
+	}
+	return q.pop(), nil
+}
+
+// TryGet removes and returns the item at the front of the queue without
+// waiting, and reports whether there was one.
+func (q *Queue[T]) TryGet() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.len == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.pop(), true
+}
+
+// Close closes the queue. Waiting and later calls to Put return
+// [ErrClosed], while Get keeps returning the remaining items. Calling Close
+// again has no effect.
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	notify(&q.notEmpty)
+	notify(&q.notFull)
+}
+
+// Closed reports whether the queue has been closed.
+func (q *Queue[T]) Closed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// Len returns the number of items in the queue.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.len
+}
+
+// Cap returns the maximum number of items in the queue.
+func (q *Queue[T]) Cap() int {
+	return len(q.items)
+}
+
+// push adds v to the back of the queue. q.mu must be held.
+func (q *Queue[T]) push(v T) {
+	q.items[(q.head+q.len)%len(q.items)] = v
+	q.len++
+	notify(&q.notEmpty)
+}
+
+// pop removes the item at the front of the queue. q.mu must be held.
+func (q *Queue[T]) pop() T {
+	v := q.items[q.head]
+	var zero T
+	q.items[q.head] = zero
+	q.head = (q.head + 1) % len(q.items)
+	q.len--
+	notify(&q.notFull)
+	return v
+}
+
+// waitChan returns the channel to wait on for a notification, creating it
+// if needed.
+func waitChan(ch *chan struct{}) chan struct{} {
+	if *ch == nil {
+		*ch = make(chan struct{})
+	}
+	return *ch
+}
+
+// notify wakes the goroutines waiting on a notification.
+func notify(ch *chan struct{}) {
+	if *ch != nil {
+		close(*ch)
+		*ch = nil
+	}
+}
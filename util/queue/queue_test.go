@@ -0,0 +1,130 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	q := New[int](2)
+	if q.Cap() != 2 {
+		t.Errorf("Cap() = %d, want 2", q.Cap())
+	}
+
+	if err := q.Put(ctx, 1); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := q.TryPut(2); err != nil {
+		t.Fatalf("TryPut() error = %v", err)
+	}
+	if err := q.TryPut(3); !errors.Is(err, ErrFull) {
+		t.Errorf("TryPut() error = %v, want %v", err, ErrFull)
+	}
+	if n := q.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+
+	if v, err := q.Get(ctx); v != 1 || err != nil {
+		t.Errorf("Get() = %d, %v, want 1, nil", v, err)
+	}
+	_ = q.TryPut(3)
+	if v, ok := q.TryGet(); v != 2 || !ok {
+		t.Errorf("TryGet() = %d, %t, want 2, true", v, ok)
+	}
+	if v, ok := q.TryGet(); v != 3 || !ok {
+		t.Errorf("TryGet() = %d, %t, want 3, true", v, ok)
+	}
+	if _, ok := q.TryGet(); ok {
+		t.Error("TryGet() = true on empty queue")
+	}
+}
+
+func TestQueueWait(t *testing.T) {
+	q := New[int](1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	_ = q.TryPut(1)
+	if err := q.Put(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Put() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	const n = 100
+	q = New[int](3)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if err := q.Put(context.Background(), i); err != nil {
+				t.Errorf("Put() error = %v", err)
+			}
+		}
+	}()
+	for i := 0; i < n; i++ {
+		v, err := q.Get(context.Background())
+		if v != i || err != nil {
+			t.Fatalf("Get() = %d, %v, want %d, nil", v, err, i)
+		}
+	}
+	wg.Wait()
+}
+
+func TestQueueClose(t *testing.T) {
+	ctx := context.Background()
+	q := New[int](1)
+	_ = q.TryPut(1)
+
+	done := make(chan error)
+	go func() {
+		done <- q.Put(ctx, 2)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("waiting Put() error = %v, want %v", err, ErrClosed)
+	}
+	if !q.Closed() {
+		t.Error("Closed() = false after Close")
+	}
+	if err := q.TryPut(3); !errors.Is(err, ErrClosed) {
+		t.Errorf("TryPut() error = %v, want %v", err, ErrClosed)
+	}
+
+	if v, err := q.Get(ctx); v != 1 || err != nil {
+		t.Errorf("Get() = %d, %v, want 1, nil", v, err)
+	}
+	if _, err := q.Get(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("Get() error = %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,269 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket rate limiter. Tokens are added to the bucket at
+// the limit, up to the burst, and each event takes a token. A Bucket is
+// safe for concurrent use.
+type Bucket struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	limit  Limit
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+var _ Limiter = (*Bucket)(nil)
+
+// NewBucket returns a Bucket that allows events at limit, with bursts of up
+// to burst events. The bucket starts full.
+func NewBucket(limit Limit, burst int) *Bucket {
+	return &Bucket{
+		now:    time.Now,
+		limit:  limit,
+		burst:  burst,
+		tokens: float64(burst),
+	}
+}
+
+// Limit returns the limit of the bucket.
+func (b *Bucket) Limit() Limit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit
+}
+
+// Burst returns the burst of the bucket.
+func (b *Bucket) Burst() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.burst
+}
+
+// SetLimit changes the limit of the bucket. Tokens accumulated at the
+// previous limit are kept.
+func (b *Bucket) SetLimit(limit Limit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.now())
+	b.limit = limit
+}
+
+// SetBurst changes the burst of the bucket. If the bucket holds more tokens
+// than the new burst, the excess is discarded.
+func (b *Bucket) SetBurst(burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.now())
+	b.burst = burst
+	b.tokens = min(b.tokens, float64(burst))
+}
+
+// Tokens returns the number of tokens in the bucket. It is negative when
+// tokens have been reserved ahead of time.
+func (b *Bucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.now())
+	return b.tokens
+}
+
+// Allow reports whether an event may happen now, taking a token if so.
+func (b *Bucket) Allow() bool {
+	return b.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, taking n tokens if so.
+func (b *Bucket) AllowN(n int) bool {
+	return b.reserve(b.now(), n, 0).ok
+}
+
+// Wait waits until an event may happen, or until ctx is done.
+func (b *Bucket) Wait(ctx context.Context) error {
+	return b.WaitN(ctx, 1)
+}
+
+// WaitN waits until n events may happen, or until ctx is done. If n exceeds
+// the burst, [ErrExceedsBurst] is returned, and if the wait would not end
+// before the context's deadline, [ErrWouldExceedDeadline] is returned,
+// without waiting.
+func (b *Bucket) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := b.now()
+	maxWait := maxDuration
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = deadline.Sub(now)
+	}
+
+	if n > b.Burst() && b.Limit() != Inf {
+		return ErrExceedsBurst
+	}
+	r := b.reserve(now, n, maxWait)
+	if !r.ok {
+		return ErrWouldExceedDeadline
+	}
+	delay := r.DelayFrom(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// Acquire implements [Limiter] by waiting for a token. The returned release
+// function does nothing.
+func (b *Bucket) Acquire(ctx context.Context) (func(), error) {
+	if err := b.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return noop, nil
+}
+
+// TryAcquire implements [Limiter] by taking a token if one is available.
+// The returned release function does nothing.
+func (b *Bucket) TryAcquire() (func(), bool) {
+	if !b.Allow() {
+		return nil, false
+	}
+	return noop, true
+}
+
+// Reserve reserves a token for an event that may happen after
+// [Reservation.Delay].
+func (b *Bucket) Reserve() *Reservation {
+	return b.ReserveN(1)
+}
+
+// ReserveN reserves n tokens for n events that may happen after
+// [Reservation.Delay]. If n exceeds the burst, the reservation is not OK.
+func (b *Bucket) ReserveN(n int) *Reservation {
+	return b.reserve(b.now(), n, maxDuration)
+}
+
+// reserve takes n tokens at now, if they are available within maxWait.
+func (b *Bucket) reserve(now time.Time, n int, maxWait time.Duration) *Reservation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit == Inf {
+		return &Reservation{b: b, ok: true, n: 0, at: now}
+	}
+	if n > b.burst {
+		return &Reservation{b: b}
+	}
+
+	b.advance(now)
+	tokens := b.tokens - float64(n)
+	var wait time.Duration
+	if tokens < 0 {
+		wait = b.limit.durationFromTokens(-tokens)
+	}
+	if wait > maxWait {
+		return &Reservation{b: b}
+	}
+	b.tokens = tokens
+	return &Reservation{b: b, ok: true, n: n, at: now.Add(wait)}
+}
+
+// advance adds the tokens accumulated since the last update. b.mu must be
+// held.
+func (b *Bucket) advance(now time.Time) {
+	if b.last.IsZero() {
+		b.last = now
+		return
+	}
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.last = now
+	b.tokens = min(b.tokens+b.limit.tokensFromDuration(elapsed), float64(b.burst))
+}
+
+// Reservation is a reservation of tokens from a [Bucket].
+type Reservation struct {
+	b  *Bucket
+	ok bool
+	n  int
+	at time.Time
+
+	cancelled bool
+}
+
+// OK reports whether the tokens were reserved. A reservation is not OK if
+// more tokens were requested than the burst of the bucket.
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay returns how long to wait before the reserved events may happen.
+func (r *Reservation) Delay() time.Duration {
+	return r.DelayFrom(r.b.now())
+}
+
+// DelayFrom returns how long to wait from now before the reserved events
+// may happen. If the reservation is not OK, the maximum duration is
+// returned.
+func (r *Reservation) DelayFrom(now time.Time) time.Duration {
+	if !r.ok {
+		return maxDuration
+	}
+	return max(r.at.Sub(now), 0)
+}
+
+// Cancel returns the reserved tokens to the bucket, if the reserved events
+// have not happened yet, so that other events may use them.
+func (r *Reservation) Cancel() {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !r.ok || r.cancelled || r.n == 0 {
+		return
+	}
+	now := b.now()
+	if !r.at.After(now) {
+		return
+	}
+	r.cancelled = true
+	b.advance(now)
+	b.tokens = min(b.tokens+float64(r.n), float64(b.burst))
+}
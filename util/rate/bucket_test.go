@@ -0,0 +1,192 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestBucket(limit Limit, burst int) (*Bucket, *fakeClock) {
+	c := &fakeClock{t: time.Unix(1700000000, 0)}
+	b := NewBucket(limit, burst)
+	b.now = c.now
+	return b, c
+}
+
+func TestEvery(t *testing.T) {
+	if l := Every(100 * time.Millisecond); l != 10 {
+		t.Errorf("Every(100ms) = %v, want 10", l)
+	}
+	if l := Every(0); l != Inf {
+		t.Errorf("Every(0) = %v, want Inf", l)
+	}
+}
+
+func TestBucketAllow(t *testing.T) {
+	b, c := newTestBucket(10, 3)
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("Allow() #%d = false, want true", i)
+		}
+	}
+	if b.Allow() {
+		t.Fatal("Allow() = true with empty bucket")
+	}
+
+	c.advance(100 * time.Millisecond)
+	if !b.Allow() {
+		t.Error("Allow() = false after a token was added")
+	}
+	if b.AllowN(4) {
+		t.Error("AllowN(4) = true, exceeds burst")
+	}
+
+	c.advance(time.Hour)
+	if tokens := b.Tokens(); tokens != 3 {
+		t.Errorf("Tokens() = %v, want 3", tokens)
+	}
+}
+
+func TestBucketInf(t *testing.T) {
+	b, _ := newTestBucket(Inf, 0)
+	for i := 0; i < 100; i++ {
+		if !b.Allow() {
+			t.Fatal("Allow() = false with Inf limit")
+		}
+	}
+}
+
+func TestBucketReserve(t *testing.T) {
+	b, c := newTestBucket(10, 1)
+	if r := b.Reserve(); !r.OK() || r.Delay() != 0 {
+		t.Errorf("Reserve() = %t, %v, want true, 0", r.OK(), r.Delay())
+	}
+	r := b.Reserve()
+	if !r.OK() || r.Delay() != 100*time.Millisecond {
+		t.Errorf("Reserve() = %t, %v, want true, 100ms", r.OK(), r.Delay())
+	}
+	if r := b.ReserveN(2); r.OK() {
+		t.Error("ReserveN(2) OK, exceeds burst")
+	}
+
+	r.Cancel()
+	c.advance(100 * time.Millisecond)
+	if tokens := b.Tokens(); tokens != 1 {
+		t.Errorf("Tokens() after Cancel = %v, want 1", tokens)
+	}
+}
+
+func TestBucketSetLimit(t *testing.T) {
+	b, c := newTestBucket(1, 10)
+	b.AllowN(10)
+	b.SetLimit(100)
+	c.advance(50 * time.Millisecond)
+	if tokens := b.Tokens(); tokens != 5 {
+		t.Errorf("Tokens() = %v, want 5", tokens)
+	}
+
+	b.SetBurst(2)
+	if tokens := b.Tokens(); tokens != 2 {
+		t.Errorf("Tokens() after SetBurst = %v, want 2", tokens)
+	}
+	if b.Limit() != 100 || b.Burst() != 2 {
+		t.Errorf("Limit(), Burst() = %v, %d, want 100, 2", b.Limit(), b.Burst())
+	}
+}
+
+func TestBucketWait(t *testing.T) {
+	b := NewBucket(Every(10*time.Millisecond), 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("3 waits took %v, want at least 20ms", elapsed)
+	}
+
+	if err := b.WaitN(ctx, 2); !errors.Is(err, ErrExceedsBurst) {
+		t.Errorf("WaitN(2) error = %v, want %v", err, ErrExceedsBurst)
+	}
+
+	b.SetLimit(Every(time.Hour))
+	b.Allow()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err := b.Wait(ctx)
+	if !errors.Is(err, ErrWouldExceedDeadline) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrWouldExceedDeadline)
+	}
+}
+
+func TestBucketWaitCancel(t *testing.T) {
+	b := NewBucket(Every(time.Hour), 1)
+	b.Allow()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := b.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want %v", err, context.Canceled)
+	}
+	if tokens := b.Tokens(); tokens < 0 {
+		t.Errorf("Tokens() = %v, cancelled wait was not returned", tokens)
+	}
+}
+
+func TestBucketLimiter(t *testing.T) {
+	var l Limiter = NewBucket(Inf, 0)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	release()
+	release, ok := l.TryAcquire()
+	if !ok {
+		t.Fatal("TryAcquire() = false, want true")
+	}
+	release()
+}
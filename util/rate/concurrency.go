@@ -0,0 +1,261 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default values used by [NewConcurrency].
+const (
+	DefaultInitialLimit = 20
+	DefaultMinLimit     = 1
+	DefaultMaxLimit     = 1000
+)
+
+// Algorithm adjusts the limit of a [Concurrency] limiter from the latency
+// of completed operations. An algorithm is only called by one goroutine at
+// a time.
+type Algorithm interface {
+	// Update returns the new limit, given the current limit, the latency of
+	// an operation that completed and the number of operations that were
+	// in flight when it completed, including itself.
+	Update(limit float64, latency time.Duration, inflight int) float64
+}
+
+// AIMD is an additive increase, multiplicative decrease [Algorithm]. The
+// limit grows by one while operations complete within the timeout, and
+// shrinks by the backoff ratio when one does not.
+type AIMD struct {
+	// Timeout is the latency above which an operation is considered to
+	// have failed because of overload.
+	Timeout time.Duration
+
+	// BackoffRatio is the ratio the limit is multiplied by when an
+	// operation exceeds the timeout. Defaults to 0.9.
+	BackoffRatio float64
+}
+
+// Update implements [Algorithm].
+func (a *AIMD) Update(limit float64, latency time.Duration, inflight int) float64 {
+	if latency > a.Timeout {
+		ratio := a.BackoffRatio
+		if ratio <= 0 || ratio >= 1 {
+			ratio = 0.9
+		}
+		return limit * ratio
+	}
+	// Only grow while the limit is being used, so that the limit does not
+	// grow without bound while the load is low.
+	if float64(inflight)*2 >= limit {
+		return limit + 1
+	}
+	return limit
+}
+
+// Gradient is an [Algorithm] that adjusts the limit by the ratio of the
+// long-term average latency to the latest latency, so that the limit shrinks
+// when latency rises above its usual level and grows when it does not.
+type Gradient struct {
+	// Smoothing is the weight given to each new limit. Defaults to 0.2.
+	Smoothing float64
+
+	// LongWindow is the number of operations that the long-term average
+	// latency is averaged over. Defaults to 600.
+	LongWindow int
+
+	longLatency float64
+}
+
+// Update implements [Algorithm].
+func (g *Gradient) Update(limit float64, latency time.Duration, inflight int) float64 {
+	window := g.LongWindow
+	if window <= 0 {
+		window = 600
+	}
+	smoothing := g.Smoothing
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = 0.2
+	}
+
+	sample := float64(latency)
+	if g.longLatency == 0 {
+		g.longLatency = sample
+	} else {
+		g.longLatency += (sample - g.longLatency) / float64(window)
+	}
+	if float64(inflight)*2 < limit || sample <= 0 {
+		return limit
+	}
+
+	gradient := max(0.5, min(1, g.longLatency/sample))
+	queue := math.Sqrt(limit)
+	next := limit*gradient + queue
+	return limit*(1-smoothing) + next*smoothing
+}
+
+// ConcurrencyOptions configure a [Concurrency] limiter.
+type ConcurrencyOptions struct {
+	// Algorithm adjusts the limit. Defaults to [Gradient].
+	Algorithm Algorithm
+
+	// InitialLimit is the limit before it is first adjusted. Defaults to
+	// [DefaultInitialLimit].
+	InitialLimit int
+
+	// MinLimit is the lowest the limit is adjusted to. Defaults to
+	// [DefaultMinLimit].
+	MinLimit int
+
+	// MaxLimit is the highest the limit is adjusted to. Defaults to
+	// [DefaultMaxLimit].
+	MaxLimit int
+}
+
+// Concurrency is an adaptive concurrency limiter, which limits how many
+// operations run at the same time, and adjusts the limit from the latency
+// of the operations. A Concurrency limiter is safe for concurrent use.
+type Concurrency struct {
+	alg      Algorithm
+	minLimit float64
+	maxLimit float64
+	now      func() time.Time
+
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+
+	// changed is closed and replaced when an operation completes.
+	changed chan struct{}
+}
+
+var _ Limiter = (*Concurrency)(nil)
+
+// NewConcurrency returns a Concurrency limiter configured with opts. If opts
+// is nil, the default options are used.
+func NewConcurrency(opts *ConcurrencyOptions) *Concurrency {
+	if opts == nil {
+		opts = &ConcurrencyOptions{}
+	}
+	alg := opts.Algorithm
+	if alg == nil {
+		alg = &Gradient{}
+	}
+	minLimit := opts.MinLimit
+	if minLimit <= 0 {
+		minLimit = DefaultMinLimit
+	}
+	maxLimit := opts.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxLimit
+	}
+	maxLimit = max(maxLimit, minLimit)
+	limit := opts.InitialLimit
+	if limit <= 0 {
+		limit = DefaultInitialLimit
+	}
+	return &Concurrency{
+		alg:      alg,
+		minLimit: float64(minLimit),
+		maxLimit: float64(maxLimit),
+		now:      time.Now,
+		limit:    float64(max(min(limit, maxLimit), minLimit)),
+	}
+}
+
+// Limit returns the current limit.
+func (c *Concurrency) Limit() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int(c.limit)
+}
+
+// InFlight returns the number of operations in flight.
+func (c *Concurrency) InFlight() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inflight
+}
+
+// Acquire waits until fewer operations are in flight than the limit, or
+// until ctx is done. release must be called once the operation is done, and
+// the time until then is used to adjust the limit.
+func (c *Concurrency) Acquire(ctx context.Context) (func(), error) {
+	c.mu.Lock()
+	for c.inflight >= int(c.limit) {
+		if c.changed == nil {
+			c.changed = make(chan struct{})
+		}
+		changed := c.changed
+		c.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		c.mu.Lock()
+	}
+	c.inflight++
+	c.mu.Unlock()
+	return c.release(), nil
+}
+
+// TryAcquire reports whether fewer operations are in flight than the limit.
+// If so, release must be called once the operation is done.
+func (c *Concurrency) TryAcquire() (func(), bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inflight >= int(c.limit) {
+		return nil, false
+	}
+	c.inflight++
+	return c.release(), true
+}
+
+// release returns a function that releases an operation started now.
+func (c *Concurrency) release() func() {
+	start := c.now()
+	var released atomic.Bool
+	return func() {
+		if released.Swap(true) {
+			return
+		}
+		latency := c.now().Sub(start)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		limit := c.alg.Update(c.limit, latency, c.inflight)
+		c.limit = max(min(limit, c.maxLimit), c.minLimit)
+		c.inflight--
+		if c.changed != nil {
+			close(c.changed)
+			c.changed = nil
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAIMD(t *testing.T) {
+	a := &AIMD{Timeout: time.Second, BackoffRatio: 0.5}
+	tests := []struct {
+		name     string
+		latency  time.Duration
+		inflight int
+		want     float64
+	}{
+		{name: "increase", latency: time.Millisecond, inflight: 10, want: 11},
+		{name: "idle", latency: time.Millisecond, inflight: 1, want: 10},
+		{name: "decrease", latency: 2 * time.Second, inflight: 10, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Update(10, tt.latency, tt.inflight); got != tt.want {
+				t.Errorf("Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradient(t *testing.T) {
+	g := &Gradient{Smoothing: 1}
+	limit := 100.0
+	for i := 0; i < 10; i++ {
+		limit = g.Update(limit, 10*time.Millisecond, 100)
+	}
+	if limit <= 100 {
+		t.Errorf("limit = %v with steady latency, want growth", limit)
+	}
+
+	before := limit
+	limit = g.Update(limit, time.Second, int(limit))
+	if limit >= before {
+		t.Errorf("limit = %v after latency spike, want below %v", limit, before)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	c := NewConcurrency(&ConcurrencyOptions{
+		Algorithm:    &AIMD{Timeout: time.Hour},
+		InitialLimit: 2,
+		MaxLimit:     3,
+	})
+
+	r1, ok1 := c.TryAcquire()
+	r2, ok2 := c.TryAcquire()
+	if !ok1 || !ok2 {
+		t.Fatal("TryAcquire() = false under the limit")
+	}
+	if _, ok := c.TryAcquire(); ok {
+		t.Fatal("TryAcquire() = true at the limit")
+	}
+	if n := c.InFlight(); n != 2 {
+		t.Errorf("InFlight() = %d, want 2", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := c.Acquire(context.Background())
+		if err != nil {
+			t.Errorf("Acquire() error = %v", err)
+			return
+		}
+		release()
+		close(acquired)
+	}()
+	r1()
+	r1()
+	<-acquired
+	r2()
+
+	if n := c.InFlight(); n != 0 {
+		t.Errorf("InFlight() = %d, want 0", n)
+	}
+	if l := c.Limit(); l != 3 {
+		t.Errorf("Limit() = %d, want 3", l)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	var l Limiter = NewConcurrency(&ConcurrencyOptions{InitialLimit: 4, MaxLimit: 4, MinLimit: 4})
+	var mu sync.Mutex
+	var inflight, peak int
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Acquire() error = %v", err)
+				return
+			}
+			defer release()
+			mu.Lock()
+			inflight++
+			peak = max(peak, inflight)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			inflight--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if peak > 4 {
+		t.Errorf("peak concurrency = %d, want at most 4", peak)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMaxKeys is the default value of [KeyedOptions.MaxKeys].
+const DefaultMaxKeys = 10000
+
+// KeyedOptions configure a [Keyed] limiter.
+type KeyedOptions struct {
+	// MaxKeys is the maximum number of keys to keep limiters for. When
+	// exceeded, the limiter of the least recently used key is evicted.
+	// Defaults to [DefaultMaxKeys].
+	MaxKeys int
+
+	// IdleTimeout evicts the limiters of keys that have not been used for
+	// longer. If zero, limiters are only evicted because of MaxKeys.
+	IdleTimeout time.Duration
+}
+
+// keyedEntry is the limiter of a key.
+type keyedEntry[K comparable] struct {
+	key      K
+	limiter  Limiter
+	lastUsed time.Time
+}
+
+// Keyed maintains an independent limiter for each key, such as a client IP
+// address or tenant. Limiters are created on first use, and evicted when
+// there are too many keys or they have been idle too long. An evicted key
+// starts over with a new limiter, so a key should not be evicted while its
+// limit still matters. A Keyed limiter is safe for concurrent use.
+type Keyed[K comparable] struct {
+	newLimiter  func(key K) Limiter
+	maxKeys     int
+	idleTimeout time.Duration
+	now         func() time.Time
+
+	mu sync.Mutex
+	// lru holds *keyedEntry, most recently used first.
+	lru  *list.List
+	keys map[K]*list.Element
+}
+
+// NewKeyed returns a Keyed limiter that creates the limiter of each key
+// using newLimiter.
+func NewKeyed[K comparable](newLimiter func(key K) Limiter, opts *KeyedOptions) *Keyed[K] {
+	if opts == nil {
+		opts = &KeyedOptions{}
+	}
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+	return &Keyed[K]{
+		newLimiter:  newLimiter,
+		maxKeys:     maxKeys,
+		idleTimeout: opts.IdleTimeout,
+		now:         time.Now,
+		lru:         list.New(),
+		keys:        make(map[K]*list.Element),
+	}
+}
+
+// Get returns the limiter of key, creating it if needed.
+func (k *Keyed[K]) Get(key K) Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	k.evictIdle(now)
+
+	if el, ok := k.keys[key]; ok {
+		e := el.Value.(*keyedEntry[K])
+		e.lastUsed = now
+		k.lru.MoveToFront(el)
+		return e.limiter
+	}
+
+	e := &keyedEntry[K]{key: key, limiter: k.newLimiter(key), lastUsed: now}
+	k.keys[key] = k.lru.PushFront(e)
+	for k.lru.Len() > k.maxKeys {
+		k.remove(k.lru.Back())
+	}
+	return e.limiter
+}
+
+// Acquire waits until the limiter of key allows an operation, or until ctx
+// is done. See [Limiter.Acquire].
+func (k *Keyed[K]) Acquire(ctx context.Context, key K) (func(), error) {
+	return k.Get(key).Acquire(ctx)
+}
+
+// TryAcquire reports whether the limiter of key allows an operation without
+// waiting. See [Limiter.TryAcquire].
+func (k *Keyed[K]) TryAcquire(key K) (func(), bool) {
+	return k.Get(key).TryAcquire()
+}
+
+// Remove removes the limiter of key, and reports whether it was found.
+func (k *Keyed[K]) Remove(key K) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	el, ok := k.keys[key]
+	if ok {
+		k.remove(el)
+	}
+	return ok
+}
+
+// Len returns the number of keys with a limiter.
+func (k *Keyed[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.evictIdle(k.now())
+	return k.lru.Len()
+}
+
+// evictIdle evicts the limiters that have been idle longer than the idle
+// timeout. k.mu must be held.
+func (k *Keyed[K]) evictIdle(now time.Time) {
+	if k.idleTimeout <= 0 {
+		return
+	}
+	for el := k.lru.Back(); el != nil; el = k.lru.Back() {
+		if now.Sub(el.Value.(*keyedEntry[K]).lastUsed) <= k.idleTimeout {
+			return
+		}
+		k.remove(el)
+	}
+}
+
+// remove removes an entry. k.mu must be held.
+func (k *Keyed[K]) remove(el *list.Element) {
+	k.lru.Remove(el)
+	delete(k.keys, el.Value.(*keyedEntry[K]).key)
+}
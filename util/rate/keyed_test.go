@@ -0,0 +1,98 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestKeyed(opts *KeyedOptions) (*Keyed[string], *fakeClock, map[string]int) {
+	c := &fakeClock{t: time.Unix(1700000000, 0)}
+	created := make(map[string]int)
+	k := NewKeyed(func(key string) Limiter {
+		created[key]++
+		return NewBucket(Every(time.Hour), 1)
+	}, opts)
+	k.now = c.now
+	return k, c, created
+}
+
+func TestKeyed(t *testing.T) {
+	k, _, created := newTestKeyed(nil)
+
+	if _, ok := k.TryAcquire("a"); !ok {
+		t.Error("TryAcquire(a) = false, want true")
+	}
+	if _, ok := k.TryAcquire("a"); ok {
+		t.Error("TryAcquire(a) = true, limit of a exceeded")
+	}
+	if _, ok := k.TryAcquire("b"); !ok {
+		t.Error("TryAcquire(b) = false, keys must be independent")
+	}
+	if created["a"] != 1 || created["b"] != 1 {
+		t.Errorf("limiters created = %v, want one per key", created)
+	}
+
+	if !k.Remove("a") || k.Remove("a") {
+		t.Error("Remove(a) did not remove a once")
+	}
+	if _, ok := k.TryAcquire("a"); !ok {
+		t.Error("TryAcquire(a) = false after Remove")
+	}
+}
+
+func TestKeyedMaxKeys(t *testing.T) {
+	k, _, created := newTestKeyed(&KeyedOptions{MaxKeys: 2})
+	k.Get("a")
+	k.Get("b")
+	k.Get("a")
+	k.Get("c") // evicts b, the least recently used
+	if n := k.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+
+	k.Get("a")
+	k.Get("b")
+	if created["a"] != 1 || created["b"] != 2 {
+		t.Errorf("limiters created = %v, want a once and b twice", created)
+	}
+}
+
+func TestKeyedIdleTimeout(t *testing.T) {
+	k, c, created := newTestKeyed(&KeyedOptions{IdleTimeout: time.Minute})
+	k.Get("a")
+	c.advance(30 * time.Second)
+	k.Get("b")
+	c.advance(45 * time.Second)
+	if n := k.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+	k.Get("b")
+	k.Get("a")
+	if created["a"] != 2 || created["b"] != 1 {
+		t.Errorf("limiters created = %v, want a twice and b once", created)
+	}
+}
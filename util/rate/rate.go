@@ -0,0 +1,107 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package rate provides rate and concurrency limiters, which share the
+[Limiter] interface so that code can be written against either.
+
+	l := rate.NewBucket(rate.Every(100*time.Millisecond), 5)
+	if err := l.Wait(ctx); err != nil {
+		return err
+	}
+*/
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrExceedsBurst is returned when more tokens are requested at once
+	// than the burst of a limiter allows, so the request can never succeed.
+	ErrExceedsBurst = errors.New("rate: request exceeds burst")
+
+	// ErrWouldExceedDeadline is returned when waiting for a limiter would
+	// not finish before the context's deadline. It wraps
+	// [context.DeadlineExceeded].
+	ErrWouldExceedDeadline = fmt.Errorf("rate: wait would exceed context deadline: %w", context.DeadlineExceeded)
+)
+
+// Limiter limits operations. Rate limiters limit how often operations start,
+// and concurrency limiters limit how many run at the same time.
+type Limiter interface {
+	// Acquire waits until an operation may proceed, or until ctx is done.
+	// If it returns nil, release must be called once the operation is done.
+	Acquire(ctx context.Context) (release func(), err error)
+
+	// TryAcquire reports whether an operation may proceed without waiting.
+	// If ok is true, release must be called once the operation is done.
+	TryAcquire() (release func(), ok bool)
+}
+
+// Limit is a rate of events per second.
+type Limit float64
+
+// Inf is an infinite rate, which allows every event.
+const Inf = Limit(math.MaxFloat64)
+
+// maxDuration is the longest possible duration, used as an infinite wait.
+const maxDuration = time.Duration(math.MaxInt64)
+
+// Every returns the limit that allows one event every interval. If interval
+// is zero or less, Every returns [Inf].
+func Every(interval time.Duration) Limit {
+	if interval <= 0 {
+		return Inf
+	}
+	return 1 / Limit(interval.Seconds())
+}
+
+// durationFromTokens returns the time it takes to accumulate tokens at the
+// limit.
+func (l Limit) durationFromTokens(tokens float64) time.Duration {
+	if l <= 0 {
+		return maxDuration
+	}
+	seconds := tokens / float64(l)
+	if seconds >= float64(math.MaxInt64)/float64(time.Second) {
+		return maxDuration
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// tokensFromDuration returns the number of tokens accumulated at the limit
+// over d.
+func (l Limit) tokensFromDuration(d time.Duration) float64 {
+	if l <= 0 {
+		return 0
+	}
+	return d.Seconds() * float64(l)
+}
+
+func noop() {}
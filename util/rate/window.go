@@ -0,0 +1,176 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SlidingWindow is a sliding window counter rate limiter, which allows up
+// to a number of events in any window of time. Unlike a fixed window, a
+// burst at the end of one window is counted against the start of the next.
+// The count is estimated from the counts of the current and previous fixed
+// windows. A SlidingWindow is safe for concurrent use.
+type SlidingWindow struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	start time.Time
+	cur   int
+	prev  int
+}
+
+var _ Limiter = (*SlidingWindow)(nil)
+
+// NewSlidingWindow returns a SlidingWindow that allows limit events in any
+// window of time. NewSlidingWindow panics if window is not positive.
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	if window <= 0 {
+		panic("rate: non-positive window for NewSlidingWindow")
+	}
+	return &SlidingWindow{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Allow reports whether an event may happen now, counting it if so.
+func (w *SlidingWindow) Allow() bool {
+	return w.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, counting them if so.
+func (w *SlidingWindow) AllowN(n int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ok, _ := w.take(w.now(), n)
+	return ok
+}
+
+// Wait waits until an event may happen, or until ctx is done.
+func (w *SlidingWindow) Wait(ctx context.Context) error {
+	return w.WaitN(ctx, 1)
+}
+
+// WaitN waits until n events may happen, or until ctx is done. If n exceeds
+// the limit, [ErrExceedsBurst] is returned.
+func (w *SlidingWindow) WaitN(ctx context.Context, n int) error {
+	if n > w.limit {
+		return ErrExceedsBurst
+	}
+	for {
+		w.mu.Lock()
+		ok, delay := w.take(w.now(), n)
+		w.mu.Unlock()
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Acquire implements [Limiter] by waiting for an event to be allowed. The
+// returned release function does nothing.
+func (w *SlidingWindow) Acquire(ctx context.Context) (func(), error) {
+	if err := w.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return noop, nil
+}
+
+// TryAcquire implements [Limiter] by counting an event if it is allowed.
+// The returned release function does nothing.
+func (w *SlidingWindow) TryAcquire() (func(), bool) {
+	if !w.Allow() {
+		return nil, false
+	}
+	return noop, true
+}
+
+// Count returns the estimated number of events in the window ending now.
+func (w *SlidingWindow) Count() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+	w.advance(now)
+	return w.estimate(now)
+}
+
+// take counts n events at now if they are allowed. Otherwise, it returns
+// how long to wait before trying again. w.mu must be held.
+func (w *SlidingWindow) take(now time.Time, n int) (bool, time.Duration) {
+	w.advance(now)
+	if w.estimate(now)+float64(n) <= float64(w.limit) {
+		w.cur += n
+		return true, 0
+	}
+
+	end := w.start.Add(w.window)
+	if w.cur+n > w.limit || w.prev == 0 {
+		// Not allowed until the current window ends.
+		return false, max(end.Sub(now), time.Millisecond)
+	}
+	// Wait until enough of the previous window has slid out.
+	weight := float64(w.limit-w.cur-n) / float64(w.prev)
+	at := end.Add(-time.Duration(weight * float64(w.window)))
+	return false, max(at.Sub(now), time.Millisecond)
+}
+
+// estimate returns the estimated number of events in the window ending at
+// now. w.mu must be held.
+func (w *SlidingWindow) estimate(now time.Time) float64 {
+	elapsed := float64(now.Sub(w.start)) / float64(w.window)
+	return float64(w.prev)*(1-elapsed) + float64(w.cur)
+}
+
+// advance moves the fixed windows forward to now. w.mu must be held.
+func (w *SlidingWindow) advance(now time.Time) {
+	if w.start.IsZero() {
+		w.start = now
+		return
+	}
+	windows := now.Sub(w.start) / w.window
+	switch {
+	case windows <= 0:
+		return
+	case windows == 1:
+		w.prev, w.cur = w.cur, 0
+	default:
+		w.prev, w.cur = 0, 0
+	}
+	w.start = w.start.Add(windows * w.window)
+}
@@ -0,0 +1,117 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package rate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestWindow(limit int, window time.Duration) (*SlidingWindow, *fakeClock) {
+	c := &fakeClock{t: time.Unix(1700000000, 0)}
+	w := NewSlidingWindow(limit, window)
+	w.now = c.now
+	return w, c
+}
+
+func TestSlidingWindow(t *testing.T) {
+	w, c := newTestWindow(10, time.Second)
+	if !w.AllowN(10) {
+		t.Fatal("AllowN(10) = false, want true")
+	}
+	if w.Allow() {
+		t.Fatal("Allow() = true over the limit")
+	}
+
+	// Half of the previous window is still counted.
+	c.advance(1500 * time.Millisecond)
+	if got := w.Count(); got != 5 {
+		t.Errorf("Count() = %v, want 5", got)
+	}
+	if !w.AllowN(5) {
+		t.Error("AllowN(5) = false, want true")
+	}
+	if w.Allow() {
+		t.Error("Allow() = true over the limit")
+	}
+
+	c.advance(2 * time.Second)
+	if got := w.Count(); got != 0 {
+		t.Errorf("Count() = %v, want 0", got)
+	}
+}
+
+func TestSlidingWindowDelay(t *testing.T) {
+	w, c := newTestWindow(10, time.Second)
+	w.AllowN(10)
+	c.advance(time.Second)
+
+	// 10 events in the previous window, so 1 event is allowed once a tenth
+	// of it has slid out.
+	ok, delay := w.take(c.now(), 1)
+	if ok || delay != 100*time.Millisecond {
+		t.Errorf("take() = %t, %v, want false, 100ms", ok, delay)
+	}
+	c.advance(100 * time.Millisecond)
+	if !w.Allow() {
+		t.Error("Allow() = false after delay")
+	}
+}
+
+func TestSlidingWindowWait(t *testing.T) {
+	w := NewSlidingWindow(2, 20*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := w.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("3 waits took %v, want at least 10ms", elapsed)
+	}
+	if err := w.WaitN(ctx, 3); !errors.Is(err, ErrExceedsBurst) {
+		t.Errorf("WaitN(3) error = %v, want %v", err, ErrExceedsBurst)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	w.AllowN(2)
+	if err := w.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestSlidingWindowNonPositive(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewSlidingWindow(1, 0) did not panic")
+		}
+	}()
+	NewSlidingWindow(1, 0)
+}
@@ -0,0 +1,423 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Stop is returned by [Backoff.Next] to indicate that no more retries should
+// be made.
+const Stop time.Duration = -1
+
+// Default values used by [NewExponentialBackoff].
+const (
+	DefaultInitialInterval = 500 * time.Millisecond
+	DefaultMaxInterval     = time.Minute
+	DefaultMultiplier      = 1.5
+	DefaultMaxElapsedTime  = 15 * time.Minute
+)
+
+// Backoff decides how long to wait between attempts.
+type Backoff interface {
+	// Next returns the duration to wait before the next attempt, or [Stop]
+	// if no more attempts should be made.
+	Next() time.Duration
+}
+
+// ResettableBackoff is a [Backoff] that can be restarted from its initial
+// state. The retry functions reset backoffs that implement it before the
+// first attempt, so that a backoff can be reused for multiple operations.
+type ResettableBackoff interface {
+	Backoff
+
+	// Reset restarts the backoff from its initial state.
+	Reset()
+}
+
+// ConstantBackoff waits the same interval between every attempt.
+//
+// ConstantBackoff is not safe for concurrent use.
+type ConstantBackoff struct {
+	// Interval is the interval between attempts.
+	Interval time.Duration
+
+	// MaxRetries is the number of retries after which Next returns [Stop].
+	// If zero, the number of retries is not limited.
+	MaxRetries int
+
+	retries int
+}
+
+// NewConstantBackoff returns a [ConstantBackoff] with the given interval.
+func NewConstantBackoff(interval time.Duration) *ConstantBackoff {
+	return &ConstantBackoff{Interval: interval}
+}
+
+// Next implements [Backoff].
+func (b *ConstantBackoff) Next() time.Duration {
+	if b.MaxRetries > 0 && b.retries >= b.MaxRetries {
+		return Stop
+	}
+	b.retries++
+	return b.Interval
+}
+
+// Reset restarts the retry count.
+func (b *ConstantBackoff) Reset() {
+	b.retries = 0
+}
+
+// ExponentialBackoff increases the interval between attempts by a constant
+// multiplier, up to a maximum interval.
+//
+// ExponentialBackoff is not safe for concurrent use.
+type ExponentialBackoff struct {
+	// InitialInterval is the interval before the first retry.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the interval between attempts. If zero, the interval
+	// is not capped.
+	MaxInterval time.Duration
+
+	// Multiplier is the factor the interval is multiplied by after each
+	// attempt.
+	Multiplier float64
+
+	// MaxElapsedTime is the time after the first call to Next, after which
+	// Next returns [Stop]. If zero, the backoff never stops.
+	MaxElapsedTime time.Duration
+
+	// MaxRetries is the number of retries after which Next returns [Stop].
+	// If zero, the number of retries is not limited.
+	MaxRetries int
+
+	// JitterPercent randomly adjusts each interval by up to the given
+	// percentage in either direction, using [PercentageJitter]. The interval
+	// is increased from the unjittered value. If zero, intervals are not
+	// jittered.
+	JitterPercent int
+
+	// ImmediateFirstRetry makes the first retry happen without waiting, with
+	// InitialInterval used before the second retry. Many transient failures
+	// resolve immediately.
+	ImmediateFirstRetry bool
+
+	// Rand is the source of randomness for jitter. If nil, the global
+	// generator from math/rand/v2 is used. Setting it makes the intervals
+	// reproducible, which is useful for tests and simulations.
+	Rand *rand.Rand
+
+	// Clock is used to measure the elapsed time. If nil, the clock set with
+	// [WithClock] is used while retrying, and [SystemClock] otherwise.
+	Clock Clock
+
+	clock   Clock
+	current time.Duration
+	start   time.Time
+	retries int
+}
+
+// NewExponentialBackoff returns an [ExponentialBackoff] with the default
+// values.
+func NewExponentialBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		InitialInterval: DefaultInitialInterval,
+		MaxInterval:     DefaultMaxInterval,
+		Multiplier:      DefaultMultiplier,
+		MaxElapsedTime:  DefaultMaxElapsedTime,
+	}
+}
+
+// Next implements [Backoff].
+func (b *ExponentialBackoff) Next() time.Duration {
+	if b.MaxRetries > 0 && b.retries >= b.MaxRetries {
+		return Stop
+	}
+	b.retries++
+	clock := b.Clock
+	if clock == nil {
+		clock = clockOrSystem(b.clock)
+	}
+	now := clock.Now()
+	if b.start.IsZero() {
+		b.start = now
+		if b.ImmediateFirstRetry {
+			return 0
+		}
+	} else if b.MaxElapsedTime > 0 && now.Sub(b.start) >= b.MaxElapsedTime {
+		return Stop
+	}
+
+	if b.current == 0 {
+		b.current = b.InitialInterval
+	} else {
+		b.current = time.Duration(float64(b.current) * b.Multiplier)
+	}
+	if b.MaxInterval > 0 && (b.current > b.MaxInterval || b.current < 0) {
+		b.current = b.MaxInterval
+	}
+	return percentageJitter{min(b.JitterPercent, 100), b.Rand}.Apply(b.current)
+}
+
+// Reset restarts the backoff from the initial interval.
+func (b *ExponentialBackoff) Reset() {
+	b.current = 0
+	b.start = time.Time{}
+	b.retries = 0
+}
+
+func (b *ExponentialBackoff) useClock(c Clock) {
+	b.clock = c
+}
+
+// maxRetriesBackoff stops after a maximum number of retries.
+type maxRetriesBackoff struct {
+	b       Backoff
+	max     int
+	retries int
+}
+
+// LimitRetries returns a [Backoff] that stops after n retries, meaning that
+// an operation is attempted at most n+1 times. Resetting it resets the retry
+// count, and b if it is a [ResettableBackoff].
+func LimitRetries(b Backoff, n int) ResettableBackoff {
+	return &maxRetriesBackoff{b: b, max: n}
+}
+
+// Next implements [Backoff].
+func (b *maxRetriesBackoff) Next() time.Duration {
+	if b.retries >= b.max {
+		return Stop
+	}
+	b.retries++
+	return b.b.Next()
+}
+
+// Reset implements [ResettableBackoff].
+func (b *maxRetriesBackoff) Reset() {
+	b.retries = 0
+	resetBackoff(b.b)
+}
+
+func (b *maxRetriesBackoff) useClock(c Clock) {
+	useClock(b.b, c)
+}
+
+// N returns a [Backoff] that retries n times, waiting interval between
+// attempts, so that an operation is attempted at most n+1 times. It is a
+// shorthand for LimitRetries(NewConstantBackoff(interval), n).
+func N(n int, interval time.Duration) ResettableBackoff {
+	return LimitRetries(NewConstantBackoff(interval), n)
+}
+
+// foreverBackoff never stops.
+type foreverBackoff struct {
+	b    Backoff
+	last time.Duration
+}
+
+// Forever returns a [Backoff] that never returns [Stop], so that retries are
+// only stopped by the context. Once b returns Stop, the last non-zero
+// interval it returned is repeated. If it returned none, its initial interval
+// is used, or [DefaultInitialInterval] if it has none.
+func Forever(b Backoff) ResettableBackoff {
+	return &foreverBackoff{b: b}
+}
+
+// Next implements [Backoff].
+func (b *foreverBackoff) Next() time.Duration {
+	next := b.b.Next()
+	if next == Stop {
+		if b.last > 0 {
+			return b.last
+		}
+		// Never retry without waiting, which would spin
+		return initialInterval(b.b)
+	}
+	if next > 0 {
+		b.last = next
+	}
+	return next
+}
+
+// initialInterval returns the initial interval of b, or
+// [DefaultInitialInterval] if it is not known or not positive.
+func initialInterval(b Backoff) time.Duration {
+	var d time.Duration
+	switch b := b.(type) {
+	case *ConstantBackoff:
+		d = b.Interval
+	case *ExponentialBackoff:
+		d = b.InitialInterval
+	case *FibonacciBackoff:
+		d = b.InitialInterval
+	case *LinearBackoff:
+		d = b.InitialInterval
+	case *DecorrelatedJitterBackoff:
+		d = b.BaseInterval
+	}
+	if d <= 0 {
+		return DefaultInitialInterval
+	}
+	return d
+}
+
+// Reset implements [ResettableBackoff].
+func (b *foreverBackoff) Reset() {
+	b.last = 0
+	resetBackoff(b.b)
+}
+
+func (b *foreverBackoff) useClock(c Clock) {
+	useClock(b.b, c)
+}
+
+func (b *foreverBackoff) clone() (Backoff, bool) {
+	inner, ok := cloneBackoff(b.b)
+	if !ok {
+		return nil, false
+	}
+	c := *b
+	c.b = inner
+	return &c, true
+}
+
+// maxElapsedTimeBackoff stops after a maximum elapsed time.
+type maxElapsedTimeBackoff struct {
+	b     Backoff
+	max   time.Duration
+	clock Clock
+	start time.Time
+}
+
+// WithMaxElapsedTime returns a [Backoff] that returns [Stop] once d has
+// elapsed since the first call to Next. Resetting it restarts the elapsed
+// time, and resets b if it is a [ResettableBackoff].
+func WithMaxElapsedTime(b Backoff, d time.Duration) ResettableBackoff {
+	return &maxElapsedTimeBackoff{b: b, max: d}
+}
+
+// Next implements [Backoff].
+func (b *maxElapsedTimeBackoff) Next() time.Duration {
+	now := clockOrSystem(b.clock).Now()
+	if b.start.IsZero() {
+		b.start = now
+	} else if now.Sub(b.start) >= b.max {
+		return Stop
+	}
+	return b.b.Next()
+}
+
+// Reset implements [ResettableBackoff].
+func (b *maxElapsedTimeBackoff) Reset() {
+	b.start = time.Time{}
+	resetBackoff(b.b)
+}
+
+// capBackoff caps the intervals of a backoff.
+type capBackoff struct {
+	b   Backoff
+	max time.Duration
+}
+
+// WithCap returns a [Backoff] that caps the intervals returned by b at
+// maxInterval. [Stop] is returned unchanged.
+func WithCap(b Backoff, maxInterval time.Duration) ResettableBackoff {
+	return &capBackoff{b: b, max: maxInterval}
+}
+
+// Next implements [Backoff].
+func (b *capBackoff) Next() time.Duration {
+	next := b.b.Next()
+	if next == Stop {
+		return Stop
+	}
+	return min(next, b.max)
+}
+
+// Reset implements [ResettableBackoff].
+func (b *capBackoff) Reset() {
+	resetBackoff(b.b)
+}
+
+// resetBackoff resets b if it is a [ResettableBackoff].
+func resetBackoff(b Backoff) {
+	if r, ok := b.(ResettableBackoff); ok {
+		r.Reset()
+	}
+}
+
+func (b *maxElapsedTimeBackoff) useClock(c Clock) {
+	b.clock = c
+	useClock(b.b, c)
+}
+
+func (b *capBackoff) useClock(c Clock) {
+	useClock(b.b, c)
+}
+
+// Clone implements [CloneableBackoff].
+func (b *ConstantBackoff) Clone() Backoff {
+	c := *b
+	return &c
+}
+
+// Clone implements [CloneableBackoff].
+func (b *ExponentialBackoff) Clone() Backoff {
+	c := *b
+	return &c
+}
+
+func (b *maxRetriesBackoff) clone() (Backoff, bool) {
+	inner, ok := cloneBackoff(b.b)
+	if !ok {
+		return nil, false
+	}
+	c := *b
+	c.b = inner
+	return &c, true
+}
+
+func (b *maxElapsedTimeBackoff) clone() (Backoff, bool) {
+	inner, ok := cloneBackoff(b.b)
+	if !ok {
+		return nil, false
+	}
+	c := *b
+	c.b = inner
+	return &c, true
+}
+
+func (b *capBackoff) clone() (Backoff, bool) {
+	inner, ok := cloneBackoff(b.b)
+	if !ok {
+		return nil, false
+	}
+	c := *b
+	c.b = inner
+	return &c, true
+}
@@ -0,0 +1,274 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     time.Second,
+		Multiplier:      2,
+	}
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Errorf("Next() #%d = %v, want %v", i, got, w)
+		}
+	}
+
+	b.Reset()
+	if got := b.Next(); got != 100*time.Millisecond {
+		t.Errorf("Next() after Reset = %v, want %v", got, 100*time.Millisecond)
+	}
+}
+
+func TestExponentialBackoffMaxElapsedTime(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	b := NewExponentialBackoff()
+	b.MaxElapsedTime = time.Minute
+	b.Clock = clock
+	if got := b.Next(); got == Stop {
+		t.Fatal("first Next() = Stop")
+	}
+	clock.Advance(59 * time.Second)
+	if got := b.Next(); got == Stop {
+		t.Fatal("Next() before MaxElapsedTime = Stop")
+	}
+	clock.Advance(time.Second)
+	if got := b.Next(); got != Stop {
+		t.Errorf("Next() = %v, want Stop", got)
+	}
+}
+
+func TestLimitRetries(t *testing.T) {
+	b := LimitRetries(NewConstantBackoff(time.Second), 2)
+	for i := 0; i < 2; i++ {
+		if got := b.Next(); got != time.Second {
+			t.Errorf("Next() #%d = %v, want %v", i, got, time.Second)
+		}
+	}
+	if got := b.Next(); got != Stop {
+		t.Errorf("Next() = %v, want Stop", got)
+	}
+
+	b.Reset()
+	if got := b.Next(); got != time.Second {
+		t.Errorf("Next() after Reset = %v, want %v", got, time.Second)
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	tests := []struct {
+		name string
+		b    ResettableBackoff
+	}{
+		{"exponential", &ExponentialBackoff{InitialInterval: time.Second, Multiplier: 2, MaxRetries: 3}},
+		{"linear", &LinearBackoff{InitialInterval: time.Second, Increment: time.Second, MaxRetries: 3}},
+		{"fibonacci", &FibonacciBackoff{InitialInterval: time.Second, MaxRetries: 3}},
+		{"decorrelated", &DecorrelatedJitterBackoff{BaseInterval: time.Second, MaxRetries: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 3; j++ {
+					if got := tt.b.Next(); got == Stop {
+						t.Fatalf("Next() #%d = Stop", j)
+					}
+				}
+				if got := tt.b.Next(); got != Stop {
+					t.Errorf("Next() = %v, want Stop", got)
+				}
+				tt.b.Reset()
+			}
+		})
+	}
+}
+
+func TestN(t *testing.T) {
+	want := []time.Duration{time.Second, time.Second, time.Second}
+	if got := Schedule(N(3, time.Second), 5); !slices.Equal(got, want) {
+		t.Errorf("Schedule() = %v, want %v", got, want)
+	}
+}
+
+func TestConstantBackoffMaxRetries(t *testing.T) {
+	b := &ConstantBackoff{Interval: time.Second, MaxRetries: 2}
+	want := []time.Duration{time.Second, time.Second, Stop}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Errorf("Next() #%d = %v, want %v", i, got, w)
+		}
+	}
+	b.Reset()
+	if got := b.Next(); got != time.Second {
+		t.Errorf("Next() after Reset = %v, want %v", got, time.Second)
+	}
+}
+
+func TestForever(t *testing.T) {
+	b := Forever(LimitRetries(&ExponentialBackoff{InitialInterval: time.Second, Multiplier: 2}, 3))
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second, 4 * time.Second}
+	if got := Schedule(b, 5); !slices.Equal(got, want) {
+		t.Errorf("Schedule() = %v, want %v", got, want)
+	}
+}
+
+func TestForeverStopsImmediately(t *testing.T) {
+	b := Forever(&ExponentialBackoff{InitialInterval: time.Second, MaxRetries: 1, ImmediateFirstRetry: true})
+	want := []time.Duration{0, time.Second, time.Second}
+	if got := Schedule(b, 3); !slices.Equal(got, want) {
+		t.Errorf("Schedule() = %v, want %v", got, want)
+	}
+
+	b = Forever(LimitRetries(NewConstantBackoff(time.Second), 0))
+	if got := b.Next(); got != DefaultInitialInterval {
+		t.Errorf("Next() = %v, want %v", got, DefaultInitialInterval)
+	}
+}
+
+func TestLinearBackoff(t *testing.T) {
+	b := NewLinearBackoff(100*time.Millisecond, 200*time.Millisecond)
+	b.MaxInterval = 600 * time.Millisecond
+	want := []time.Duration{
+		100 * time.Millisecond,
+		300 * time.Millisecond,
+		500 * time.Millisecond,
+		600 * time.Millisecond,
+		600 * time.Millisecond,
+	}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Errorf("Next() #%d = %v, want %v", i, got, w)
+		}
+	}
+
+	b.Reset()
+	b.JitterPercent = 50
+	for i := 0; i < 100; i++ {
+		if got := b.Next(); got < 50*time.Millisecond || got > 900*time.Millisecond {
+			t.Fatalf("Next() = %v, want within 50%% of the interval", got)
+		}
+	}
+}
+
+func TestFibonacciBackoff(t *testing.T) {
+	b := NewFibonacciBackoff(100 * time.Millisecond)
+	b.MaxInterval = time.Second
+	want := []time.Duration{
+		100 * time.Millisecond,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+		500 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Errorf("Next() #%d = %v, want %v", i, got, w)
+		}
+	}
+
+	b.Reset()
+	if got := b.Next(); got != 100*time.Millisecond {
+		t.Errorf("Next() after Reset = %v, want %v", got, 100*time.Millisecond)
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	b := NewDecorrelatedJitterBackoff(100*time.Millisecond, time.Second)
+	prev := b.BaseInterval
+	for i := 0; i < 100; i++ {
+		got := b.Next()
+		if got < b.BaseInterval || got > b.MaxInterval || got > prev*3 {
+			t.Fatalf("Next() #%d = %v, want between %v and min(%v, %v)", i, got, b.BaseInterval, prev*3, b.MaxInterval)
+		}
+		prev = got
+	}
+
+	b.Reset()
+	if got := b.Next(); got > 300*time.Millisecond {
+		t.Errorf("Next() after Reset = %v, want at most %v", got, 300*time.Millisecond)
+	}
+}
+
+func TestWithMaxElapsedTime(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	b := WithMaxElapsedTime(NewConstantBackoff(time.Second), time.Minute)
+	useClock(b, clock)
+	if got := b.Next(); got != time.Second {
+		t.Fatalf("Next() = %v, want %v", got, time.Second)
+	}
+	clock.Advance(time.Minute)
+	if got := b.Next(); got != Stop {
+		t.Errorf("Next() = %v, want Stop", got)
+	}
+
+	b.Reset()
+	if got := b.Next(); got != time.Second {
+		t.Errorf("Next() after Reset = %v, want %v", got, time.Second)
+	}
+}
+
+func TestWithCap(t *testing.T) {
+	b := WithCap(LimitRetries(&ExponentialBackoff{
+		InitialInterval: time.Second,
+		Multiplier:      4,
+	}, 3), 10*time.Second)
+	want := []time.Duration{time.Second, 4 * time.Second, 10 * time.Second, Stop}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Errorf("Next() #%d = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestExponentialBackoffImmediateFirstRetry(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval:     time.Second,
+		Multiplier:          2,
+		ImmediateFirstRetry: true,
+	}
+	want := []time.Duration{0, time.Second, 2 * time.Second}
+	for i := 0; i < 2; i++ {
+		if got := Schedule(b, 3); !slices.Equal(got, want) {
+			t.Errorf("Schedule() = %v, want %v", got, want)
+		}
+		b.Reset()
+	}
+}
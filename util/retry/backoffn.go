@@ -0,0 +1,178 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// BackoffN is a stateless alternative to [Backoff], which is given the number
+// of the attempt that failed. As it has no state, a single BackoffN can be
+// shared by many concurrent retry loops.
+type BackoffN interface {
+	// Next returns the duration to wait after the given attempt failed, or
+	// [Stop] if no more attempts should be made. Attempts are numbered
+	// starting at 1.
+	Next(attempt int) time.Duration
+}
+
+// ConstantN is a [BackoffN] that waits the same interval after every attempt.
+type ConstantN time.Duration
+
+// Next implements [BackoffN].
+func (b ConstantN) Next(int) time.Duration {
+	return time.Duration(b)
+}
+
+// ExponentialN is a [BackoffN] that multiplies the interval by a constant
+// after each attempt, up to a maximum interval. It is safe for concurrent
+// use.
+type ExponentialN struct {
+	// InitialInterval is the interval after the first attempt.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the interval between attempts. If zero, the interval
+	// is not capped.
+	MaxInterval time.Duration
+
+	// Multiplier is the factor the interval is multiplied by after each
+	// attempt.
+	Multiplier float64
+
+	// Jitter is applied to each interval. If nil, intervals are not
+	// jittered.
+	Jitter Jitter
+}
+
+// Next implements [BackoffN].
+func (b ExponentialN) Next(attempt int) time.Duration {
+	next := float64(b.InitialInterval) * math.Pow(b.Multiplier, float64(max(attempt-1, 0)))
+	d := time.Duration(next)
+	if next >= math.MaxInt64 {
+		d = math.MaxInt64
+	}
+	if b.MaxInterval > 0 {
+		d = min(d, b.MaxInterval)
+	}
+	return applyJitter(b.Jitter, d)
+}
+
+// LinearN is a [BackoffN] that adds a constant increment to the interval after
+// each attempt, up to a maximum interval. It is safe for concurrent use.
+type LinearN struct {
+	// InitialInterval is the interval after the first attempt.
+	InitialInterval time.Duration
+
+	// Increment is added to the interval after each attempt.
+	Increment time.Duration
+
+	// MaxInterval caps the interval between attempts. If zero, the interval
+	// is not capped.
+	MaxInterval time.Duration
+
+	// Jitter is applied to each interval. If nil, intervals are not
+	// jittered.
+	Jitter Jitter
+}
+
+// Next implements [BackoffN].
+func (b LinearN) Next(attempt int) time.Duration {
+	d := b.InitialInterval + b.Increment*time.Duration(max(attempt-1, 0))
+	if b.MaxInterval > 0 {
+		d = min(d, b.MaxInterval)
+	}
+	return applyJitter(b.Jitter, d)
+}
+
+// applyJitter applies j to d, if j is not nil.
+func applyJitter(j Jitter, d time.Duration) time.Duration {
+	if j == nil {
+		return d
+	}
+	return j.Apply(d)
+}
+
+// fromN adapts a BackoffN to a Backoff.
+type fromN struct {
+	b       BackoffN
+	attempt int
+}
+
+// FromN returns a [ResettableBackoff] that counts attempts and calls b with
+// the number of each attempt that failed. Each retry loop needs its own
+// backoff returned by FromN.
+func FromN(b BackoffN) ResettableBackoff {
+	return &fromN{b: b}
+}
+
+// Next implements [Backoff].
+func (b *fromN) Next() time.Duration {
+	b.attempt++
+	return b.b.Next(b.attempt)
+}
+
+// Reset restarts counting attempts from 1.
+func (b *fromN) Reset() {
+	b.attempt = 0
+}
+
+// toN adapts a Backoff to a BackoffN.
+type toN struct {
+	mu       sync.Mutex
+	b        Backoff
+	schedule []time.Duration
+}
+
+// ToN returns a [BackoffN] that returns the intervals of b. The intervals are
+// recorded the first time they are needed, so that all callers see the same
+// schedule, including the same jitter. The returned backoff is safe for
+// concurrent use, but uses memory for each attempt recorded.
+func ToN(b Backoff) BackoffN {
+	return &toN{b: b}
+}
+
+// Next implements [BackoffN].
+func (b *toN) Next(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for len(b.schedule) < attempt {
+		if n := len(b.schedule); n > 0 && b.schedule[n-1] == Stop {
+			return Stop
+		}
+		b.schedule = append(b.schedule, b.b.Next())
+	}
+	return b.schedule[attempt-1]
+}
+
+// Clone implements [CloneableBackoff].
+func (b *fromN) Clone() Backoff {
+	c := *b
+	return &c
+}
@@ -0,0 +1,107 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExponentialN(t *testing.T) {
+	b := ExponentialN{InitialInterval: time.Second, MaxInterval: 10 * time.Second, Multiplier: 2}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := b.Next(i + 1); got != w {
+			t.Errorf("Next(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	if got := b.Next(1000); got != 10*time.Second {
+		t.Errorf("Next(1000) = %v, want %v", got, 10*time.Second)
+	}
+}
+
+func TestLinearN(t *testing.T) {
+	b := LinearN{InitialInterval: time.Second, Increment: time.Second, MaxInterval: 3 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i, w := range want {
+		if got := b.Next(i + 1); got != w {
+			t.Errorf("Next(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestFromN(t *testing.T) {
+	b := FromN(LinearN{InitialInterval: time.Second, Increment: time.Second})
+	for i := 1; i <= 3; i++ {
+		if got, want := b.Next(), time.Duration(i)*time.Second; got != want {
+			t.Errorf("Next() #%d = %v, want %v", i, got, want)
+		}
+	}
+	b.Reset()
+	if got := b.Next(); got != time.Second {
+		t.Errorf("Next() after Reset = %v, want %v", got, time.Second)
+	}
+}
+
+func TestToN(t *testing.T) {
+	b := ToN(LimitRetries(NewLinearBackoff(time.Second, time.Second), 2))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			want := []time.Duration{time.Second, 2 * time.Second, Stop, Stop}
+			for j, w := range want {
+				if got := b.Next(j + 1); got != w {
+					t.Errorf("Next(%d) = %v, want %v", j+1, got, w)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDoBackoffN(t *testing.T) {
+	b := ConstantN(time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Do(context.Background(), func(ctx context.Context) error {
+				if Attempt(ctx) < 3 {
+					return errTest
+				}
+				return nil
+			}, WithBackoffN(b))
+			if err != nil {
+				t.Errorf("Do() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,88 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExhausted is returned, wrapping the last error from the
+// operation, when a retry is not allowed by the [Budget] set with
+// [WithBudget].
+var ErrBudgetExhausted = errors.New("retry: budget exhausted")
+
+// Budget limits the number of retries made by many operations together, so
+// that retries don't overload a service that is already failing. It is a
+// token bucket: every retry withdraws a token, and every successful
+// operation deposits a fraction of a token. While the bucket is empty,
+// operations fail instead of being retried.
+//
+// A Budget is safe for concurrent use, and should be shared by all
+// operations calling the same service.
+type Budget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// NewBudget returns a full [Budget] allowing a burst of up to maxTokens
+// retries, which is refilled by ratio tokens for every successful operation.
+// For example, a ratio of 0.1 allows one retry for every ten successful
+// operations in the long run.
+func NewBudget(maxTokens int, ratio float64) *Budget {
+	return &Budget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+// Withdraw takes a token for a retry, reporting whether the retry is
+// allowed.
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Deposit adds tokens for a successful operation.
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+// Tokens returns the number of tokens in the budget.
+func (b *Budget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
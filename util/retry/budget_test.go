@@ -0,0 +1,80 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(2, 0.5)
+	if !b.Withdraw() || !b.Withdraw() {
+		t.Fatal("Withdraw() = false, want true")
+	}
+	if b.Withdraw() {
+		t.Fatal("Withdraw() on empty budget = true, want false")
+	}
+
+	b.Deposit()
+	if b.Withdraw() {
+		t.Fatal("Withdraw() with half a token = true, want false")
+	}
+	b.Deposit()
+	if !b.Withdraw() {
+		t.Fatal("Withdraw() = false, want true")
+	}
+
+	for i := 0; i < 10; i++ {
+		b.Deposit()
+	}
+	if got := b.Tokens(); got != 2 {
+		t.Errorf("Tokens() = %v, want 2", got)
+	}
+}
+
+func TestDoBudget(t *testing.T) {
+	budget := NewBudget(2, 1)
+	calls := 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return errTest
+	}, WithBackoff(NewConstantBackoff(time.Millisecond)), WithBudget(budget))
+	if !errors.Is(err, ErrBudgetExhausted) || !errors.Is(err, errTest) {
+		t.Errorf("Do() = %v, want %v wrapping %v", err, ErrBudgetExhausted, errTest)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+
+	_ = Do(context.Background(), func(context.Context) error {
+		return nil
+	}, WithBudget(budget))
+	if got := budget.Tokens(); got != 1 {
+		t.Errorf("Tokens() = %v, want 1", got)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"time"
+)
+
+// chainBackoff uses a sequence of backoffs, one after another.
+type chainBackoff struct {
+	backoffs []Backoff
+	index    int
+}
+
+// ChainBackoff returns a [ResettableBackoff] that uses each of the given
+// backoffs in turn, switching to the next one once the current one returns
+// [Stop]. For example, three fast retries followed by exponential backoff:
+//
+//	retry.ChainBackoff(
+//		retry.LimitRetries(retry.NewConstantBackoff(100*time.Millisecond), 3),
+//		retry.NewExponentialBackoff(),
+//	)
+//
+// Resetting the returned backoff resets the backoffs that are a
+// [ResettableBackoff], and starts again from the first backoff.
+func ChainBackoff(backoffs ...Backoff) ResettableBackoff {
+	return &chainBackoff{backoffs: backoffs}
+}
+
+// Next implements [Backoff].
+func (b *chainBackoff) Next() time.Duration {
+	for b.index < len(b.backoffs) {
+		if next := b.backoffs[b.index].Next(); next != Stop {
+			return next
+		}
+		b.index++
+	}
+	return Stop
+}
+
+// Reset implements [ResettableBackoff].
+func (b *chainBackoff) Reset() {
+	for _, backoff := range b.backoffs {
+		resetBackoff(backoff)
+	}
+	b.index = 0
+}
+
+func (b *chainBackoff) useClock(c Clock) {
+	for _, backoff := range b.backoffs {
+		useClock(backoff, c)
+	}
+}
+
+func (b *chainBackoff) clone() (Backoff, bool) {
+	backoffs := make([]Backoff, len(b.backoffs))
+	for i, backoff := range b.backoffs {
+		c, ok := cloneBackoff(backoff)
+		if !ok {
+			return nil, false
+		}
+		backoffs[i] = c
+	}
+	return &chainBackoff{backoffs: backoffs, index: b.index}, true
+}
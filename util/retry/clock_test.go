@@ -0,0 +1,145 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	t1 := clock.NewTimer(time.Second)
+	t2 := clock.NewTimer(2 * time.Second)
+	t3 := clock.NewTimer(3 * time.Second)
+	if !t3.Stop() {
+		t.Error("Stop() = false, want true")
+	}
+
+	clock.Advance(time.Second)
+	if got := clock.Now(); !got.Equal(start.Add(time.Second)) {
+		t.Errorf("Now() = %v, want %v", got, start.Add(time.Second))
+	}
+	select {
+	case got := <-t1.C():
+		if !got.Equal(start.Add(time.Second)) {
+			t.Errorf("timer fired at %v, want %v", got, start.Add(time.Second))
+		}
+	default:
+		t.Error("expected timer to fire")
+	}
+	select {
+	case <-t2.C():
+		t.Error("expected timer not to fire yet")
+	default:
+	}
+
+	clock.Advance(5 * time.Second)
+	select {
+	case <-t2.C():
+	default:
+		t.Error("expected timer to fire")
+	}
+	select {
+	case <-t3.C():
+		t.Error("expected stopped timer not to fire")
+	default:
+	}
+	if t1.Stop() {
+		t.Error("Stop() on fired timer = true, want false")
+	}
+}
+
+func TestDoClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(context.Background(), func(context.Context) error {
+			return errTest
+		},
+			WithBackoff(NewConstantBackoff(time.Hour)),
+			WithMaxRetries(2),
+			WithClock(clock),
+		)
+	}()
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}
+	err := <-done
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("Do() = %v, want %T", err, exhausted)
+	}
+	if exhausted.Elapsed != 2*time.Hour {
+		t.Errorf("Elapsed = %v, want %v", exhausted.Elapsed, 2*time.Hour)
+	}
+}
+
+func TestDoClockDeadline(t *testing.T) {
+	start := time.Now()
+	clock := NewFakeClock(start)
+	ctx, cancel := context.WithDeadline(context.Background(), start.Add(90*time.Minute))
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(ctx, func(context.Context) error {
+			return errTest
+		},
+			WithBackoff(NewConstantBackoff(time.Hour)),
+			WithClock(clock),
+		)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	// Only 30 minutes are left on the fake clock, so Do gives up without
+	// waiting for the next attempt
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestDoClockDoesNotLeak(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	b := NewExponentialBackoff()
+	b.InitialInterval = 0
+	attempts := 0
+	_ = Do(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts < 2 {
+			return errTest
+		}
+		return nil
+	}, WithBackoff(b), WithClock(clock))
+	if b.Clock != nil || b.clock != nil {
+		t.Errorf("backoff clock = %v, %v after Do(), want nil", b.Clock, b.clock)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+)
+
+// maxRetriesKey is the context key for the maximum number of retries.
+type maxRetriesKey struct{}
+
+// Disable returns a copy of ctx in which the retry functions attempt
+// operations only once, without retrying. It is the same as
+// [ContextWithMaxRetries](ctx, 0), and is useful to make tests or units of
+// work fail fast without reconfiguring every retry below them.
+func Disable(ctx context.Context) context.Context {
+	return ContextWithMaxRetries(ctx, 0)
+}
+
+// ContextWithMaxRetries returns a copy of ctx in which the retry functions
+// retry operations at most n times, in addition to any limit set with
+// options or the backoff. If ctx already has a lower limit, it is kept, so
+// that a limit cannot be raised further down the call tree.
+func ContextWithMaxRetries(ctx context.Context, n int) context.Context {
+	n = max(n, 0)
+	if limit, ok := maxRetriesFromContext(ctx); ok && limit <= n {
+		return ctx
+	}
+	return context.WithValue(ctx, maxRetriesKey{}, n)
+}
+
+// maxRetriesFromContext returns the maximum number of retries set with
+// [ContextWithMaxRetries], if any.
+func maxRetriesFromContext(ctx context.Context) (int, bool) {
+	n, ok := ctx.Value(maxRetriesKey{}).(int)
+	return n, ok
+}
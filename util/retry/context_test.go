@@ -0,0 +1,89 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDisable(t *testing.T) {
+	attempts := 0
+	err := Do(Disable(context.Background()), func(context.Context) error {
+		attempts++
+		return errTest
+	}, WithBackoff(NewConstantBackoff(0)))
+	if !errors.Is(err, ErrExhausted) || !errors.Is(err, errTest) {
+		t.Errorf("err = %v, want %v", err, errTest)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestContextWithMaxRetries(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		opts []Option
+		want int
+	}{
+		{
+			name: "context limit",
+			ctx:  ContextWithMaxRetries(context.Background(), 2),
+			want: 3,
+		},
+		{
+			name: "option limit is lower",
+			ctx:  ContextWithMaxRetries(context.Background(), 2),
+			opts: []Option{WithMaxRetries(1)},
+			want: 2,
+		},
+		{
+			name: "cannot be raised",
+			ctx:  ContextWithMaxRetries(Disable(context.Background()), 5),
+			want: 1,
+		},
+		{
+			name: "can be lowered",
+			ctx:  Disable(ContextWithMaxRetries(context.Background(), 5)),
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			opts := append([]Option{WithBackoff(NewConstantBackoff(0))}, tt.opts...)
+			_ = Do(tt.ctx, func(context.Context) error {
+				attempts++
+				return errTest
+			}, opts...)
+			if attempts != tt.want {
+				t.Errorf("attempts = %d, want %d", attempts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// DecorrelatedJitterBackoff chooses each interval randomly between the base
+// interval and three times the previous interval, up to a maximum interval.
+// This spreads out retries from many clients better than adding jitter to
+// [ExponentialBackoff], as described in the AWS Architecture Blog post
+// "Exponential Backoff And Jitter".
+//
+// DecorrelatedJitterBackoff is not safe for concurrent use.
+type DecorrelatedJitterBackoff struct {
+	// BaseInterval is the minimum interval between attempts.
+	BaseInterval time.Duration
+
+	// MaxInterval caps the interval between attempts. If zero, the interval
+	// is not capped.
+	MaxInterval time.Duration
+
+	// MaxRetries is the number of retries after which Next returns [Stop].
+	// If zero, the number of retries is not limited.
+	MaxRetries int
+
+	// Rand is the source of randomness for the intervals, as for
+	// [ExponentialBackoff.Rand].
+	Rand *rand.Rand
+
+	prev    time.Duration
+	retries int
+}
+
+// NewDecorrelatedJitterBackoff returns a [DecorrelatedJitterBackoff] with
+// the given base and maximum intervals.
+func NewDecorrelatedJitterBackoff(base, maxInterval time.Duration) *DecorrelatedJitterBackoff {
+	return &DecorrelatedJitterBackoff{
+		BaseInterval: base,
+		MaxInterval:  maxInterval,
+	}
+}
+
+// Next implements [Backoff].
+func (b *DecorrelatedJitterBackoff) Next() time.Duration {
+	if b.MaxRetries > 0 && b.retries >= b.MaxRetries {
+		return Stop
+	}
+	b.retries++
+	prev := max(b.prev, b.BaseInterval)
+	upper := prev * 3
+	if upper < prev {
+		// Overflow
+		upper = prev
+	}
+
+	next := b.BaseInterval
+	if upper > b.BaseInterval {
+		next += randN(b.Rand, upper-b.BaseInterval)
+	}
+	if b.MaxInterval > 0 && next > b.MaxInterval {
+		next = b.MaxInterval
+	}
+	b.prev = next
+	return next
+}
+
+// Reset restarts the backoff from the base interval.
+func (b *DecorrelatedJitterBackoff) Reset() {
+	b.prev = 0
+	b.retries = 0
+}
+
+// Clone implements [CloneableBackoff].
+func (b *DecorrelatedJitterBackoff) Clone() Backoff {
+	c := *b
+	return &c
+}
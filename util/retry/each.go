@@ -0,0 +1,127 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ItemResult is the outcome of processing an item with [Each].
+type ItemResult[T any] struct {
+	// Item is the item that was processed.
+	Item T
+
+	// Err is the error returned for the item, or nil if it was processed
+	// successfully.
+	Err error
+
+	// Attempts is the number of attempts made to process the item. It is
+	// zero if the context was done before the item was processed.
+	Attempts int
+}
+
+// Report is the outcome of [Each], with one result for each item in the
+// order of the items.
+type Report[T any] []ItemResult[T]
+
+// Failed returns the items that were not processed successfully, for
+// example to process them again later.
+func (r Report[T]) Failed() []T {
+	var failed []T
+	for _, result := range r {
+		if result.Err != nil {
+			failed = append(failed, result.Item)
+		}
+	}
+	return failed
+}
+
+// Err returns the errors of the items that failed joined with
+// [errors.Join], or nil if all items were processed successfully.
+func (r Report[T]) Err() error {
+	var errs []error
+	for _, result := range r {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Each calls f for each item, retrying each item independently as with
+// [Do], with a new backoff from newBackoff. If newBackoff is nil, the
+// backoff is set by the options, which must not share a backoff between
+// items if they are processed concurrently. Up to [WithConcurrency] items are
+// processed concurrently.
+//
+// Each returns a report of the outcome for every item, and the errors of the
+// items that failed, see [Report.Err]. If the context is done, the items
+// that have not been processed yet fail with the context's error.
+func Each[T any](
+	ctx context.Context,
+	items []T,
+	f func(ctx context.Context, item T) error,
+	newBackoff BackoffFactory,
+	opts ...Option,
+) (Report[T], error) {
+	if newBackoff != nil {
+		opts = append([]Option{WithBackoffFactory(newBackoff)}, opts...)
+	}
+	concurrency := max(newConfig(opts).concurrency, 1)
+
+	report := make(Report[T], len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		report[i].Item = item
+		if err := ctx.Err(); err != nil {
+			report[i].Err = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			report[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result := &report[i]
+			result.Err = Do(ctx, func(ctx context.Context) error {
+				result.Attempts++
+				return f(ctx, item)
+			}, opts...)
+		}()
+	}
+	wg.Wait()
+	return report, report.Err()
+}
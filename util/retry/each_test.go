@@ -0,0 +1,88 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	failures := map[int]int{1: 1, 2: 10, 4: 2}
+	var attempts [5]atomic.Int32
+	report, err := Each(context.Background(), []int{0, 1, 2, 3, 4}, func(_ context.Context, item int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if int(attempts[item].Add(1)) <= failures[item] {
+			return errTest
+		}
+		return nil
+	}, func() Backoff {
+		return N(3, 0)
+	}, WithConcurrency(2))
+
+	if !errors.Is(err, errTest) {
+		t.Errorf("Each() error = %v, want %v", err, errTest)
+	}
+	if got := report.Failed(); !slices.Equal(got, []int{2}) {
+		t.Errorf("Failed() = %v, want [2]", got)
+	}
+	wantAttempts := []int{1, 2, 4, 1, 3}
+	for i, result := range report {
+		if result.Item != i {
+			t.Errorf("report[%d].Item = %d, want %d", i, result.Item, i)
+		}
+		if result.Attempts != wantAttempts[i] {
+			t.Errorf("report[%d].Attempts = %d, want %d", i, result.Attempts, wantAttempts[i])
+		}
+	}
+	if m := maxRunning.Load(); m > 2 {
+		t.Errorf("max concurrency = %d, want at most 2", m)
+	}
+}
+
+func TestEachContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := Each(ctx, []string{"a", "b"}, func(context.Context, string) error {
+		return nil
+	}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Each() error = %v, want %v", err, context.Canceled)
+	}
+	if len(report.Failed()) != 2 {
+		t.Errorf("Failed() = %v, want all items", report.Failed())
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExhausted is matched by errors returned when the backoff stops before
+// the operation succeeds, see [ExhaustedError].
+var ErrExhausted = errors.New("retry: attempts exhausted")
+
+// ExhaustedError is returned when the backoff returns [Stop] before the
+// operation succeeds. It wraps the error from the last attempt, and matches
+// [ErrExhausted] with [errors.Is].
+type ExhaustedError struct {
+	// Err is the error from the last attempt, or the errors from all
+	// attempts if [WithErrorAggregation] is used.
+	Err error
+
+	// Attempts is the number of attempts that were made.
+	Attempts int
+
+	// Elapsed is the time between the start of the first attempt and giving
+	// up.
+	Elapsed time.Duration
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("retry: gave up after %d attempts in %v: %v", e.Attempts, e.Elapsed, e.Err)
+}
+
+func (e *ExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is [ErrExhausted].
+func (e *ExhaustedError) Is(target error) bool {
+	return target == ErrExhausted
+}
+
+// SuppressedError is passed to the notify function in place of the error
+// from the failed attempt when earlier notifications were skipped because of
+// [WithNotifyEvery] or [WithNotifyMinInterval]. It wraps the error from the
+// failed attempt.
+type SuppressedError struct {
+	// Err is the error from the failed attempt.
+	Err error
+
+	// Suppressed is the number of notifications that were skipped since the
+	// last one that was delivered.
+	Suppressed int
+}
+
+func (e *SuppressedError) Error() string {
+	return fmt.Sprintf("%v (%d earlier notifications suppressed)", e.Err, e.Suppressed)
+}
+
+func (e *SuppressedError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// FibonacciBackoff increases the interval between attempts following the
+// Fibonacci sequence, which grows more gently than [ExponentialBackoff]. The
+// intervals are InitialInterval multiplied by 1, 1, 2, 3, 5, 8 and so on, up
+// to a maximum interval.
+//
+// FibonacciBackoff is not safe for concurrent use.
+type FibonacciBackoff struct {
+	// InitialInterval is the interval before the first and second retries.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the interval between attempts. If zero, the interval
+	// is not capped.
+	MaxInterval time.Duration
+
+	// MaxRetries is the number of retries after which Next returns [Stop].
+	// If zero, the number of retries is not limited.
+	MaxRetries int
+
+	// JitterPercent randomly adjusts each interval by up to the given
+	// percentage in either direction. If zero, intervals are not jittered.
+	JitterPercent int
+
+	// Rand is the source of randomness for jitter, as for
+	// [ExponentialBackoff.Rand].
+	Rand *rand.Rand
+
+	prev, current time.Duration
+	retries       int
+}
+
+// NewFibonacciBackoff returns a [FibonacciBackoff] with the given initial
+// interval, capped at [DefaultMaxInterval].
+func NewFibonacciBackoff(initial time.Duration) *FibonacciBackoff {
+	return &FibonacciBackoff{
+		InitialInterval: initial,
+		MaxInterval:     DefaultMaxInterval,
+	}
+}
+
+// Next implements [Backoff].
+func (b *FibonacciBackoff) Next() time.Duration {
+	if b.MaxRetries > 0 && b.retries >= b.MaxRetries {
+		return Stop
+	}
+	b.retries++
+	if b.current == 0 {
+		b.current = b.InitialInterval
+	} else {
+		b.prev, b.current = b.current, b.prev+b.current
+	}
+	if b.MaxInterval > 0 && (b.current > b.MaxInterval || b.current < 0) {
+		b.current = b.MaxInterval
+	}
+	return percentageJitter{min(b.JitterPercent, 100), b.Rand}.Apply(b.current)
+}
+
+// Reset restarts the backoff from the initial interval.
+func (b *FibonacciBackoff) Reset() {
+	b.prev, b.current = 0, 0
+	b.retries = 0
+}
+
+// Clone implements [CloneableBackoff].
+func (b *FibonacciBackoff) Clone() Backoff {
+	c := *b
+	return &c
+}
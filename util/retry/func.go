@@ -0,0 +1,68 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"time"
+)
+
+// BackoffFunc is an adapter to allow the use of an ordinary function as a
+// [Backoff].
+type BackoffFunc func() time.Duration
+
+// Next implements [Backoff] by calling f.
+func (f BackoffFunc) Next() time.Duration {
+	return f()
+}
+
+// indexedBackoff calls a function with the index of each retry.
+type indexedBackoff struct {
+	f     func(i int) time.Duration
+	index int
+}
+
+// IndexedBackoff returns a [ResettableBackoff] that calls f with the index of
+// each retry, starting at 0, to get the interval before it.
+func IndexedBackoff(f func(i int) time.Duration) ResettableBackoff {
+	return &indexedBackoff{f: f}
+}
+
+// Next implements [Backoff].
+func (b *indexedBackoff) Next() time.Duration {
+	next := b.f(b.index)
+	b.index++
+	return next
+}
+
+// Reset restarts the backoff from index 0.
+func (b *indexedBackoff) Reset() {
+	b.index = 0
+}
+
+// Clone implements [CloneableBackoff].
+func (b *indexedBackoff) Clone() Backoff {
+	c := *b
+	return &c
+}
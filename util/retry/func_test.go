@@ -0,0 +1,57 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffFunc(t *testing.T) {
+	var b Backoff = BackoffFunc(func() time.Duration { return time.Second })
+	if got := b.Next(); got != time.Second {
+		t.Errorf("Next() = %v, want %v", got, time.Second)
+	}
+}
+
+func TestIndexedBackoff(t *testing.T) {
+	schedule := []time.Duration{0, time.Second, 5 * time.Second}
+	b := IndexedBackoff(func(i int) time.Duration {
+		if i >= len(schedule) {
+			return Stop
+		}
+		return schedule[i]
+	})
+	for i, want := range append(schedule, Stop) {
+		if got := b.Next(); got != want {
+			t.Errorf("Next() #%d = %v, want %v", i, got, want)
+		}
+	}
+
+	b.Reset()
+	if got := b.Next(); got != 0 {
+		t.Errorf("Next() after Reset = %v, want 0", got)
+	}
+}
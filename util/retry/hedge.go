@@ -0,0 +1,122 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedge calls op, and if it has not succeeded after delay, calls it again
+// concurrently, up to maxHedges additional times. The first successful
+// result is returned and the contexts of the other attempts are cancelled.
+// If an attempt fails while no other attempts are running, the next attempt
+// is started immediately. A negative maxHedges is treated as zero.
+//
+// If all attempts fail, the errors are joined with [errors.Join]. If an
+// attempt fails with an error wrapped with [Permanent], no more attempts are
+// started and the error is returned. The number of each attempt is available
+// from its context with [Attempt].
+func Hedge(ctx context.Context, op Retryable, delay time.Duration, maxHedges int) error {
+	_, err := HedgeValue(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	}, delay, maxHedges)
+	return err
+}
+
+// HedgeValue is like [Hedge], but for operations that return a value. The
+// value from the first successful attempt is returned.
+func HedgeValue[T any](
+	ctx context.Context,
+	op func(ctx context.Context) (T, error),
+	delay time.Duration,
+	maxHedges int,
+) (T, error) {
+	maxHedges = max(maxHedges, 0)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+	results := make(chan result, maxHedges+1)
+	launched := 0
+	launch := func() {
+		launched++
+		attemptCtx := context.WithValue(ctx, attemptKey{}, launched)
+		go func() {
+			v, err := op(attemptCtx)
+			results <- result{v: v, err: err}
+		}()
+	}
+
+	var zero T
+	var errs []error
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	launch()
+	for {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.v, nil
+			}
+			var perm *permanentError
+			if errors.As(r.err, &perm) {
+				return zero, perm.err
+			}
+			errs = append(errs, r.err)
+			if len(errs) < launched {
+				continue
+			}
+			if launched > maxHedges {
+				return zero, errors.Join(errs...)
+			}
+			// Every attempt failed, so don't wait for the delay
+			launch()
+			resetTimer(timer, delay)
+		case <-timer.C:
+			if launched <= maxHedges {
+				launch()
+				timer.Reset(delay)
+			}
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// resetTimer stops the timer, draining its channel if needed, and resets it.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
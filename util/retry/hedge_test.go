@@ -0,0 +1,93 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge(t *testing.T) {
+	var cancelled atomic.Int32
+	v, err := HedgeValue(context.Background(), func(ctx context.Context) (int, error) {
+		if Attempt(ctx) == 1 {
+			// The first attempt is slow
+			<-ctx.Done()
+			cancelled.Add(1)
+			return 0, ctx.Err()
+		}
+		return Attempt(ctx), nil
+	}, time.Millisecond, 2)
+	if err != nil || v != 2 {
+		t.Errorf("HedgeValue() = %d, %v, want 2, nil", v, err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for cancelled.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if cancelled.Load() != 1 {
+		t.Error("expected slow attempt to be cancelled")
+	}
+}
+
+func TestHedgeAllFail(t *testing.T) {
+	var calls atomic.Int32
+	err := Hedge(context.Background(), func(context.Context) error {
+		calls.Add(1)
+		return errTest
+	}, time.Hour, 2)
+	if !errors.Is(err, errTest) {
+		t.Errorf("Hedge() = %v, want %v", err, errTest)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestHedgePermanent(t *testing.T) {
+	var calls atomic.Int32
+	err := Hedge(context.Background(), func(context.Context) error {
+		calls.Add(1)
+		return Permanent(errTest)
+	}, time.Hour, 2)
+	if !errors.Is(err, errTest) || calls.Load() != 1 {
+		t.Errorf("Hedge() = %v after %d calls, want %v after 1 call", err, calls.Load(), errTest)
+	}
+}
+
+func TestHedgeNegative(t *testing.T) {
+	var calls atomic.Int32
+	err := Hedge(context.Background(), func(context.Context) error {
+		calls.Add(1)
+		return errTest
+	}, time.Millisecond, -5)
+	if !errors.Is(err, errTest) || calls.Load() != 1 {
+		t.Errorf("Hedge() = %v after %d calls, want %v after 1 call", err, calls.Load(), errTest)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryAfter returns the delay requested by the Retry-After header of a 429
+// (Too Many Requests) or 503 (Service Unavailable) response. The header can
+// either be a number of seconds or an HTTP date. RetryAfter returns false if
+// the response has another status code, or no valid Retry-After header.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil ||
+		(resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+// parseRetryAfter parses the value of a Retry-After header.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// StatusError is an error for an unsuccessful HTTP response. If the response
+// requested a delay with a Retry-After header, the retry functions wait for
+// the requested delay instead of the interval from the backoff.
+type StatusError struct {
+	// StatusCode is the status code of the response, e.g. 503.
+	StatusCode int
+
+	// Status is the status of the response, e.g. "503 Service Unavailable".
+	Status string
+
+	retryAfter time.Duration
+}
+
+// ResponseError returns a [StatusError] for the response if its status code
+// is 400 or above, or nil otherwise.
+func ResponseError(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	retryAfter, _ := RetryAfter(resp)
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		retryAfter: retryAfter,
+	}
+}
+
+func (e *StatusError) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("retry: unsuccessful HTTP status %d", e.StatusCode)
+	}
+	return "retry: unsuccessful HTTP status " + e.Status
+}
+
+// RetryAfter returns the delay requested by the response, or 0 if no delay
+// was requested.
+func (e *StatusError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// retryAfter returns the delay requested by an error with a RetryAfter
+// method, such as [StatusError].
+func retryAfter(err error) (time.Duration, bool) {
+	var ra interface{ RetryAfter() time.Duration }
+	if errors.As(err, &ra) {
+		if d := ra.RetryAfter(); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,96 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "120", want: 2 * time.Minute, ok: true},
+		{value: "-1", ok: false},
+		{value: "Mon, 01 Jan 2024 00:00:30 GMT", want: 30 * time.Second, ok: true},
+		{value: "Sun, 31 Dec 2023 23:00:00 GMT", want: 0, ok: true},
+		{value: "soon", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusOK, Status: "200 OK"}
+	if err := ResponseError(resp); err != nil {
+		t.Errorf("ResponseError(200) = %v, want nil", err)
+	}
+
+	resp = &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Status:     "429 Too Many Requests",
+		Header:     http.Header{"Retry-After": []string{"3"}},
+	}
+	var statusErr *StatusError
+	if err := ResponseError(resp); !errors.As(err, &statusErr) {
+		t.Fatalf("ResponseError(429) = %v, want %T", err, statusErr)
+	}
+	if statusErr.StatusCode != http.StatusTooManyRequests || statusErr.RetryAfter() != 3*time.Second {
+		t.Errorf("got status %d and delay %v, want 429 and 3s", statusErr.StatusCode, statusErr.RetryAfter())
+	}
+
+	resp.StatusCode = http.StatusInternalServerError
+	if err := ResponseError(resp); !errors.As(err, &statusErr) || statusErr.RetryAfter() != 0 {
+		t.Errorf("ResponseError(500) = %v, want no delay", err)
+	}
+}
+
+func TestDoRetryAfter(t *testing.T) {
+	var intervals []time.Duration
+	_ = Do(context.Background(), func(ctx context.Context) error {
+		if Attempt(ctx) == 1 {
+			return &StatusError{StatusCode: http.StatusServiceUnavailable, retryAfter: 2 * time.Millisecond}
+		}
+		return nil
+	},
+		WithBackoff(NewConstantBackoff(time.Hour)),
+		WithNotify(func(_ error, next time.Duration) { intervals = append(intervals, next) }),
+	)
+	if len(intervals) != 1 || intervals[0] != 2*time.Millisecond {
+		t.Errorf("intervals = %v, want [2ms]", intervals)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// OpenReaderFunc opens a stream for reading, positioned at offset bytes from
+// the start of the stream. It is used by [Reader] to resume reading after a
+// failure, for example by seeking a file or sending an HTTP range request.
+type OpenReaderFunc func(ctx context.Context, offset int64) (io.ReadCloser, error)
+
+// OpenWriterFunc opens a stream for writing, positioned at offset bytes from
+// the start of the stream. It is used by [Writer] to resume writing after a
+// failure.
+type OpenWriterFunc func(ctx context.Context, offset int64) (io.WriteCloser, error)
+
+// SeekReader returns an [OpenReaderFunc] that seeks rs to the offset, relative
+// to the start of rs. Closing the returned reader does not close rs.
+func SeekReader(rs io.ReadSeeker) OpenReaderFunc {
+	return func(_ context.Context, offset int64) (io.ReadCloser, error) {
+		if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(rs), nil
+	}
+}
+
+// SeekWriter returns an [OpenWriterFunc] that seeks ws to the offset,
+// relative to the start of ws. Closing the returned writer does not close ws.
+func SeekWriter(ws io.WriteSeeker) OpenWriterFunc {
+	return func(_ context.Context, offset int64) (io.WriteCloser, error) {
+		if _, err := ws.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return nopWriteCloser{ws}, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// isTransientIO reports whether an error from a stream is likely to be
+// resolved by reopening it. This is the case for errors that are transient
+// according to [IsTransient], and for [io.ErrUnexpectedEOF], which is
+// returned when a connection is closed mid-stream.
+func isTransientIO(err error) bool {
+	return IsTransient(err) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Reader reads from a stream, reopening it at the current offset when a read
+// fails with a transient error. By default, errors that are transient
+// according to [IsTransient] and [io.ErrUnexpectedEOF] are retried, which can
+// be changed with [WithRetryIf]. The backoff is reset after every successful
+// read.
+//
+// Reader is not safe for concurrent use.
+type Reader struct {
+	ctx    context.Context
+	open   OpenReaderFunc
+	opts   []Option
+	r      io.ReadCloser
+	offset int64
+}
+
+// NewReader returns a [Reader] that opens the stream with open, starting at
+// offset zero. The stream is opened on the first read. The options configure
+// the retries of each read, see [Do].
+func NewReader(ctx context.Context, open OpenReaderFunc, opts ...Option) *Reader {
+	return &Reader{
+		ctx:  ctx,
+		open: open,
+		opts: append([]Option{WithRetryIf(isTransientIO)}, opts...),
+	}
+}
+
+// Read implements [io.Reader].
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return DoValue(r.ctx, func(ctx context.Context) (int, error) {
+		if r.r == nil {
+			rc, err := r.open(ctx, r.offset)
+			if err != nil {
+				return 0, err
+			}
+			r.r = rc
+		}
+
+		n, err := r.r.Read(p)
+		r.offset += int64(n)
+		switch {
+		case err == nil:
+			return n, nil
+		case errors.Is(err, io.EOF):
+			if n > 0 {
+				return n, nil
+			}
+			return 0, Permanent(io.EOF)
+		}
+
+		// Reopen the stream on the next attempt or read, returning any data
+		// that was read before the failure.
+		_ = r.r.Close()
+		r.r = nil
+		if n > 0 {
+			return n, nil
+		}
+		return 0, err
+	}, r.opts...)
+}
+
+// Offset returns the number of bytes read.
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// Close closes the stream, if it is open.
+func (r *Reader) Close() error {
+	if r.r == nil {
+		return nil
+	}
+	err := r.r.Close()
+	r.r = nil
+	return err
+}
+
+// Writer writes to a stream, reopening it at the current offset when a write
+// fails with a transient error, and writing the remaining data. By default,
+// errors that are transient according to [IsTransient] and
+// [io.ErrUnexpectedEOF] are retried, which can be changed with [WithRetryIf].
+//
+// Writer is not safe for concurrent use.
+type Writer struct {
+	ctx    context.Context
+	open   OpenWriterFunc
+	opts   []Option
+	w      io.WriteCloser
+	offset int64
+}
+
+// NewWriter returns a [Writer] that opens the stream with open, starting at
+// offset zero. The stream is opened on the first write. The options configure
+// the retries of each write, see [Do].
+func NewWriter(ctx context.Context, open OpenWriterFunc, opts ...Option) *Writer {
+	return &Writer{
+		ctx:  ctx,
+		open: open,
+		opts: append([]Option{WithRetryIf(isTransientIO)}, opts...),
+	}
+}
+
+// Write implements [io.Writer].
+func (w *Writer) Write(p []byte) (int, error) {
+	written := 0
+	err := Do(w.ctx, func(ctx context.Context) error {
+		if w.w == nil {
+			wc, err := w.open(ctx, w.offset)
+			if err != nil {
+				return err
+			}
+			w.w = wc
+		}
+
+		// Streams may write less than asked without an error, so keep
+		// writing until all of p is written
+		for written < len(p) {
+			n, err := w.w.Write(p[written:])
+			written += n
+			w.offset += int64(n)
+			if err != nil {
+				_ = w.w.Close()
+				w.w = nil
+				return err
+			}
+			if n == 0 {
+				return Permanent(io.ErrShortWrite)
+			}
+		}
+		return nil
+	}, w.opts...)
+	return written, err
+}
+
+// Offset returns the number of bytes written.
+func (w *Writer) Offset() int64 {
+	return w.offset
+}
+
+// Close closes the stream, if it is open.
+func (w *Writer) Close() error {
+	if w.w == nil {
+		return nil
+	}
+	err := w.w.Close()
+	w.w = nil
+	return err
+}
+
+// RetryCopy copies from src to dst until EOF, like [io.Copy], retrying
+// transient errors from src using b. It returns the number of bytes copied.
+//
+// If src implements [io.Seeker], reading resumes from the last successful
+// offset after a failure, as with a [Reader]. Otherwise the copy is only
+// retried if it fails before any data was read. Errors from dst are not
+// retried.
+func RetryCopy(ctx context.Context, dst io.Writer, src io.Reader, b Backoff) (int64, error) {
+	rs, ok := src.(io.ReadSeeker)
+	if !ok {
+		var copied int64
+		w := permanentWriter{dst}
+		err := Do(ctx, func(context.Context) error {
+			n, err := io.Copy(w, src)
+			copied = n
+			var perm *permanentError
+			if n > 0 && !errors.As(err, &perm) {
+				// The data read from src cannot be read again
+				return Permanent(err)
+			}
+			return err
+		}, WithBackoff(b), WithRetryIf(isTransientIO))
+		return copied, err
+	}
+
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	r := NewReader(ctx, func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		return SeekReader(rs)(ctx, start+offset)
+	}, WithBackoff(b))
+	return io.Copy(dst, r)
+}
+
+// permanentWriter wraps the errors of a writer with [Permanent], so that
+// they are not retried.
+type permanentWriter struct {
+	w io.Writer
+}
+
+func (w permanentWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if err != nil {
+		return n, Permanent(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,223 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// flakyReader fails with a transient error after every n bytes.
+type flakyReader struct {
+	r     io.ReadSeeker
+	n     int
+	count int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.count >= r.n {
+		r.count = 0
+		return 0, syscall.ECONNRESET
+	}
+	p = p[:min(len(p), r.n-r.count)]
+	n, err := r.r.Read(p)
+	r.count += n
+	return n, err
+}
+
+func (r *flakyReader) Seek(offset int64, whence int) (int64, error) {
+	return r.r.Seek(offset, whence)
+}
+
+// flakyWriter fails with a transient error after every n bytes.
+type flakyWriter struct {
+	bytes.Buffer
+	n     int
+	count int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.count+len(p) > w.n {
+		p = p[:w.n-w.count]
+		n, _ := w.Buffer.Write(p)
+		w.count = 0
+		return n, syscall.EPIPE
+	}
+	w.count += len(p)
+	return w.Buffer.Write(p)
+}
+
+func (w *flakyWriter) Seek(offset int64, _ int) (int64, error) {
+	w.Truncate(int(offset))
+	return offset, nil
+}
+
+const ioTestData = "the quick brown fox jumps over the lazy dog"
+
+func TestReader(t *testing.T) {
+	src := &flakyReader{r: strings.NewReader(ioTestData), n: 5}
+	r := NewReader(context.Background(), SeekReader(src), WithBackoff(NewConstantBackoff(0)))
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(got) != ioTestData {
+		t.Errorf("ReadAll() = %q, want %q", got, ioTestData)
+	}
+	if r.Offset() != int64(len(ioTestData)) {
+		t.Errorf("Offset() = %d, want %d", r.Offset(), len(ioTestData))
+	}
+}
+
+func TestReaderPermanentError(t *testing.T) {
+	opens := 0
+	r := NewReader(context.Background(), func(context.Context, int64) (io.ReadCloser, error) {
+		opens++
+		return nil, errTest
+	}, WithBackoff(NewConstantBackoff(0)))
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, errTest) {
+		t.Errorf("Read() error = %v, want %v", err, errTest)
+	}
+	if opens != 1 {
+		t.Errorf("opens = %d, want 1", opens)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	dst := &flakyWriter{n: 7}
+	w := NewWriter(context.Background(), SeekWriter(dst), WithBackoff(NewConstantBackoff(0)))
+	defer w.Close()
+
+	n, err := io.WriteString(w, ioTestData)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if n != len(ioTestData) {
+		t.Errorf("Write() = %d, want %d", n, len(ioTestData))
+	}
+	if got := dst.String(); got != ioTestData {
+		t.Errorf("written = %q, want %q", got, ioTestData)
+	}
+}
+
+// shortWriter writes at most n bytes at a time, without an error.
+type shortWriter struct {
+	bytes.Buffer
+	n int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	return w.Buffer.Write(p[:min(len(p), w.n)])
+}
+
+func (w *shortWriter) Close() error {
+	return nil
+}
+
+func TestWriterShortWrite(t *testing.T) {
+	dst := &shortWriter{n: 3}
+	w := NewWriter(context.Background(), func(context.Context, int64) (io.WriteCloser, error) {
+		return dst, nil
+	})
+	n, err := io.WriteString(w, ioTestData)
+	if err != nil || n != len(ioTestData) || dst.String() != ioTestData {
+		t.Errorf("Write() = %d, %v, want %d, nil", n, err, len(ioTestData))
+	}
+
+	dst.n = 0
+	n, err = w.Write([]byte("more"))
+	if !errors.Is(err, io.ErrShortWrite) || n != 0 {
+		t.Errorf("Write() = %d, %v, want 0, %v", n, err, io.ErrShortWrite)
+	}
+}
+
+func TestRetryCopy(t *testing.T) {
+	src := &flakyReader{r: strings.NewReader("xx" + ioTestData), n: 6}
+	if _, err := src.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	dst := new(bytes.Buffer)
+	n, err := RetryCopy(context.Background(), dst, src, NewConstantBackoff(0))
+	if err != nil {
+		t.Fatalf("RetryCopy() error = %v", err)
+	}
+	if n != int64(len(ioTestData)) || dst.String() != ioTestData {
+		t.Errorf("RetryCopy() = %d, %q, want %d, %q", n, dst.String(), len(ioTestData), ioTestData)
+	}
+}
+
+func TestRetryCopyNotSeekable(t *testing.T) {
+	attempts := 0
+	src := readerFunc(func(p []byte) (int, error) {
+		attempts++
+		if attempts == 1 {
+			return 0, syscall.ECONNRESET
+		}
+		return 0, io.EOF
+	})
+	n, err := RetryCopy(context.Background(), io.Discard, src, NewConstantBackoff(0))
+	if err != nil || n != 0 {
+		t.Errorf("RetryCopy() = %d, %v, want 0, nil", n, err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+// failOnceWriter fails with a transient error on its first write.
+type failOnceWriter struct {
+	bytes.Buffer
+	failed bool
+}
+
+func (w *failOnceWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		w.failed = true
+		return 0, syscall.ECONNRESET
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestRetryCopyDstError(t *testing.T) {
+	src := struct{ io.Reader }{strings.NewReader(ioTestData)}
+	dst := new(failOnceWriter)
+	n, err := RetryCopy(context.Background(), dst, src, NewConstantBackoff(0))
+	if !errors.Is(err, syscall.ECONNRESET) || n != 0 {
+		t.Errorf("RetryCopy() = %d, %v, want 0, %v", n, err, syscall.ECONNRESET)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter randomly adjusts intervals returned by a [Backoff], so that clients
+// retrying at the same time spread out their attempts.
+type Jitter interface {
+	// Apply returns the jittered interval for d.
+	Apply(d time.Duration) time.Duration
+}
+
+var (
+	// NoJitter returns intervals unchanged.
+	NoJitter Jitter = noJitter{}
+
+	// FullJitter chooses a random interval between zero and the interval.
+	FullJitter Jitter = fullJitter{}
+
+	// EqualJitter keeps half of the interval, and chooses the other half
+	// randomly.
+	EqualJitter Jitter = equalJitter{}
+)
+
+// JitterWithRand returns a copy of j that uses r as its source of
+// randomness, so that the jittered intervals are reproducible. This is
+// useful for tests and simulations. Jitters not created by this package are
+// returned unchanged.
+//
+// r is not safe for concurrent use, so the returned jitter must not be used
+// concurrently.
+func JitterWithRand(j Jitter, r *rand.Rand) Jitter {
+	switch j := j.(type) {
+	case fullJitter:
+		j.r = r
+		return j
+	case equalJitter:
+		j.r = r
+		return j
+	case additiveJitter:
+		j.r = r
+		return j
+	case percentageJitter:
+		j.r = r
+		return j
+	default:
+		return j
+	}
+}
+
+type noJitter struct{}
+
+func (noJitter) Apply(d time.Duration) time.Duration {
+	return d
+}
+
+type fullJitter struct {
+	r *rand.Rand
+}
+
+func (j fullJitter) Apply(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return randN(j.r, d+1)
+}
+
+type equalJitter struct {
+	r *rand.Rand
+}
+
+func (j equalJitter) Apply(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	half := d / 2
+	return d - half + randN(j.r, half+1)
+}
+
+// AdditiveJitter returns a [Jitter] that adds a random duration between zero
+// and maxJitter to intervals.
+func AdditiveJitter(maxJitter time.Duration) Jitter {
+	return additiveJitter{max: maxJitter}
+}
+
+type additiveJitter struct {
+	max time.Duration
+	r   *rand.Rand
+}
+
+func (j additiveJitter) Apply(d time.Duration) time.Duration {
+	if d <= 0 || j.max <= 0 {
+		return d
+	}
+	return d + randN(j.r, j.max+1)
+}
+
+// PercentageJitter returns a [Jitter] that adjusts intervals by up to percent
+// percent in either direction. Percentages above 100 are treated as 100.
+func PercentageJitter(percent int) Jitter {
+	return percentageJitter{percent: min(percent, 100)}
+}
+
+type percentageJitter struct {
+	percent int
+	r       *rand.Rand
+}
+
+func (j percentageJitter) Apply(d time.Duration) time.Duration {
+	if j.percent <= 0 || d <= 0 {
+		return d
+	}
+	delta := float64(d) * float64(j.percent) / 100
+	return time.Duration(float64(d) - delta + randFloat64(j.r)*2*delta)
+}
+
+// randN returns a random duration in [0, n) from r, or from the global
+// generator if r is nil.
+func randN(r *rand.Rand, n time.Duration) time.Duration {
+	if r == nil {
+		return rand.N(n)
+	}
+	return time.Duration(r.Int64N(int64(n)))
+}
+
+// randFloat64 returns a random number in [0.0, 1.0) from r, or from the
+// global generator if r is nil.
+func randFloat64(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
+
+// jitterBackoff applies a jitter to the intervals of a backoff.
+type jitterBackoff struct {
+	b Backoff
+	j Jitter
+}
+
+// WithJitter returns a [Backoff] that applies j to the intervals returned by
+// b. [Stop] is returned unchanged.
+func WithJitter(b Backoff, j Jitter) ResettableBackoff {
+	return &jitterBackoff{b: b, j: j}
+}
+
+// Next implements [Backoff].
+func (b *jitterBackoff) Next() time.Duration {
+	next := b.b.Next()
+	if next == Stop {
+		return Stop
+	}
+	return b.j.Apply(next)
+}
+
+// Reset implements [ResettableBackoff].
+func (b *jitterBackoff) Reset() {
+	resetBackoff(b.b)
+}
+
+func (b *jitterBackoff) useClock(c Clock) {
+	useClock(b.b, c)
+}
+
+func (b *jitterBackoff) clone() (Backoff, bool) {
+	inner, ok := cloneBackoff(b.b)
+	if !ok {
+		return nil, false
+	}
+	c := *b
+	c.b = inner
+	return &c, true
+}
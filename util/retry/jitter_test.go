@@ -0,0 +1,110 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	const d = time.Second
+	tests := []struct {
+		name     string
+		jitter   Jitter
+		min, max time.Duration
+	}{
+		{name: "none", jitter: NoJitter, min: d, max: d},
+		{name: "full", jitter: FullJitter, min: 0, max: d},
+		{name: "equal", jitter: EqualJitter, min: d / 2, max: d},
+		{name: "additive", jitter: AdditiveJitter(d / 4), min: d, max: d + d/4},
+		{name: "percentage", jitter: PercentageJitter(10), min: d - d/10, max: d + d/10},
+		{name: "percentage over 100", jitter: PercentageJitter(200), min: 0, max: 2 * d},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := tt.jitter.Apply(d); got < tt.min || got > tt.max {
+					t.Fatalf("Apply(%v) = %v, want between %v and %v", d, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	b := WithJitter(LimitRetries(NewConstantBackoff(time.Second), 1), FullJitter)
+	if got := b.Next(); got < 0 || got > time.Second {
+		t.Errorf("Next() = %v, want between 0 and %v", got, time.Second)
+	}
+	if got := b.Next(); got != Stop {
+		t.Errorf("Next() = %v, want Stop", got)
+	}
+}
+
+func TestExponentialBackoffJitterPercent(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval: time.Second,
+		Multiplier:      1,
+		JitterPercent:   20,
+	}
+	for i := 0; i < 100; i++ {
+		if got := b.Next(); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("Next() = %v, want within 20%% of %v", got, time.Second)
+		}
+	}
+}
+
+func TestJitterWithRand(t *testing.T) {
+	for _, j := range []Jitter{FullJitter, EqualJitter, AdditiveJitter(time.Second), PercentageJitter(50)} {
+		apply := func() []time.Duration {
+			j := JitterWithRand(j, rand.New(rand.NewPCG(1, 2)))
+			intervals := make([]time.Duration, 10)
+			for i := range intervals {
+				intervals[i] = j.Apply(time.Second)
+			}
+			return intervals
+		}
+		if a, b := apply(), apply(); !slices.Equal(a, b) {
+			t.Errorf("%T: intervals differ with the same seed: %v, %v", j, a, b)
+		}
+	}
+}
+
+func TestExponentialBackoffRand(t *testing.T) {
+	schedule := func() []time.Duration {
+		return Schedule(&ExponentialBackoff{
+			InitialInterval: time.Second,
+			Multiplier:      2,
+			JitterPercent:   50,
+			Rand:            rand.New(rand.NewPCG(1, 2)),
+		}, 10)
+	}
+	if a, b := schedule(), schedule(); !slices.Equal(a, b) {
+		t.Errorf("intervals differ with the same seed: %v, %v", a, b)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// LinearBackoff increases the interval between attempts by a constant
+// increment, up to a maximum interval.
+//
+// LinearBackoff is not safe for concurrent use.
+type LinearBackoff struct {
+	// InitialInterval is the interval before the first retry.
+	InitialInterval time.Duration
+
+	// Increment is added to the interval after each attempt.
+	Increment time.Duration
+
+	// MaxInterval caps the interval between attempts. If zero, the interval
+	// is not capped.
+	MaxInterval time.Duration
+
+	// MaxRetries is the number of retries after which Next returns [Stop].
+	// If zero, the number of retries is not limited.
+	MaxRetries int
+
+	// JitterPercent randomly adjusts each interval by up to the given
+	// percentage in either direction. If zero, intervals are not jittered.
+	JitterPercent int
+
+	// Rand is the source of randomness for jitter, as for
+	// [ExponentialBackoff.Rand].
+	Rand *rand.Rand
+
+	current time.Duration
+	started bool
+	retries int
+}
+
+// NewLinearBackoff returns a [LinearBackoff] with the given initial interval
+// and increment, capped at [DefaultMaxInterval].
+func NewLinearBackoff(initial, increment time.Duration) *LinearBackoff {
+	return &LinearBackoff{
+		InitialInterval: initial,
+		Increment:       increment,
+		MaxInterval:     DefaultMaxInterval,
+	}
+}
+
+// Next implements [Backoff].
+func (b *LinearBackoff) Next() time.Duration {
+	if b.MaxRetries > 0 && b.retries >= b.MaxRetries {
+		return Stop
+	}
+	b.retries++
+	if !b.started {
+		b.current = b.InitialInterval
+		b.started = true
+	} else {
+		b.current += b.Increment
+	}
+	if b.MaxInterval > 0 && b.current > b.MaxInterval {
+		b.current = b.MaxInterval
+	}
+	return percentageJitter{min(b.JitterPercent, 100), b.Rand}.Apply(b.current)
+}
+
+// Reset restarts the backoff from the initial interval.
+func (b *LinearBackoff) Reset() {
+	b.current = 0
+	b.started = false
+	b.retries = 0
+}
+
+// Clone implements [CloneableBackoff].
+func (b *LinearBackoff) Clone() Backoff {
+	c := *b
+	return &c
+}
@@ -0,0 +1,57 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"time"
+)
+
+// throttledNotify returns the notify function for a retry loop, which skips
+// notifications according to [WithNotifyEvery] and [WithNotifyMinInterval].
+func (c *config) throttledNotify(clock Clock) NotifyAttempt {
+	if c.notify == nil || (c.notifyEvery <= 1 && c.notifyInterval <= 0) {
+		return c.notify
+	}
+
+	var calls, suppressed int
+	var last time.Time
+	return func(err error, attempt int, next time.Duration) {
+		now := clock.Now()
+		deliver := calls%max(c.notifyEvery, 1) == 0 &&
+			(last.IsZero() || now.Sub(last) >= c.notifyInterval)
+		calls++
+		if !deliver {
+			suppressed++
+			return
+		}
+
+		if suppressed > 0 {
+			err = &SuppressedError{Err: err, Suppressed: suppressed}
+			suppressed = 0
+		}
+		last = now
+		c.notify(err, attempt, next)
+	}
+}
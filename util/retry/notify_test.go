@@ -0,0 +1,92 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWithNotifyEvery(t *testing.T) {
+	var attempts, suppressed []int
+	_ = Do(context.Background(), func(context.Context) error {
+		return errTest
+	},
+		WithBackoff(NewConstantBackoff(0)),
+		WithMaxRetries(7),
+		WithNotifyEvery(3),
+		WithNotifyAttempt(func(err error, attempt int, _ time.Duration) {
+			if !errors.Is(err, errTest) {
+				t.Errorf("err = %v, want %v", err, errTest)
+			}
+			n := 0
+			var s *SuppressedError
+			if errors.As(err, &s) {
+				n = s.Suppressed
+			}
+			attempts = append(attempts, attempt)
+			suppressed = append(suppressed, n)
+		}),
+	)
+
+	if want := []int{1, 4, 7}; !slices.Equal(attempts, want) {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+	if want := []int{0, 2, 2}; !slices.Equal(suppressed, want) {
+		t.Errorf("suppressed = %v, want %v", suppressed, want)
+	}
+}
+
+func TestWithNotifyMinInterval(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	var attempts, suppressed []int
+	c := newConfig([]Option{
+		WithNotifyMinInterval(time.Minute),
+		WithNotifyAttempt(func(err error, attempt int, _ time.Duration) {
+			n := 0
+			var s *SuppressedError
+			if errors.As(err, &s) {
+				n = s.Suppressed
+			}
+			attempts = append(attempts, attempt)
+			suppressed = append(suppressed, n)
+		}),
+	})
+	notify := c.throttledNotify(clock)
+	for i := 1; i <= 6; i++ {
+		notify(errTest, i, 0)
+		clock.Advance(25 * time.Second)
+	}
+
+	if want := []int{1, 4}; !slices.Equal(attempts, want) {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+	if want := []int{0, 2}; !slices.Equal(suppressed, want) {
+		t.Errorf("suppressed = %v, want %v", suppressed, want)
+	}
+}
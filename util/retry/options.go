@@ -0,0 +1,282 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"time"
+)
+
+// Option configures the behaviour of [Do] and [DoValue].
+type Option func(c *config)
+
+// config is the configuration of a retry loop.
+type config struct {
+	backoff           Backoff
+	newBackoff        BackoffFactory
+	maxRetries        int
+	notify            NotifyAttempt
+	notifyEvery       int
+	notifyInterval    time.Duration
+	perAttemptTimeout time.Duration
+	splitDeadline     bool
+	splitAttempts     int
+	retryIf           func(err error) bool
+
+	lastErrorOnDeadline bool
+	aggregate           bool
+	aggregateLimit      int
+	clock               Clock
+	budget              *Budget
+	breaker             Breaker
+	onGiveUp            func(err error, attempts int, elapsed time.Duration)
+	onSuccess           func(attempts int, elapsed time.Duration)
+	concurrency         int
+}
+
+// newConfig returns the configuration with the given options applied.
+func newConfig(opts []Option) *config {
+	c := &config{maxRetries: -1}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.newBackoff != nil {
+		c.backoff = c.newBackoff()
+	}
+	if c.backoff == nil {
+		c.backoff = NewExponentialBackoff()
+	}
+	if c.maxRetries >= 0 {
+		c.backoff = LimitRetries(c.backoff, c.maxRetries)
+		if c.splitDeadline && c.splitAttempts <= 0 {
+			c.splitAttempts = c.maxRetries + 1
+		}
+	}
+	return c
+}
+
+// WithBackoff sets the backoff used between attempts. Defaults to the
+// backoff returned by [NewExponentialBackoff]. It replaces any backoff set
+// with [WithBackoffFactory].
+func WithBackoff(b Backoff) Option {
+	return func(c *config) {
+		c.backoff = b
+		c.newBackoff = nil
+	}
+}
+
+// WithBackoffFactory sets a function that returns a new backoff for each
+// operation, which is needed when options are reused by concurrent
+// operations, such as by a [Retrier]. It replaces any backoff set with
+// [WithBackoff].
+func WithBackoffFactory(f BackoffFactory) Option {
+	return func(c *config) {
+		c.newBackoff = f
+		c.backoff = nil
+	}
+}
+
+// WithBackoffN sets a stateless backoff used between attempts, see [FromN].
+// It replaces any backoff set with [WithBackoff].
+func WithBackoffN(b BackoffN) Option {
+	return func(c *config) {
+		c.backoff = FromN(b)
+		c.newBackoff = nil
+	}
+}
+
+// WithMaxRetries limits the number of retries, like [LimitRetries]. The
+// operation is attempted at most n+1 times, so WithMaxRetries(n) is the same
+// as [WithMaxAttempts](n+1).
+func WithMaxRetries(n int) Option {
+	return func(c *config) {
+		c.maxRetries = max(n, 0)
+	}
+}
+
+// WithMaxAttempts limits the number of attempts, including the first. The
+// operation is attempted at most n times, so WithMaxAttempts(n) is the same
+// as [WithMaxRetries](n-1). Values below 1 are treated as 1.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxRetries = max(n-1, 0)
+	}
+}
+
+// WithNotify sets a function that is called after each failed attempt that
+// will be retried. It replaces any function set by [WithNotifyAttempt].
+func WithNotify(notify Notify) Option {
+	return func(c *config) {
+		if notify == nil {
+			c.notify = nil
+			return
+		}
+		c.notify = func(err error, _ int, next time.Duration) {
+			notify(err, next)
+		}
+	}
+}
+
+// WithNotifyAttempt sets a function that is called after each failed attempt
+// that will be retried, with the number of the attempt that failed. It
+// replaces any function set by [WithNotify].
+func WithNotifyAttempt(notify NotifyAttempt) Option {
+	return func(c *config) {
+		c.notify = notify
+	}
+}
+
+// WithNotifyEvery only calls the notify function for every nth failed
+// attempt, starting with the first. The number of notifications that were
+// skipped is reported with the next one that is delivered, see
+// [SuppressedError].
+func WithNotifyEvery(n int) Option {
+	return func(c *config) {
+		c.notifyEvery = n
+	}
+}
+
+// WithNotifyMinInterval only calls the notify function if at least d has
+// passed since it was last called, measured with the clock set by
+// [WithClock]. The number of notifications that were skipped is reported
+// with the next one that is delivered, see [SuppressedError].
+func WithNotifyMinInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.notifyInterval = d
+	}
+}
+
+// WithPerAttemptTimeout sets a timeout for each attempt. The context passed
+// to the operation is cancelled when the timeout expires, but the operation
+// is still retried. If zero, attempts are only limited by the context.
+func WithPerAttemptTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.perAttemptTimeout = d
+	}
+}
+
+// WithDeadlineSplit divides the time remaining until the context's deadline
+// between the attempts that are expected to remain, so that every attempt
+// gets a fair share of the time instead of the first attempt using all of
+// it. Attempt n of attempts is given a timeout of the remaining time divided
+// by attempts-n+1, and the last attempt is given all of the remaining time.
+// The timeout is combined with [WithPerAttemptTimeout], using the shorter of
+// the two.
+//
+// If attempts is zero or negative, the number of attempts set with
+// [WithMaxRetries] or [WithMaxAttempts] is used. If neither is set, or the
+// context has no deadline, attempts are not limited by this option.
+func WithDeadlineSplit(attempts int) Option {
+	return func(c *config) {
+		c.splitDeadline = true
+		c.splitAttempts = attempts
+	}
+}
+
+// WithRetryIf sets a function that decides whether an error is retried. If
+// it returns false, the error is returned without further attempts, as if it
+// had been wrapped with [Permanent]. By default, all errors are retried.
+func WithRetryIf(retryIf func(err error) bool) Option {
+	return func(c *config) {
+		c.retryIf = retryIf
+	}
+}
+
+// WithLastErrorOnDeadline returns the last error from the operation instead
+// of [context.DeadlineExceeded] when the context's deadline is exceeded, or
+// would be exceeded before the next attempt.
+func WithLastErrorOnDeadline() Option {
+	return func(c *config) {
+		c.lastErrorOnDeadline = true
+	}
+}
+
+// WithErrorAggregation returns the errors from all attempts, joined with
+// [errors.Join], instead of only the error from the last attempt. At most
+// limit of the most recent errors are kept. If limit is zero or negative,
+// all errors are kept.
+//
+// If the context is done, its error is joined with the errors from the
+// attempts.
+func WithErrorAggregation(limit int) Option {
+	return func(c *config) {
+		c.aggregate = true
+		c.aggregateLimit = limit
+	}
+}
+
+// WithClock sets the clock used to wait between attempts and to measure the
+// elapsed time. The clock is also used by backoffs that measure the elapsed
+// time, unless they have their own clock. Defaults to [SystemClock].
+func WithClock(c Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// WithBudget limits retries using a [Budget] shared with other operations.
+// If the budget does not allow a retry, the operation fails immediately with
+// an error wrapping [ErrBudgetExhausted] and the last error from the
+// operation. Successful operations deposit tokens to the budget.
+func WithBudget(b *Budget) Option {
+	return func(c *config) {
+		c.budget = b
+	}
+}
+
+// WithBreaker checks b before each attempt, and reports the result of the
+// attempt to it. If b rejects an attempt, its error is returned without
+// further attempts, as if it had been wrapped with [Permanent].
+func WithBreaker(b Breaker) Option {
+	return func(c *config) {
+		c.breaker = b
+	}
+}
+
+// WithOnGiveUp sets a function that is called when the operation fails
+// without being retried again, for any reason. It is given the error that
+// is returned, the number of attempts made and the time since the first
+// attempt started.
+func WithOnGiveUp(fn func(err error, attempts int, elapsed time.Duration)) Option {
+	return func(c *config) {
+		c.onGiveUp = fn
+	}
+}
+
+// WithOnSuccess sets a function that is called when the operation succeeds.
+// It is given the number of attempts made and the time since the first
+// attempt started.
+func WithOnSuccess(fn func(attempts int, elapsed time.Duration)) Option {
+	return func(c *config) {
+		c.onSuccess = fn
+	}
+}
+
+// WithConcurrency sets the maximum number of items that are processed
+// concurrently by [Each]. Defaults to 1. It has no effect on other functions.
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
@@ -0,0 +1,243 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Strategy is the name of a backoff strategy used by a [Policy].
+type Strategy string
+
+// Strategies supported by [Policy].
+const (
+	StrategyConstant           Strategy = "constant"
+	StrategyExponential        Strategy = "exponential"
+	StrategyLinear             Strategy = "linear"
+	StrategyFibonacci          Strategy = "fibonacci"
+	StrategyDecorrelatedJitter Strategy = "decorrelated-jitter"
+)
+
+// Duration is a [time.Duration] that is marshalled as text in the format
+// used by [time.ParseDuration], e.g. "1.5s", so that it can be used in JSON,
+// YAML and other configuration files.
+type Duration time.Duration
+
+// MarshalText implements [encoding.TextMarshaler].
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Policy is a declarative description of a backoff, which can be loaded from
+// configuration files using encoding/json or a YAML package, or from
+// environment variables with [PolicyFromEnv]. Zero intervals and
+// multipliers use the defaults of [NewExponentialBackoff], while zero limits
+// disable the limit.
+type Policy struct {
+	// Strategy is the backoff strategy. Defaults to [StrategyExponential].
+	Strategy Strategy `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+
+	// InitialInterval is the interval before the first retry, or the base
+	// interval of [StrategyDecorrelatedJitter].
+	InitialInterval Duration `json:"initialInterval,omitempty" yaml:"initialInterval,omitempty"`
+
+	// MaxInterval caps the interval between attempts.
+	MaxInterval Duration `json:"maxInterval,omitempty" yaml:"maxInterval,omitempty"`
+
+	// Multiplier is the factor used by [StrategyExponential].
+	Multiplier float64 `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+
+	// Increment is the increment used by [StrategyLinear]. Defaults to the
+	// initial interval.
+	Increment Duration `json:"increment,omitempty" yaml:"increment,omitempty"`
+
+	// JitterPercent randomly adjusts each interval by up to the given
+	// percentage in either direction. It is ignored by
+	// [StrategyDecorrelatedJitter], which is always random.
+	JitterPercent int `json:"jitterPercent,omitempty" yaml:"jitterPercent,omitempty"`
+
+	// MaxRetries limits the number of retries. If zero, the number of
+	// retries is not limited.
+	MaxRetries int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+
+	// MaxElapsedTime is the time after which no more retries are made. If
+	// zero, the elapsed time is not limited, unlike the default of
+	// [NewExponentialBackoff].
+	MaxElapsedTime Duration `json:"maxElapsedTime,omitempty" yaml:"maxElapsedTime,omitempty"`
+}
+
+// Backoff returns a new backoff as described by the policy. An error is
+// returned if the strategy is unknown or a value is invalid.
+//
+// nolint: cyclop
+func (p Policy) Backoff() (Backoff, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	initial := durationOr(p.InitialInterval, DefaultInitialInterval)
+	maxInterval := durationOr(p.MaxInterval, DefaultMaxInterval)
+
+	var b Backoff
+	switch p.Strategy {
+	case "", StrategyExponential:
+		multiplier := p.Multiplier
+		if multiplier == 0 {
+			multiplier = DefaultMultiplier
+		}
+		b = &ExponentialBackoff{
+			InitialInterval: initial,
+			MaxInterval:     maxInterval,
+			Multiplier:      multiplier,
+			JitterPercent:   p.JitterPercent,
+		}
+	case StrategyConstant:
+		b = WithJitter(NewConstantBackoff(initial), PercentageJitter(p.JitterPercent))
+	case StrategyLinear:
+		b = &LinearBackoff{
+			InitialInterval: initial,
+			Increment:       durationOr(p.Increment, initial),
+			MaxInterval:     maxInterval,
+			JitterPercent:   p.JitterPercent,
+		}
+	case StrategyFibonacci:
+		b = &FibonacciBackoff{
+			InitialInterval: initial,
+			MaxInterval:     maxInterval,
+			JitterPercent:   p.JitterPercent,
+		}
+	case StrategyDecorrelatedJitter:
+		b = NewDecorrelatedJitterBackoff(initial, maxInterval)
+	default:
+		return nil, fmt.Errorf("retry: unknown strategy %q", p.Strategy)
+	}
+
+	if p.MaxElapsedTime > 0 {
+		b = WithMaxElapsedTime(b, time.Duration(p.MaxElapsedTime))
+	}
+	if p.MaxRetries > 0 {
+		b = LimitRetries(b, p.MaxRetries)
+	}
+	return b, nil
+}
+
+// validate returns an error if a value of the policy is invalid.
+func (p Policy) validate() error {
+	var errs []error
+	if p.InitialInterval < 0 || p.MaxInterval < 0 || p.Increment < 0 || p.MaxElapsedTime < 0 {
+		errs = append(errs, errors.New("retry: intervals must not be negative"))
+	}
+	if p.Multiplier < 0 {
+		errs = append(errs, errors.New("retry: multiplier must not be negative"))
+	}
+	if p.JitterPercent < 0 || p.JitterPercent > 100 {
+		errs = append(errs, errors.New("retry: jitter percent must be between 0 and 100"))
+	}
+	if p.MaxRetries < 0 {
+		errs = append(errs, errors.New("retry: max retries must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// durationOr returns d, or def if d is zero.
+func durationOr(d Duration, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return time.Duration(d)
+}
+
+// Environment variable suffixes used by [PolicyFromEnv].
+const (
+	EnvStrategy        = "STRATEGY"
+	EnvInitialInterval = "INITIAL_INTERVAL"
+	EnvMaxInterval     = "MAX_INTERVAL"
+	EnvMultiplier      = "MULTIPLIER"
+	EnvIncrement       = "INCREMENT"
+	EnvJitterPercent   = "JITTER_PERCENT"
+	EnvMaxRetries      = "MAX_RETRIES"
+	EnvMaxElapsedTime  = "MAX_ELAPSED_TIME"
+)
+
+// PolicyFromEnv returns a [Policy] loaded from environment variables, named
+// by prefix followed by one of the Env suffixes, e.g. "RETRY_MAX_RETRIES"
+// for the prefix "RETRY_". Unset variables are left as zero values.
+func PolicyFromEnv(prefix string) (Policy, error) {
+	var p Policy
+	var errs []error
+	lookup := func(name string, parse func(v string) error) {
+		if v, ok := os.LookupEnv(prefix + name); ok && v != "" {
+			if err := parse(v); err != nil {
+				errs = append(errs, fmt.Errorf("retry: invalid %s: %w", prefix+name, err))
+			}
+		}
+	}
+
+	lookup(EnvStrategy, func(v string) error {
+		p.Strategy = Strategy(v)
+		return nil
+	})
+	lookup(EnvInitialInterval, parseDuration(&p.InitialInterval))
+	lookup(EnvMaxInterval, parseDuration(&p.MaxInterval))
+	lookup(EnvMultiplier, func(v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		p.Multiplier = f
+		return err
+	})
+	lookup(EnvIncrement, parseDuration(&p.Increment))
+	lookup(EnvJitterPercent, parseInt(&p.JitterPercent))
+	lookup(EnvMaxRetries, parseInt(&p.MaxRetries))
+	lookup(EnvMaxElapsedTime, parseDuration(&p.MaxElapsedTime))
+	return p, errors.Join(errs...)
+}
+
+// parseDuration returns a function that parses a duration into d.
+func parseDuration(d *Duration) func(v string) error {
+	return func(v string) error {
+		return d.UnmarshalText([]byte(v))
+	}
+}
+
+// parseInt returns a function that parses an integer into i.
+func parseInt(i *int) func(v string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		*i = n
+		return err
+	}
+}
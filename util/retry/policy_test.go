@@ -0,0 +1,115 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPolicyJSON(t *testing.T) {
+	var p Policy
+	err := json.Unmarshal([]byte(`{
+		"strategy": "linear",
+		"initialInterval": "100ms",
+		"increment": "50ms",
+		"maxInterval": "200ms",
+		"maxRetries": 3
+	}`), &p)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() = %v", err)
+	}
+
+	b, err := p.Backoff()
+	if err != nil {
+		t.Fatalf("Backoff() = %v", err)
+	}
+	want := []time.Duration{100 * time.Millisecond, 150 * time.Millisecond, 200 * time.Millisecond, Stop}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Errorf("Next() #%d = %v, want %v", i, got, w)
+		}
+	}
+
+	out, err := json.Marshal(Policy{Strategy: StrategyConstant, InitialInterval: Duration(time.Second)})
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	if got, want := string(out), `{"strategy":"constant","initialInterval":"1s"}`; got != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		policy  Policy
+		wantErr bool
+	}{
+		{policy: Policy{}},
+		{policy: Policy{Strategy: StrategyConstant}},
+		{policy: Policy{Strategy: StrategyExponential, Multiplier: 2}},
+		{policy: Policy{Strategy: StrategyFibonacci, MaxElapsedTime: Duration(time.Minute)}},
+		{policy: Policy{Strategy: StrategyDecorrelatedJitter}},
+		{policy: Policy{Strategy: "random"}, wantErr: true},
+		{policy: Policy{InitialInterval: Duration(-time.Second)}, wantErr: true},
+		{policy: Policy{JitterPercent: 101}, wantErr: true},
+	}
+	for _, tt := range tests {
+		b, err := tt.policy.Backoff()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v: Backoff() error = %v, want error %v", tt.policy, err, tt.wantErr)
+			continue
+		}
+		if err == nil && b.Next() <= 0 {
+			t.Errorf("%+v: Next() is not positive", tt.policy)
+		}
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv("RETRY_STRATEGY", "exponential")
+	t.Setenv("RETRY_INITIAL_INTERVAL", "1s")
+	t.Setenv("RETRY_MULTIPLIER", "3")
+	t.Setenv("RETRY_MAX_RETRIES", "5")
+	p, err := PolicyFromEnv("RETRY_")
+	if err != nil {
+		t.Fatalf("PolicyFromEnv() = %v", err)
+	}
+	want := Policy{
+		Strategy:        StrategyExponential,
+		InitialInterval: Duration(time.Second),
+		Multiplier:      3,
+		MaxRetries:      5,
+	}
+	if p != want {
+		t.Errorf("PolicyFromEnv() = %+v, want %+v", p, want)
+	}
+
+	t.Setenv("RETRY_MAX_INTERVAL", "forever")
+	if _, err := PolicyFromEnv("RETRY_"); err == nil {
+		t.Error("PolicyFromEnv() = nil, want error")
+	}
+}
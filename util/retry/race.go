@@ -0,0 +1,102 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoFunctions is returned by [Race] and [RaceValue] when they are called
+// without functions.
+var ErrNoFunctions = errors.New("retry: race without functions")
+
+// Race calls every function concurrently, for example to send the same
+// request to multiple targets. The first successful result is returned and
+// the contexts of the other functions are cancelled, without waiting for
+// them to return. If all functions fail, their errors are joined with
+// [errors.Join], and if there are no functions, [ErrNoFunctions] is
+// returned. The number of each function, starting at 1, is available
+// from its context with [Attempt].
+func Race(ctx context.Context, fs ...Retryable) error {
+	ops := make([]func(ctx context.Context) (struct{}, error), len(fs))
+	for i, f := range fs {
+		ops[i] = func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, f(ctx)
+		}
+	}
+	_, err := RaceValue(ctx, ops...)
+	return err
+}
+
+// RaceReplicas is like [Race], but calls op n times concurrently, with the
+// number of the replica starting at 0.
+func RaceReplicas(ctx context.Context, n int, op func(ctx context.Context, replica int) error) error {
+	fs := make([]Retryable, max(n, 0))
+	for i := range fs {
+		fs[i] = func(ctx context.Context) error {
+			return op(ctx, i)
+		}
+	}
+	return Race(ctx, fs...)
+}
+
+// RaceValue is like [Race], but for functions that return a value. The value
+// from the first successful function is returned.
+func RaceValue[T any](ctx context.Context, fs ...func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if len(fs) == 0 {
+		return zero, ErrNoFunctions
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+	results := make(chan result, len(fs))
+	for i, f := range fs {
+		fctx := context.WithValue(ctx, attemptKey{}, i+1)
+		go func() {
+			v, err := f(fctx)
+			results <- result{v: v, err: err}
+		}()
+	}
+
+	errs := make([]error, 0, len(fs))
+	for len(errs) < len(fs) {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.v, nil
+			}
+			errs = append(errs, r.err)
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+	return zero, errors.Join(errs...)
+}
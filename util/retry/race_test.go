@@ -0,0 +1,106 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRace(t *testing.T) {
+	cancelled := make(chan struct{})
+	v, err := RaceValue(context.Background(),
+		func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return 0, ctx.Err()
+		},
+		func(context.Context) (int, error) {
+			return 0, errTest
+		},
+		func(ctx context.Context) (int, error) {
+			return Attempt(ctx), nil
+		},
+	)
+	if err != nil || v != 3 {
+		t.Errorf("RaceValue() = %v, %v, want 3, nil", v, err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("slow function was not cancelled")
+	}
+}
+
+func TestRaceAllFail(t *testing.T) {
+	errOther := errors.New("other error")
+	err := Race(context.Background(),
+		func(context.Context) error { return errTest },
+		func(context.Context) error { return errOther },
+	)
+	if !errors.Is(err, errTest) || !errors.Is(err, errOther) {
+		t.Errorf("Race() error = %v, want both errors", err)
+	}
+}
+
+func TestRaceNoFunctions(t *testing.T) {
+	if err := Race(context.Background()); !errors.Is(err, ErrNoFunctions) {
+		t.Errorf("Race() error = %v, want %v", err, ErrNoFunctions)
+	}
+	if err := RaceReplicas(context.Background(), 0, nil); !errors.Is(err, ErrNoFunctions) {
+		t.Errorf("RaceReplicas(0) error = %v, want %v", err, ErrNoFunctions)
+	}
+}
+
+func TestRaceReplicas(t *testing.T) {
+	replicas := make(chan int, 3)
+	err := RaceReplicas(context.Background(), 3, func(_ context.Context, replica int) error {
+		replicas <- replica
+		if replica == 2 {
+			return nil
+		}
+		return errTest
+	})
+	if err != nil {
+		t.Errorf("RaceReplicas() error = %v", err)
+	}
+	if r := <-replicas; r < 0 || r > 2 {
+		t.Errorf("replica = %d, want between 0 and 2", r)
+	}
+}
+
+func TestRaceContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Race(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return errTest
+	})
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, errTest) {
+		t.Errorf("Race() error = %v, want %v", err, context.Canceled)
+	}
+}
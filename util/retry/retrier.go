@@ -0,0 +1,83 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+)
+
+// BackoffFactory returns a new [Backoff] for each operation.
+type BackoffFactory func() Backoff
+
+// Retrier retries operations using options that are configured once, so
+// that the same policy can be used in many places. A Retrier is safe for
+// concurrent use if its options are. A backoff set with [WithBackoff] is
+// cloned for each operation if it is a [CloneableBackoff], and other
+// backoffs should be set with [WithBackoffFactory] instead.
+type Retrier struct {
+	opts []Option
+}
+
+// NewRetrier returns a [Retrier] using the given options.
+func NewRetrier(opts ...Option) *Retrier {
+	return &Retrier{opts: opts}
+}
+
+// Run is like [Do], using the options of the retrier followed by opts.
+func (r *Retrier) Run(ctx context.Context, op Retryable, opts ...Option) error {
+	return Do(ctx, op, r.options(opts)...)
+}
+
+// RunValue is like [DoValue], using the options of the retrier followed by
+// opts.
+func RunValue[T any](
+	ctx context.Context,
+	r *Retrier,
+	op func(ctx context.Context) (T, error),
+	opts ...Option,
+) (T, error) {
+	return DoValue(ctx, op, r.options(opts)...)
+}
+
+// options returns the options of the retrier followed by opts. The backoff
+// set by the options of the retrier is cloned, so that it is not shared by
+// concurrent operations.
+func (r *Retrier) options(opts []Option) []Option {
+	o := make([]Option, 0, len(r.opts)+1+len(opts))
+	o = append(o, r.opts...)
+	o = append(o, cloneConfigBackoff)
+	return append(o, opts...)
+}
+
+// cloneConfigBackoff replaces the backoff of c with a copy, if it can be
+// cloned.
+func cloneConfigBackoff(c *config) {
+	if c.backoff == nil {
+		return
+	}
+	if b, ok := cloneBackoff(c.backoff); ok {
+		c.backoff = b
+	}
+}
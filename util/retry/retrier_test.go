@@ -0,0 +1,105 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetrier(t *testing.T) {
+	r := NewRetrier(
+		WithBackoffFactory(func() Backoff { return NewLinearBackoff(time.Millisecond, time.Millisecond) }),
+		WithMaxRetries(2),
+		WithRetryIf(func(err error) bool { return errors.Is(err, errTest) }),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := RunValue(context.Background(), r, func(ctx context.Context) (int, error) {
+				if Attempt(ctx) < 3 {
+					return 0, errTest
+				}
+				return Attempt(ctx), nil
+			})
+			if err != nil || v != 3 {
+				t.Errorf("RunValue() = %d, %v, want 3, nil", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	errOther := errors.New("other error")
+	calls := 0
+	err := r.Run(context.Background(), func(context.Context) error {
+		calls++
+		return errOther
+	})
+	if !errors.Is(err, errOther) || calls != 1 {
+		t.Errorf("Run() = %v after %d calls, want %v after 1 call", err, calls, errOther)
+	}
+
+	calls = 0
+	_ = r.Run(context.Background(), func(context.Context) error {
+		calls++
+		return errTest
+	}, WithMaxRetries(0))
+	if calls != 1 {
+		t.Errorf("calls = %d with WithMaxRetries(0), want 1", calls)
+	}
+}
+
+func TestRetrierSharedBackoff(t *testing.T) {
+	b := NewLinearBackoff(time.Millisecond, time.Millisecond)
+	b.MaxRetries = 2
+	r := NewRetrier(WithBackoff(b))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attempts := 0
+			err := r.Run(context.Background(), func(context.Context) error {
+				attempts++
+				return errTest
+			})
+			if !errors.Is(err, errTest) || attempts != 3 {
+				t.Errorf("Run() = %v after %d attempts, want %v after 3 attempts", err, attempts, errTest)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := b.Next(); got != time.Millisecond {
+		t.Errorf("backoff Next() = %v after runs, want %v", got, time.Millisecond)
+	}
+}
@@ -0,0 +1,316 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package retry implements retrying of operations with configurable backoff
+strategies.
+
+	err := retry.Do(ctx, op,
+		retry.WithBackoff(retry.NewExponentialBackoff()),
+		retry.WithMaxRetries(5),
+	)
+
+Operations can stop being retried by returning an error wrapped with
+[Permanent], and the behaviour of the retry loop is configured with
+[Option] values.
+*/
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Retryable is an operation that can be retried.
+type Retryable func(ctx context.Context) error
+
+// Notify is called after an operation fails, before waiting for the next
+// attempt. It is given the error returned by the operation and the time
+// until the next attempt.
+type Notify func(err error, next time.Duration)
+
+// NotifyAttempt is like [Notify], but is also given the number of the attempt
+// that failed, starting at 1.
+type NotifyAttempt func(err error, attempt int, next time.Duration)
+
+// attemptKey is the context key for the attempt number.
+type attemptKey struct{}
+
+// Attempt returns the number of the current attempt, starting at 1, from a
+// context passed to an operation by a retry function. Attempt returns 0 if
+// the context was not passed by a retry function.
+func Attempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+// permanentError wraps an error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err to signal that the operation should not be retried.
+// The retry functions return the wrapped error. Permanent returns nil if err
+// is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do calls op until it succeeds, returns an error wrapped with [Permanent],
+// the backoff returns [Stop], or the context is done.
+//
+// If the backoff stops, an [ExhaustedError] wrapping the last error returned
+// by op is returned. If the context is done, the context's error is returned.
+// If the context has a deadline that would expire before the next attempt,
+// Do returns [context.DeadlineExceeded] without waiting.
+//
+// If an error has a RetryAfter() time.Duration method returning a positive
+// duration, such as [StatusError], that duration is waited instead of the
+// interval from the backoff.
+func Do(ctx context.Context, op Retryable, opts ...Option) error {
+	_, err := retry(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	}, newConfig(opts))
+	return err
+}
+
+// DoValue is like [Do], but for operations that return a value. The value
+// from the first successful attempt is returned.
+func DoValue[T any](ctx context.Context, op func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	return retry(ctx, op, newConfig(opts))
+}
+
+// Retry is like [Do], using the given backoff.
+func Retry(ctx context.Context, op Retryable, b Backoff) error {
+	return Do(ctx, op, WithBackoff(b))
+}
+
+// RetryNotify is like [Retry], but calls notify after each failed attempt
+// that will be retried. A nil notify is ignored.
+func RetryNotify(ctx context.Context, op Retryable, b Backoff, notify Notify) error {
+	return Do(ctx, op, WithBackoff(b), WithNotify(notify))
+}
+
+// RetryNotifyAttempt is like [RetryNotify], but calls a [NotifyAttempt]
+// after each failed attempt that will be retried.
+func RetryNotifyAttempt(ctx context.Context, op Retryable, b Backoff, notify NotifyAttempt) error {
+	return Do(ctx, op, WithBackoff(b), WithNotifyAttempt(notify))
+}
+
+// RetryValue is like [DoValue], using the given backoff.
+func RetryValue[T any](ctx context.Context, op func(ctx context.Context) (T, error), b Backoff) (T, error) {
+	return DoValue(ctx, op, WithBackoff(b))
+}
+
+// RetryValueNotify is like [RetryValue], but calls notify after each failed
+// attempt that will be retried. A nil notify is ignored.
+func RetryValueNotify[T any](
+	ctx context.Context,
+	op func(ctx context.Context) (T, error),
+	b Backoff,
+	notify Notify,
+) (T, error) {
+	return DoValue(ctx, op, WithBackoff(b), WithNotify(notify))
+}
+
+// retry runs the retry loop shared by all retry functions, and calls the
+// hooks once it finishes.
+func retry[T any](ctx context.Context, op func(ctx context.Context) (T, error), c *config) (T, error) {
+	clock := clockOrSystem(c.clock)
+	// The clock is only used by the backoff during this run, so that it
+	// does not leak into later uses of the backoff.
+	useClock(c.backoff, clock)
+	defer useClock(c.backoff, nil)
+	resetBackoff(c.backoff)
+	if n, ok := maxRetriesFromContext(ctx); ok {
+		limited := *c
+		limited.backoff = LimitRetries(c.backoff, n)
+		c = &limited
+	}
+	start := clock.Now()
+	v, attempts, err := loop(ctx, op, c, clock, start)
+	if err != nil {
+		if c.onGiveUp != nil {
+			c.onGiveUp(err, attempts, clock.Now().Sub(start))
+		}
+	} else if c.onSuccess != nil {
+		c.onSuccess(attempts, clock.Now().Sub(start))
+	}
+	return v, err
+}
+
+// loop calls op until it succeeds or should not be retried, returning the
+// result and the number of attempts made.
+func loop[T any](
+	ctx context.Context,
+	op func(ctx context.Context) (T, error),
+	c *config,
+	clock Clock,
+	start time.Time,
+) (T, int, error) {
+	var zero T
+	var errs []error
+	notify := c.throttledNotify(clock)
+	for n := 1; ; n++ {
+		v, err := attempt(ctx, n, op, c)
+		if err == nil {
+			if c.budget != nil {
+				c.budget.Deposit()
+			}
+			return v, n, nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return zero, n, c.join(c.collect(errs, perm.err))
+		}
+		errs = c.collect(errs, err)
+		if c.retryIf != nil && !c.retryIf(err) {
+			return zero, n, c.join(errs)
+		}
+
+		next := c.backoff.Next()
+		if next == Stop {
+			return zero, n, &ExhaustedError{
+				Err:      c.join(errs),
+				Attempts: n,
+				Elapsed:  clock.Now().Sub(start),
+			}
+		}
+		if d, ok := retryAfter(err); ok {
+			next = d
+		}
+		if c.budget != nil && !c.budget.Withdraw() {
+			return zero, n, fmt.Errorf("%w: %w", ErrBudgetExhausted, c.join(errs))
+		}
+		// Don't wait if the context would expire before the next attempt
+		if deadline, ok := ctx.Deadline(); ok && deadline.Sub(clock.Now()) < next {
+			return zero, n, c.contextErr(errs, context.DeadlineExceeded)
+		}
+		if notify != nil {
+			notify(err, n, next)
+		}
+
+		timer := clock.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, n, c.contextErr(errs, ctx.Err())
+		case <-timer.C():
+		}
+	}
+}
+
+// collect adds the error from an attempt to errs, keeping only the errors
+// that are returned according to [WithErrorAggregation].
+func (c *config) collect(errs []error, err error) []error {
+	limit := 1
+	if c.aggregate {
+		limit = c.aggregateLimit
+	}
+	if limit > 0 && len(errs) >= limit {
+		errs = append(errs[:0], errs[len(errs)-limit+1:]...)
+	}
+	return append(errs, err)
+}
+
+// join returns the error to return from the collected errors.
+func (c *config) join(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// contextErr returns the error to return when the context is done. This is
+// the last error from the operation if the deadline was exceeded and
+// [WithLastErrorOnDeadline] is used.
+func (c *config) contextErr(errs []error, ctxErr error) error {
+	if c.lastErrorOnDeadline && errors.Is(ctxErr, context.DeadlineExceeded) {
+		return c.join(errs)
+	}
+	if !c.aggregate {
+		return ctxErr
+	}
+	return c.join(append(errs, ctxErr))
+}
+
+// attempt calls op once, if allowed by the breaker set with [WithBreaker].
+func attempt[T any](ctx context.Context, n int, op func(ctx context.Context) (T, error), c *config) (T, error) {
+	if c.breaker != nil {
+		done, err := c.breaker.Allow()
+		if err != nil {
+			var zero T
+			return zero, Permanent(err)
+		}
+		v, err := attemptOp(ctx, n, op, c)
+		done(err)
+		return v, err
+	}
+	return attemptOp(ctx, n, op, c)
+}
+
+// attemptOp calls op once, applying the per-attempt timeout if configured.
+func attemptOp[T any](ctx context.Context, n int, op func(ctx context.Context) (T, error), c *config) (T, error) {
+	timeout := c.attemptTimeout(ctx, n)
+	ctx = context.WithValue(ctx, attemptKey{}, n)
+	if timeout <= 0 {
+		return op(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return op(ctx)
+}
+
+// attemptTimeout returns the timeout for attempt n, according to
+// [WithPerAttemptTimeout] and [WithDeadlineSplit], or zero if the attempt is
+// only limited by the context.
+func (c *config) attemptTimeout(ctx context.Context, n int) time.Duration {
+	timeout := c.perAttemptTimeout
+	left := c.splitAttempts - n + 1
+	if !c.splitDeadline || left <= 1 {
+		return timeout
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	share := max(deadline.Sub(clockOrSystem(c.clock).Now())/time.Duration(left), 1)
+	if timeout <= 0 {
+		return share
+	}
+	return min(timeout, share)
+}
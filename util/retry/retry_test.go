@@ -0,0 +1,432 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"hypera.dev/lib/util/breaker"
+)
+
+var errTest = errors.New("test error")
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTest
+		}
+		return nil
+	}, NewConstantBackoff(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Retry() = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetryStop(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), func(context.Context) error {
+		calls++
+		return errTest
+	}, LimitRetries(NewConstantBackoff(time.Millisecond), 2))
+	if !errors.Is(err, errTest) {
+		t.Errorf("Retry() = %v, want %v", err, errTest)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetryPermanent(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), func(context.Context) error {
+		calls++
+		return Permanent(errTest)
+	}, NewConstantBackoff(time.Millisecond))
+	var perm *permanentError
+	if !errors.Is(err, errTest) || errors.As(err, &perm) {
+		t.Errorf("Retry() = %v, want %v", err, errTest)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := Retry(ctx, func(context.Context) error {
+		cancel()
+		return errTest
+	}, NewConstantBackoff(time.Hour))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Retry() = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRetryNotify(t *testing.T) {
+	var notified []time.Duration
+	_ = RetryNotify(context.Background(), func(context.Context) error {
+		return errTest
+	}, LimitRetries(NewConstantBackoff(time.Millisecond), 2), func(err error, next time.Duration) {
+		if !errors.Is(err, errTest) {
+			t.Errorf("notify err = %v, want %v", err, errTest)
+		}
+		notified = append(notified, next)
+	})
+	if len(notified) != 2 {
+		t.Errorf("notified %d times, want 2", len(notified))
+	}
+}
+
+func TestRetryValue(t *testing.T) {
+	calls := 0
+	v, err := RetryValue(context.Background(), func(context.Context) (string, error) {
+		calls++
+		if calls < 2 {
+			return "", errTest
+		}
+		return "ok", nil
+	}, NewConstantBackoff(time.Millisecond))
+	if err != nil || v != "ok" {
+		t.Errorf("RetryValue() = %q, %v, want %q, nil", v, err, "ok")
+	}
+
+	v, err = RetryValue(context.Background(), func(context.Context) (string, error) {
+		return "partial", Permanent(errTest)
+	}, NewConstantBackoff(time.Millisecond))
+	if !errors.Is(err, errTest) || v != "" {
+		t.Errorf("RetryValue() = %q, %v, want zero value and %v", v, err, errTest)
+	}
+}
+
+func TestDo(t *testing.T) {
+	calls, notified := 0, 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return errTest
+	},
+		WithBackoff(NewConstantBackoff(time.Millisecond)),
+		WithMaxRetries(3),
+		WithNotify(func(error, time.Duration) { notified++ }),
+	)
+	if !errors.Is(err, errTest) {
+		t.Errorf("Do() = %v, want %v", err, errTest)
+	}
+	if calls != 4 || notified != 3 {
+		t.Errorf("calls = %d, notified = %d, want 4 and 3", calls, notified)
+	}
+}
+
+func TestDoPerAttemptTimeout(t *testing.T) {
+	calls := 0
+	v, err := DoValue(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return calls, nil
+	},
+		WithBackoff(NewConstantBackoff(time.Millisecond)),
+		WithPerAttemptTimeout(10*time.Millisecond),
+	)
+	if err != nil || v != 2 {
+		t.Errorf("DoValue() = %d, %v, want 2, nil", v, err)
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	if n := Attempt(context.Background()); n != 0 {
+		t.Errorf("Attempt() = %d, want 0", n)
+	}
+
+	var attempts []int
+	_ = Retry(context.Background(), func(ctx context.Context) error {
+		attempts = append(attempts, Attempt(ctx))
+		return errTest
+	}, LimitRetries(NewConstantBackoff(time.Millisecond), 2))
+	if want := []int{1, 2, 3}; !slices.Equal(attempts, want) {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+}
+
+func TestRetryNotifyAttempt(t *testing.T) {
+	var attempts []int
+	_ = RetryNotifyAttempt(context.Background(), func(context.Context) error {
+		return errTest
+	}, LimitRetries(NewConstantBackoff(time.Millisecond), 2), func(_ error, attempt int, next time.Duration) {
+		if next != time.Millisecond {
+			t.Errorf("next = %v, want %v", next, time.Millisecond)
+		}
+		attempts = append(attempts, attempt)
+	})
+	if want := []int{1, 2}; !slices.Equal(attempts, want) {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+}
+
+func TestDoRetryIf(t *testing.T) {
+	errOther := errors.New("other error")
+	calls := 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTest
+		}
+		return errOther
+	},
+		WithBackoff(NewConstantBackoff(time.Millisecond)),
+		WithRetryIf(func(err error) bool { return errors.Is(err, errTest) }),
+	)
+	if !errors.Is(err, errOther) {
+		t.Errorf("Do() = %v, want %v", err, errOther)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDoDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	err := Do(ctx, func(context.Context) error {
+		return errTest
+	}, WithBackoff(NewConstantBackoff(time.Hour)))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Do() waited %v, want no wait", elapsed)
+	}
+
+	err = Do(ctx, func(context.Context) error {
+		return errTest
+	}, WithBackoff(NewConstantBackoff(time.Hour)), WithLastErrorOnDeadline())
+	if !errors.Is(err, errTest) {
+		t.Errorf("Do() = %v, want %v", err, errTest)
+	}
+}
+
+func TestDoErrorAggregation(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  string
+	}{
+		{name: "all", limit: 0, want: "error 1\nerror 2\nerror 3\nerror 4"},
+		{name: "limit", limit: 2, want: "error 3\nerror 4"},
+		{name: "one", limit: 1, want: "error 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Do(context.Background(), func(ctx context.Context) error {
+				return fmt.Errorf("error %d", Attempt(ctx))
+			},
+				WithBackoff(NewConstantBackoff(time.Millisecond)),
+				WithMaxRetries(3),
+				WithErrorAggregation(tt.limit),
+			)
+			var exhausted *ExhaustedError
+			if !errors.As(err, &exhausted) || exhausted.Err.Error() != tt.want {
+				t.Errorf("Do() = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoExhausted(t *testing.T) {
+	err := Do(context.Background(), func(context.Context) error {
+		return errTest
+	}, WithBackoff(NewConstantBackoff(time.Millisecond)), WithMaxRetries(2))
+	if !errors.Is(err, ErrExhausted) || !errors.Is(err, errTest) {
+		t.Fatalf("Do() = %v, want %v wrapping %v", err, ErrExhausted, errTest)
+	}
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("Do() = %T, want %T", err, exhausted)
+	}
+	if exhausted.Attempts != 3 || exhausted.Elapsed <= 0 {
+		t.Errorf("Attempts = %d, Elapsed = %v, want 3 and positive", exhausted.Attempts, exhausted.Elapsed)
+	}
+
+	err = Do(context.Background(), func(context.Context) error {
+		return Permanent(errTest)
+	})
+	if errors.Is(err, ErrExhausted) {
+		t.Errorf("Do() = %v, want permanent error not to match %v", err, ErrExhausted)
+	}
+}
+
+func TestRetryResetsBackoff(t *testing.T) {
+	b := &ExponentialBackoff{InitialInterval: time.Millisecond, Multiplier: 1000}
+	for i := 0; i < 2; i++ {
+		var intervals []time.Duration
+		_ = RetryNotify(context.Background(), func(ctx context.Context) error {
+			if Attempt(ctx) > 1 {
+				return nil
+			}
+			return errTest
+		}, b, func(_ error, next time.Duration) {
+			intervals = append(intervals, next)
+		})
+		if want := []time.Duration{time.Millisecond}; !slices.Equal(intervals, want) {
+			t.Errorf("run %d: intervals = %v, want %v", i, intervals, want)
+		}
+	}
+}
+
+func TestDoHooks(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	var gaveUp, succeeded int
+	opts := []Option{
+		WithBackoff(NewConstantBackoff(0)),
+		WithMaxRetries(2),
+		WithClock(clock),
+		WithOnGiveUp(func(err error, attempts int, _ time.Duration) {
+			if !errors.Is(err, errTest) || attempts != 3 {
+				t.Errorf("OnGiveUp(%v, %d), want %v and 3", err, attempts, errTest)
+			}
+			gaveUp++
+		}),
+		WithOnSuccess(func(attempts int, _ time.Duration) {
+			if attempts != 2 {
+				t.Errorf("OnSuccess(%d), want 2", attempts)
+			}
+			succeeded++
+		}),
+	}
+
+	_ = Do(context.Background(), func(context.Context) error {
+		return errTest
+	}, opts...)
+	_ = Do(context.Background(), func(ctx context.Context) error {
+		if Attempt(ctx) < 2 {
+			return errTest
+		}
+		return nil
+	}, opts...)
+	if gaveUp != 1 || succeeded != 1 {
+		t.Errorf("gave up %d times and succeeded %d times, want 1 and 1", gaveUp, succeeded)
+	}
+}
+
+func TestDoMaxAttempts(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+		want int
+	}{
+		{name: "max attempts", opt: WithMaxAttempts(3), want: 3},
+		{name: "max retries", opt: WithMaxRetries(3), want: 4},
+		{name: "one attempt", opt: WithMaxAttempts(1), want: 1},
+		{name: "zero attempts", opt: WithMaxAttempts(0), want: 1},
+		{name: "zero retries", opt: WithMaxRetries(0), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_ = Do(context.Background(), func(context.Context) error {
+				calls++
+				return errTest
+			}, WithBackoff(NewConstantBackoff(0)), tt.opt)
+			if calls != tt.want {
+				t.Errorf("calls = %d, want %d", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryReusesLimitedBackoff(t *testing.T) {
+	b := LimitRetries(NewConstantBackoff(0), 2)
+	for i := 0; i < 2; i++ {
+		attempts := 0
+		_ = Retry(context.Background(), func(context.Context) error {
+			attempts++
+			return errTest
+		}, b)
+		if attempts != 3 {
+			t.Errorf("attempts = %d, want 3", attempts)
+		}
+	}
+}
+
+func TestDoDeadlineSplit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	parent, _ := ctx.Deadline()
+
+	var remaining []time.Duration
+	_ = Do(ctx, func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		remaining = append(remaining, time.Until(deadline))
+		if Attempt(ctx) == 3 && !deadline.Equal(parent) {
+			t.Errorf("last attempt deadline = %v, want %v", deadline, parent)
+		}
+		return errTest
+	},
+		WithBackoff(NewConstantBackoff(0)),
+		WithMaxAttempts(3),
+		WithDeadlineSplit(0),
+	)
+
+	want := []time.Duration{20 * time.Second, 30 * time.Second, time.Minute}
+	if len(remaining) != len(want) {
+		t.Fatalf("attempts = %d, want %d", len(remaining), len(want))
+	}
+	for i, w := range want {
+		if remaining[i] > w || remaining[i] < w-time.Second {
+			t.Errorf("attempt %d timeout = %v, want about %v", i+1, remaining[i], w)
+		}
+	}
+}
+
+func TestDoBreaker(t *testing.T) {
+	b := breaker.New(&breaker.Options{Trip: breaker.ConsecutiveFailures(2)})
+	attempts := 0
+	err := Do(context.Background(), func(context.Context) error {
+		attempts++
+		return errTest
+	}, WithBackoff(NewConstantBackoff(0)), WithMaxRetries(5), WithBreaker(b))
+	if !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("err = %v, want %v", err, breaker.ErrOpen)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
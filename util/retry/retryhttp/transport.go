@@ -0,0 +1,243 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package retryhttp implements an [http.RoundTripper] that retries idempotent
+requests using the [retry] package.
+*/
+package retryhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"hypera.dev/lib/util/retry"
+)
+
+// DefaultMaxRetries is the number of retries made by a [Transport], unless
+// configured otherwise with [retry.WithMaxRetries].
+const DefaultMaxRetries = 3
+
+// maxDrainBytes is the maximum number of bytes read from the body of a
+// response that is retried, so that the connection can be reused.
+const maxDrainBytes = 4 << 10
+
+// Transport is an [http.RoundTripper] that retries requests that fail with
+// a connection error or a retryable status code. Only idempotent requests
+// are retried, and requests with a body are only retried if their GetBody
+// function is set, which is the case for requests created by
+// [http.NewRequest] with common body types.
+//
+// Delays requested by responses with a Retry-After header are honoured. If
+// all attempts fail with a retryable status code, the last response is
+// returned.
+type Transport struct {
+	// Base is the transport used to make requests. If nil,
+	// [http.DefaultTransport] is used.
+	Base http.RoundTripper
+
+	// NewBackoff returns the backoff used for each request. If nil,
+	// [retry.NewExponentialBackoff] is used.
+	NewBackoff func() retry.Backoff
+
+	// RetryStatus reports whether responses with the given status code are
+	// retried. If nil, [DefaultRetryStatus] is used.
+	RetryStatus func(code int) bool
+
+	// Options are additional options used for all requests. They must not
+	// include [retry.WithBackoff], as backoffs cannot be shared by requests.
+	Options []retry.Option
+}
+
+// DefaultRetryStatus reports whether code is 429 (Too Many Requests), 502
+// (Bad Gateway), 503 (Service Unavailable) or 504 (Gateway Timeout).
+func DefaultRetryStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// optionsKey is the context key for per-request options.
+type optionsKey struct{}
+
+// WithOptions returns a context that adds options for requests made with it
+// by a [Transport]. The options are applied after the options of the
+// transport, so they take precedence.
+func WithOptions(ctx context.Context, opts ...retry.Option) context.Context {
+	prev, _ := ctx.Value(optionsKey{}).([]retry.Option)
+	return context.WithValue(ctx, optionsKey{}, append(prev[:len(prev):len(prev)], opts...))
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !isIdempotent(req) || !canRewind(req) {
+		return base.RoundTrip(req)
+	}
+
+	var last *http.Response
+	resp, err := retry.DoValue(req.Context(), func(ctx context.Context) (*http.Response, error) {
+		if last != nil {
+			discard(last)
+			last = nil
+		}
+		rctx, cancel := attemptContext(req.Context(), ctx)
+		r := req.Clone(rctx)
+		if retry.Attempt(ctx) > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, retry.Permanent(err)
+			}
+			r.Body = body
+		}
+
+		resp, err := base.RoundTrip(r)
+		if err != nil {
+			cancel()
+			if isRetryableError(req.Context(), err) {
+				return nil, err
+			}
+			return nil, retry.Permanent(err)
+		}
+		resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+		if t.retryStatus(resp.StatusCode) {
+			last = resp
+			return nil, retry.ResponseError(resp)
+		}
+		return resp, nil
+	}, t.options(req.Context())...)
+	if err != nil && last != nil {
+		// Return the last response rather than an error, as the base
+		// transport would have.
+		var statusErr *retry.StatusError
+		if errors.As(err, &statusErr) {
+			return last, nil
+		}
+		discard(last)
+	}
+	return resp, err
+}
+
+// options returns the options for a request made with the given context.
+func (t *Transport) options(ctx context.Context) []retry.Option {
+	newBackoff := t.NewBackoff
+	if newBackoff == nil {
+		newBackoff = func() retry.Backoff { return retry.NewExponentialBackoff() }
+	}
+	opts := []retry.Option{
+		retry.WithBackoff(newBackoff()),
+		retry.WithMaxRetries(DefaultMaxRetries),
+	}
+	opts = append(opts, t.Options...)
+	if ctxOpts, ok := ctx.Value(optionsKey{}).([]retry.Option); ok {
+		opts = append(opts, ctxOpts...)
+	}
+	return opts
+}
+
+func (t *Transport) retryStatus(code int) bool {
+	if t.RetryStatus == nil {
+		return DefaultRetryStatus(code)
+	}
+	return t.RetryStatus(code)
+}
+
+// isIdempotent reports whether the request can be safely retried.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	// Requests with an idempotency key are treated as idempotent, like
+	// by net/http.
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+	return ok
+}
+
+// canRewind reports whether the body of the request can be sent again.
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// attemptContext returns the context of the request sent by an attempt with
+// the context ctx, and a function that cancels it. The context of an attempt
+// is cancelled as soon as the attempt returns, which would stop the caller
+// from reading the response body, so the request instead uses a context
+// derived from parent with the deadline of the attempt, which is cancelled
+// once the response body is closed.
+func attemptContext(parent, ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(parent, deadline)
+	}
+	return context.WithCancel(parent)
+}
+
+// cancelBody is a response body that cancels the context of its request once
+// it is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// isRetryableError reports whether a request made with the context ctx, that
+// failed with err, should be retried. Requests that exceeded the deadline of
+// their attempt are retried, but not those whose context is done.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return retry.IsTransient(err) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// discard reads a limited amount of the response body and closes it, so
+// that the connection can be reused.
+func discard(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,167 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package retrysql retries database transactions that fail with transient
+errors, such as serialization failures and deadlocks.
+*/
+package retrysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"hypera.dev/lib/util/retry"
+)
+
+// Classifier reports whether a database error is transient, meaning that the
+// operation is likely to succeed if it is retried.
+type Classifier func(err error) bool
+
+// TransientSQLStates are the SQLSTATE codes and classes treated as transient
+// by [IsTransient]. Codes ending in "*" match the whole class.
+var TransientSQLStates = []string{
+	"40001", // serialization_failure
+	"40P01", // deadlock_detected
+	"08*",   // connection exceptions
+	"53300", // too_many_connections
+	"57P01", // admin_shutdown
+	"57P03", // cannot_connect_now
+}
+
+// IsTransient reports whether err is a transient database error. This is the
+// case for:
+//   - [driver.ErrBadConn] and [sql.ErrConnDone],
+//   - errors with a SQLState() string method returning one of the
+//     [TransientSQLStates], as returned by most PostgreSQL drivers,
+//   - errors that are transient according to [retry.IsTransient], such as
+//     connection resets.
+//
+// Driver-specific errors can be classified with [Transient].
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	if SQLState(TransientSQLStates...)(err) {
+		return true
+	}
+	return retry.IsTransient(err)
+}
+
+// Transient returns a [Classifier] that reports errors as transient if
+// [IsTransient] or any of the given classifiers does.
+func Transient(classifiers ...Classifier) Classifier {
+	return func(err error) bool {
+		if IsTransient(err) {
+			return true
+		}
+		for _, c := range classifiers {
+			if c(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SQLState returns a [Classifier] that matches errors with a SQLState()
+// string method returning one of the given codes. Codes ending in "*" match
+// every code starting with the prefix before it.
+func SQLState(codes ...string) Classifier {
+	return func(err error) bool {
+		var stateErr interface{ SQLState() string }
+		if !errors.As(err, &stateErr) {
+			return false
+		}
+		state := stateErr.SQLState()
+		for _, code := range codes {
+			if prefix, ok := strings.CutSuffix(code, "*"); ok {
+				if strings.HasPrefix(state, prefix) {
+					return true
+				}
+			} else if state == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// TxBeginner starts transactions, like [sql.DB] and [sql.Conn].
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// WithTx runs fn in a transaction, which is committed if fn returns nil and
+// rolled back otherwise. If starting the transaction, fn or committing fails
+// with a transient error according to [IsTransient], the whole transaction
+// is retried using [retry.Do] with the given options. The classification
+// can be changed with [retry.WithRetryIf].
+//
+// As fn may be called more than once, it should not have side effects
+// outside of the transaction.
+func WithTx(
+	ctx context.Context,
+	db TxBeginner,
+	txOpts *sql.TxOptions,
+	fn func(ctx context.Context, tx *sql.Tx) error,
+	opts ...retry.Option,
+) error {
+	opts = append([]retry.Option{retry.WithRetryIf(IsTransient)}, opts...)
+	return retry.Do(ctx, func(ctx context.Context) error {
+		return runTx(ctx, db, txOpts, fn)
+	}, opts...)
+}
+
+// runTx runs fn in a single transaction.
+func runTx(
+	ctx context.Context,
+	db TxBeginner,
+	txOpts *sql.TxOptions,
+	fn func(ctx context.Context, tx *sql.Tx) error,
+) error {
+	tx, err := db.BeginTx(ctx, txOpts)
+	if err != nil {
+		return err
+	}
+	if err := fn(ctx, tx); err != nil {
+		return errors.Join(err, ignoreDone(tx.Rollback()))
+	}
+	return tx.Commit()
+}
+
+// ignoreDone returns nil if err is [sql.ErrTxDone], which is returned when
+// rolling back a transaction that fn already committed or rolled back.
+func ignoreDone(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
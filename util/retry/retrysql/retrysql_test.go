@@ -0,0 +1,172 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retrysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hypera.dev/lib/util/retry"
+)
+
+type stateError string
+
+func (e stateError) Error() string {
+	return "sql state " + string(e)
+}
+
+func (e stateError) SQLState() string {
+	return string(e)
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil, want: false},
+		{err: errors.New("syntax error"), want: false},
+		{err: driver.ErrBadConn, want: true},
+		{err: fmt.Errorf("query: %w", sql.ErrConnDone), want: true},
+		{err: stateError("40001"), want: true},
+		{err: stateError("40P01"), want: true},
+		{err: stateError("08006"), want: true},
+		{err: stateError("23505"), want: false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTransient(t *testing.T) {
+	errLockTimeout := errors.New("lock wait timeout")
+	c := Transient(func(err error) bool { return errors.Is(err, errLockTimeout) })
+	if !c(errLockTimeout) || !c(stateError("40001")) || c(errors.New("other")) {
+		t.Error("unexpected classification")
+	}
+}
+
+// fakeDriver is a database driver whose commits fail with the errors in
+// commitErrs, in order.
+type fakeDriver struct {
+	commitErrs []error
+	commits    atomic.Int32
+	rollbacks  atomic.Int32
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{d: c.d}, nil
+}
+
+type fakeTx struct {
+	d *fakeDriver
+}
+
+func (tx *fakeTx) Commit() error {
+	n := int(tx.d.commits.Add(1))
+	if n <= len(tx.d.commitErrs) {
+		return tx.d.commitErrs[n-1]
+	}
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.d.rollbacks.Add(1)
+	return nil
+}
+
+var driverCount atomic.Int32
+
+func openFake(t *testing.T, d *fakeDriver) *sql.DB {
+	t.Helper()
+	name := fmt.Sprintf("retrysql-fake-%d", driverCount.Add(1))
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("sql.Open() = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestWithTx(t *testing.T) {
+	d := &fakeDriver{commitErrs: []error{stateError("40001"), stateError("40P01")}}
+	db := openFake(t, d)
+
+	calls := 0
+	err := WithTx(context.Background(), db, nil, func(context.Context, *sql.Tx) error {
+		calls++
+		return nil
+	}, retry.WithBackoff(retry.NewConstantBackoff(time.Millisecond)))
+	if err != nil {
+		t.Fatalf("WithTx() = %v", err)
+	}
+	if calls != 3 || d.commits.Load() != 3 {
+		t.Errorf("calls = %d, commits = %d, want 3 and 3", calls, d.commits.Load())
+	}
+}
+
+func TestWithTxPermanent(t *testing.T) {
+	d := &fakeDriver{}
+	db := openFake(t, d)
+
+	errConstraint := stateError("23505")
+	calls := 0
+	err := WithTx(context.Background(), db, nil, func(context.Context, *sql.Tx) error {
+		calls++
+		return errConstraint
+	}, retry.WithBackoff(retry.NewConstantBackoff(time.Millisecond)))
+	if !errors.Is(err, errConstraint) {
+		t.Errorf("WithTx() = %v, want %v", err, errConstraint)
+	}
+	if calls != 1 || d.rollbacks.Load() != 1 || d.commits.Load() != 0 {
+		t.Errorf("calls = %d, rollbacks = %d, commits = %d, want 1, 1 and 0",
+			calls, d.rollbacks.Load(), d.commits.Load())
+	}
+}
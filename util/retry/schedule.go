@@ -0,0 +1,83 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"time"
+)
+
+// CloneableBackoff is a [Backoff] that can be copied, including its current
+// state. All backoffs in this package can be cloned, as long as the backoffs
+// they wrap can be.
+type CloneableBackoff interface {
+	Backoff
+
+	// Clone returns a copy of the backoff, which continues from the same
+	// state independently of the original.
+	Clone() Backoff
+}
+
+// cloner is implemented by wrapping backoffs, which can only be cloned if
+// the backoffs they wrap can be.
+type cloner interface {
+	clone() (Backoff, bool)
+}
+
+// cloneBackoff returns a copy of b, reporting whether b could be cloned.
+func cloneBackoff(b Backoff) (Backoff, bool) {
+	switch b := b.(type) {
+	case CloneableBackoff:
+		return b.Clone(), true
+	case cloner:
+		return b.clone()
+	default:
+		return nil, false
+	}
+}
+
+// Schedule returns the first n intervals of b, without waiting. Fewer
+// intervals are returned if b returns [Stop]. This is useful for tests,
+// documentation and validating configuration.
+//
+// Schedule uses a copy of b if it can be cloned, see [CloneableBackoff].
+// Otherwise b itself is advanced, and reset afterwards if it is a
+// [ResettableBackoff].
+func Schedule(b Backoff, n int) []time.Duration {
+	if c, ok := cloneBackoff(b); ok {
+		b = c
+	} else if r, ok := b.(ResettableBackoff); ok {
+		defer r.Reset()
+	}
+
+	intervals := make([]time.Duration, 0, n)
+	for i := 0; i < n; i++ {
+		next := b.Next()
+		if next == Stop {
+			break
+		}
+		intervals = append(intervals, next)
+	}
+	return intervals
+}
@@ -0,0 +1,65 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	b := &ExponentialBackoff{InitialInterval: time.Second, Multiplier: 2}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if got := Schedule(b, 3); !slices.Equal(got, want) {
+		t.Errorf("Schedule() = %v, want %v", got, want)
+	}
+	// The original backoff is not advanced
+	if got := b.Next(); got != time.Second {
+		t.Errorf("Next() = %v, want %v", got, time.Second)
+	}
+}
+
+func TestScheduleWrapped(t *testing.T) {
+	b := WithCap(LimitRetries(NewLinearBackoff(time.Second, time.Second), 3), 2*time.Second)
+	want := []time.Duration{time.Second, 2 * time.Second, 2 * time.Second}
+	for i := 0; i < 2; i++ {
+		if got := Schedule(b, 10); !slices.Equal(got, want) {
+			t.Errorf("Schedule() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestScheduleNotCloneable(t *testing.T) {
+	n := 0
+	b := BackoffFunc(func() time.Duration {
+		n++
+		return time.Duration(n) * time.Second
+	})
+	want := []time.Duration{time.Second, 2 * time.Second}
+	if got := Schedule(b, 2); !slices.Equal(got, want) {
+		t.Errorf("Schedule() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// NotifySlog returns a [NotifyAttempt] that logs each failed attempt to
+// logger with the given level and message, and the error, attempt number and
+// delay before the next attempt as attributes. If notifications were
+// suppressed, see [SuppressedError], the number of suppressed notifications
+// is logged as well. If logger is nil, [slog.Default] is used.
+//
+// It can be used with [WithNotifyAttempt] or [RetryNotifyAttempt].
+func NotifySlog(logger *slog.Logger, level slog.Level, msg string) NotifyAttempt {
+	return func(err error, attempt int, next time.Duration) {
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		attrs := []slog.Attr{
+			slog.Any("error", err),
+			slog.Int("attempt", attempt),
+			slog.Duration("next", next),
+		}
+		var suppressed *SuppressedError
+		if errors.As(err, &suppressed) {
+			attrs[0] = slog.Any("error", suppressed.Err)
+			attrs = append(attrs, slog.Int("suppressed", suppressed.Suppressed))
+		}
+		l.LogAttrs(context.Background(), level, msg, attrs...)
+	}
+}
+
+// loggingBackoff logs the intervals of a backoff.
+type loggingBackoff struct {
+	b      Backoff
+	logger *slog.Logger
+	level  slog.Level
+	clock  Clock
+
+	retries int
+	start   time.Time
+}
+
+// WithLogging returns a [Backoff] that logs each interval returned by b to
+// logger with the given level, for debugging retry behaviour. The retry
+// number, the interval and the time since the first call to Next are logged
+// as attributes. If logger is nil, [slog.Default] is used.
+//
+// Resetting it restarts the retry number and elapsed time, and resets b if it
+// is a [ResettableBackoff].
+func WithLogging(b Backoff, logger *slog.Logger, level slog.Level) ResettableBackoff {
+	return &loggingBackoff{b: b, logger: logger, level: level}
+}
+
+// Next implements [Backoff].
+func (b *loggingBackoff) Next() time.Duration {
+	now := clockOrSystem(b.clock).Now()
+	if b.start.IsZero() {
+		b.start = now
+	}
+	b.retries++
+	next := b.b.Next()
+
+	l := b.logger
+	if l == nil {
+		l = slog.Default()
+	}
+	if next == Stop {
+		l.LogAttrs(context.Background(), b.level, "retry backoff stopped",
+			slog.Int("retry", b.retries),
+			slog.Duration("elapsed", now.Sub(b.start)),
+		)
+	} else {
+		l.LogAttrs(context.Background(), b.level, "retry backoff",
+			slog.Int("retry", b.retries),
+			slog.Duration("next", next),
+			slog.Duration("elapsed", now.Sub(b.start)),
+		)
+	}
+	return next
+}
+
+// Reset implements [ResettableBackoff].
+func (b *loggingBackoff) Reset() {
+	b.retries = 0
+	b.start = time.Time{}
+	resetBackoff(b.b)
+}
+
+func (b *loggingBackoff) useClock(c Clock) {
+	b.clock = c
+	useClock(b.b, c)
+}
+
+func (b *loggingBackoff) clone() (Backoff, bool) {
+	inner, ok := cloneBackoff(b.b)
+	if !ok {
+		return nil, false
+	}
+	c := *b
+	c.b = inner
+	return &c, true
+}
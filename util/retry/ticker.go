@@ -0,0 +1,120 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Ticker delivers ticks on a channel according to a [Backoff], for loops
+// that can't be expressed as a single [Retryable], such as reconnect loops.
+//
+// The first tick is delivered immediately. Each following tick is delivered
+// after the interval returned by the backoff, measured from when the
+// previous tick was received.
+type Ticker struct {
+	// C is the channel on which the ticks are delivered. C is closed when
+	// the backoff returns [Stop] or the ticker is stopped.
+	C <-chan time.Time
+
+	c     chan time.Time
+	b     Backoff
+	stop  chan struct{}
+	reset chan struct{}
+	once  sync.Once
+}
+
+// NewTicker returns a [Ticker] that delivers ticks according to b. The
+// ticker must be stopped with Stop when it is no longer needed, unless the
+// backoff stops.
+func NewTicker(b Backoff) *Ticker {
+	c := make(chan time.Time)
+	t := &Ticker{
+		C:     c,
+		c:     c,
+		b:     b,
+		stop:  make(chan struct{}),
+		reset: make(chan struct{}, 1),
+	}
+	go t.run()
+	return t
+}
+
+// Stop stops the ticker and closes its channel. Calling Stop more than once
+// has no effect.
+func (t *Ticker) Stop() {
+	t.once.Do(func() {
+		close(t.stop)
+	})
+}
+
+// Reset restarts the ticker, resetting the backoff if it is a
+// [ResettableBackoff] and delivering the next tick immediately. Reset has no effect if the
+// ticker has stopped.
+func (t *Ticker) Reset() {
+	select {
+	case t.reset <- struct{}{}:
+	default:
+	}
+}
+
+func (t *Ticker) run() {
+	defer close(t.c)
+	var next time.Duration
+	for {
+		timer := time.NewTimer(next)
+		select {
+		case <-t.stop:
+			timer.Stop()
+			return
+		case <-t.reset:
+			timer.Stop()
+			next = t.resetBackoff()
+			continue
+		case tick := <-timer.C:
+			select {
+			case t.c <- tick:
+			case <-t.stop:
+				return
+			case <-t.reset:
+				next = t.resetBackoff()
+				continue
+			}
+		}
+
+		next = t.b.Next()
+		if next == Stop {
+			return
+		}
+	}
+}
+
+// resetBackoff resets the backoff if possible, and returns the interval
+// before the next tick.
+func (t *Ticker) resetBackoff() time.Duration {
+	resetBackoff(t.b)
+	return 0
+}
@@ -0,0 +1,73 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicker(t *testing.T) {
+	ticker := NewTicker(LimitRetries(NewConstantBackoff(time.Millisecond), 2))
+	defer ticker.Stop()
+
+	ticks := 0
+	for range ticker.C {
+		ticks++
+	}
+	if ticks != 3 {
+		t.Errorf("ticks = %d, want 3", ticks)
+	}
+}
+
+func TestTickerStop(t *testing.T) {
+	ticker := NewTicker(NewConstantBackoff(time.Hour))
+	<-ticker.C
+	ticker.Stop()
+	ticker.Stop()
+
+	select {
+	case _, ok := <-ticker.C:
+		if ok {
+			t.Error("expected channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("timed out waiting for channel to be closed")
+	}
+}
+
+func TestTickerReset(t *testing.T) {
+	b := &ExponentialBackoff{InitialInterval: time.Hour, Multiplier: 2}
+	ticker := NewTicker(b)
+	defer ticker.Stop()
+
+	<-ticker.C
+	ticker.Reset()
+	select {
+	case <-ticker.C:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for tick after Reset")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package ringbuffer provides fixed-size ring buffers, such as for keeping the
+last lines of a log to include in a crash report.
+*/
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrFull is returned by [Ring.Push] when the ring is full and rejects new
+// items.
+var ErrFull = errors.New("ringbuffer: ring is full")
+
+// Mode decides what happens when an item is pushed to a full ring.
+type Mode int
+
+const (
+	// Overwrite overwrites the oldest item.
+	Overwrite Mode = iota
+	// Reject rejects the new item.
+	Reject
+)
+
+// Ring is a fixed-size ring buffer. A Ring is safe for concurrent use.
+type Ring[T any] struct {
+	mode Mode
+
+	mu    sync.Mutex
+	items []T
+	head  int
+	len   int
+}
+
+// New returns a Ring that holds up to size items. A size of zero or less
+// is treated as one.
+func New[T any](size int, mode Mode) *Ring[T] {
+	return &Ring[T]{mode: mode, items: make([]T, max(size, 1))}
+}
+
+// Push adds v as the newest item. If the ring is full, the oldest item is
+// overwritten, or [ErrFull] is returned if the ring rejects new items.
+func (r *Ring[T]) Push(v T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.len == len(r.items) {
+		if r.mode == Reject {
+			return ErrFull
+		}
+		r.items[r.head] = v
+		r.head = (r.head + 1) % len(r.items)
+		return nil
+	}
+	r.items[(r.head+r.len)%len(r.items)] = v
+	r.len++
+	return nil
+}
+
+// Pop removes and returns the oldest item, and reports whether there was
+// one.
+func (r *Ring[T]) Pop() (T, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var zero T
+	if r.len == 0 {
+		return zero, false
+	}
+	v := r.items[r.head]
+	r.items[r.head] = zero
+	r.head = (r.head + 1) % len(r.items)
+	r.len--
+	return v, true
+}
+
+// Snapshot returns a copy of the items, oldest first.
+func (r *Ring[T]) Snapshot() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	items := make([]T, r.len)
+	n := copy(items, r.items[r.head:min(r.head+r.len, len(r.items))])
+	copy(items[n:], r.items[:r.len-n])
+	return items
+}
+
+// Len returns the number of items in the ring.
+func (r *Ring[T]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.len
+}
+
+// Cap returns the maximum number of items in the ring.
+func (r *Ring[T]) Cap() int {
+	return len(r.items)
+}
+
+// Reset removes every item.
+func (r *Ring[T]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.items)
+	r.head, r.len = 0, 0
+}
@@ -0,0 +1,90 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ringbuffer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		push     []int
+		want     []int
+		wantErrs int
+	}{
+		{name: "partial", mode: Overwrite, push: []int{1, 2}, want: []int{1, 2}},
+		{name: "overwrite", mode: Overwrite, push: []int{1, 2, 3, 4, 5}, want: []int{3, 4, 5}},
+		{name: "reject", mode: Reject, push: []int{1, 2, 3, 4, 5}, want: []int{1, 2, 3}, wantErrs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New[int](3, tt.mode)
+			errs := 0
+			for _, v := range tt.push {
+				if err := r.Push(v); err != nil {
+					if !errors.Is(err, ErrFull) {
+						t.Fatalf("Push() error = %v, want %v", err, ErrFull)
+					}
+					errs++
+				}
+			}
+			if errs != tt.wantErrs {
+				t.Errorf("Push() failed %d times, want %d", errs, tt.wantErrs)
+			}
+			if got := r.Snapshot(); !slices.Equal(got, tt.want) {
+				t.Errorf("Snapshot() = %v, want %v", got, tt.want)
+			}
+			if r.Len() != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", r.Len(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestRingPop(t *testing.T) {
+	r := New[string](2, Overwrite)
+	_ = r.Push("a")
+	_ = r.Push("b")
+	_ = r.Push("c")
+	if v, ok := r.Pop(); v != "b" || !ok {
+		t.Errorf("Pop() = %q, %t, want \"b\", true", v, ok)
+	}
+	_ = r.Push("d")
+	if got := r.Snapshot(); !slices.Equal(got, []string{"c", "d"}) {
+		t.Errorf("Snapshot() = %v, want [c d]", got)
+	}
+
+	r.Reset()
+	if _, ok := r.Pop(); ok {
+		t.Error("Pop() = true after Reset")
+	}
+	if r.Cap() != 2 {
+		t.Errorf("Cap() = %d, want 2", r.Cap())
+	}
+}
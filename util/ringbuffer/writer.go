@@ -0,0 +1,110 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ringbuffer
+
+import (
+	"io"
+	"sync"
+)
+
+// Writer is an [io.Writer] that keeps the last bytes written to it. Writes
+// never fail. A Writer is safe for concurrent use.
+type Writer struct {
+	mu    sync.Mutex
+	buf   []byte
+	head  int
+	len   int
+	total int64
+}
+
+var _ io.Writer = (*Writer)(nil)
+
+// NewWriter returns a Writer that keeps the last size bytes. A size of zero
+// or less is treated as one.
+func NewWriter(size int) *Writer {
+	return &Writer{buf: make([]byte, max(size, 1))}
+}
+
+// Write writes p, discarding the oldest bytes once the writer is full.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	w.total += int64(n)
+	size := len(w.buf)
+	if n >= size {
+		// Only the end of p is kept.
+		copy(w.buf, p[n-size:])
+		w.head, w.len = 0, size
+		return n, nil
+	}
+
+	tail := (w.head + w.len) % size
+	c := copy(w.buf[tail:], p)
+	copy(w.buf, p[c:])
+	w.len += n
+	if w.len > size {
+		w.head = (w.head + w.len - size) % size
+		w.len = size
+	}
+	return n, nil
+}
+
+// Bytes returns a copy of the bytes kept, oldest first.
+func (w *Writer) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := make([]byte, w.len)
+	n := copy(b, w.buf[w.head:min(w.head+w.len, len(w.buf))])
+	copy(b[n:], w.buf[:w.len-n])
+	return b
+}
+
+// String returns the bytes kept as a string.
+func (w *Writer) String() string {
+	return string(w.Bytes())
+}
+
+// Len returns the number of bytes kept.
+func (w *Writer) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.len
+}
+
+// Written returns the total number of bytes written, including those that
+// have been discarded.
+func (w *Writer) Written() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.total
+}
+
+// Reset discards the bytes kept.
+func (w *Writer) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.head, w.len, w.total = 0, 0, 0
+}
@@ -0,0 +1,80 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package ringbuffer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{name: "empty", writes: nil, want: ""},
+		{name: "partial", writes: []string{"ab", "c"}, want: "abc"},
+		{name: "wrap", writes: []string{"abcd", "ef", "gh"}, want: "cdefgh"},
+		{name: "large write", writes: []string{"ab", "0123456789"}, want: "456789"},
+		{name: "many small writes", writes: []string{"a", "b", "c", "d", "e", "f", "g"}, want: "bcdefg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWriter(6)
+			var total int64
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if n != len(s) || err != nil {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(s))
+				}
+				total += int64(n)
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if w.Written() != total {
+				t.Errorf("Written() = %d, want %d", w.Written(), total)
+			}
+		})
+	}
+}
+
+func TestWriterLines(t *testing.T) {
+	w := NewWriter(12)
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+	if got, want := w.String(), "ne 8\nline 9\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if w.Len() != 12 {
+		t.Errorf("Len() = %d, want 12", w.Len())
+	}
+	w.Reset()
+	if w.String() != "" {
+		t.Errorf("String() after Reset = %q, want empty", w.String())
+	}
+}
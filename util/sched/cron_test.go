@@ -0,0 +1,86 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sched
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCron(t *testing.T) {
+	base := time.Date(2024, time.January, 15, 10, 30, 45, 0, time.UTC) // Monday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.January, 15, 10, 31, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, time.January, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, time.January, 16, 3, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * feb *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * fri", time.Date(2024, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Cron(tt.expr)
+			if err != nil {
+				t.Fatalf("Cron() error = %v", err)
+			}
+			if got := s.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronIn(t *testing.T) {
+	loc := time.FixedZone("UTC+5:30", 5*60*60+30*60)
+	s, err := CronIn("0 9 * * *", loc)
+	if err != nil {
+		t.Fatalf("CronIn() error = %v", err)
+	}
+	base := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2024, time.January, 15, 3, 30, 0, 0, time.UTC)
+	if got := s.Next(base); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * mon-", "*/0 * * * *", "5-1 * * * *", "@often"} {
+		if _, err := Cron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("Cron(%q) error = %v, want %v", expr, err, ErrInvalidCron)
+		}
+	}
+}
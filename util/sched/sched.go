@@ -0,0 +1,353 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package sched implements an in-process job scheduler, running jobs at fixed
+intervals, on cron schedules or once after a delay.
+
+	s := sched.New(&sched.Options{Logger: logger})
+	err := s.Add(sched.Job{
+		Name:     "cleanup",
+		Schedule: sched.MustCron("0 3 * * *"),
+		Func:     cleanup,
+	})
+	...
+	err = s.Run(ctx) // runs jobs until ctx is done
+*/
+package sched
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"hypera.dev/lib/util/internal/panics"
+)
+
+var (
+	// ErrDuplicateJob is returned by [Scheduler.Add] when a job with the
+	// same name was already added.
+	ErrDuplicateJob = errors.New("sched: duplicate job name")
+
+	// ErrRunning is returned by [Scheduler.Run] when the scheduler is
+	// already running.
+	ErrRunning = errors.New("sched: scheduler already running")
+
+	// ErrStopping is returned by [Scheduler.Add] when the scheduler is
+	// waiting for runs in progress to finish before [Scheduler.Run]
+	// returns.
+	ErrStopping = errors.New("sched: scheduler stopping")
+
+	// ErrInvalidJob is returned by [Scheduler.Add] when a job has no
+	// schedule or function.
+	ErrInvalidJob = errors.New("sched: invalid job")
+)
+
+// PanicError describes a job that panicked, with the value passed to panic
+// and the stack trace.
+type PanicError = panics.Error
+
+// Overlap decides what happens when a job is due while it is still running.
+type Overlap int
+
+const (
+	// OverlapSkip skips the run.
+	OverlapSkip Overlap = iota
+	// OverlapQueue runs the job again once the current run finishes. At
+	// most one run is queued.
+	OverlapQueue
+	// OverlapConcurrent runs the job concurrently with the current run.
+	OverlapConcurrent
+)
+
+// Job is a function run by a [Scheduler].
+type Job struct {
+	// Name identifies the job, and must be unique within a scheduler.
+	Name string
+
+	// Schedule decides when the job runs.
+	Schedule Schedule
+
+	// Func is the function that is run. Its context is cancelled when the
+	// scheduler shuts down.
+	Func func(ctx context.Context) error
+
+	// Overlap decides what happens when the job is due while it is still
+	// running. Defaults to [OverlapSkip].
+	Overlap Overlap
+
+	// Jitter delays each run by a random duration up to Jitter, so that
+	// jobs scheduled at the same time on many instances don't all run at
+	// once.
+	Jitter time.Duration
+}
+
+// Hooks are called as jobs run, for example to record metrics. All hooks
+// are optional.
+type Hooks struct {
+	// OnStart is called before a job runs.
+	OnStart func(name string)
+
+	// OnFinish is called after a job runs, with the error it returned.
+	OnFinish func(name string, elapsed time.Duration, err error)
+
+	// OnSkip is called when a run is skipped because of [OverlapSkip].
+	OnSkip func(name string)
+}
+
+// Options configure a [Scheduler].
+type Options struct {
+	// Logger is used to log jobs that fail or are skipped. If nil, nothing
+	// is logged.
+	Logger *slog.Logger
+
+	// Hooks are called as jobs run.
+	Hooks Hooks
+}
+
+// job is a job added to a scheduler.
+type job struct {
+	Job
+	stop chan struct{}
+
+	mu      sync.Mutex
+	running int
+	queued  bool
+}
+
+// Scheduler runs jobs according to their schedules. A Scheduler is safe for
+// concurrent use.
+type Scheduler struct {
+	logger *slog.Logger
+	hooks  Hooks
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	jobs     map[string]*job
+	ctx      context.Context
+	stopping bool
+}
+
+// New returns a [Scheduler] configured with opts. If opts is nil, the
+// default options are used.
+func New(opts *Options) *Scheduler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Scheduler{
+		logger: opts.Logger,
+		hooks:  opts.Hooks,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Add adds a job to the scheduler. If the scheduler is running, the job is
+// scheduled immediately, otherwise it is scheduled when [Scheduler.Run] is
+// called. The first run is the first time returned by the job's schedule
+// after it is scheduled. Jobs cannot be added while [Scheduler.Run] is
+// waiting for runs in progress to finish.
+func (s *Scheduler) Add(j Job) error {
+	switch {
+	case j.Schedule == nil:
+		return fmt.Errorf("%w: %q has no schedule", ErrInvalidJob, j.Name)
+	case j.Func == nil:
+		return fmt.Errorf("%w: %q has no function", ErrInvalidJob, j.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		return ErrStopping
+	}
+	if _, ok := s.jobs[j.Name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateJob, j.Name)
+	}
+	jb := &job{Job: j, stop: make(chan struct{})}
+	s.jobs[j.Name] = jb
+	if s.ctx != nil {
+		s.start(s.ctx, jb)
+	}
+	return nil
+}
+
+// Remove removes the named job, so that it does not run again, and reports
+// whether it was found. Runs in progress are not stopped.
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if ok {
+		close(j.stop)
+		delete(s.jobs, name)
+	}
+	return ok
+}
+
+// Run schedules the jobs, and runs them until ctx is done. It then waits
+// for the runs in progress to finish, and returns nil.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ctx != nil || s.stopping {
+		s.mu.Unlock()
+		return ErrRunning
+	}
+	s.ctx = ctx
+	for _, j := range s.jobs {
+		s.start(ctx, j)
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+	s.mu.Lock()
+	s.ctx = nil
+	s.stopping = true
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.stopping = false
+	s.mu.Unlock()
+	return nil
+}
+
+// start starts scheduling a job. The lock must be held.
+func (s *Scheduler) start(ctx context.Context, j *job) {
+	s.wg.Add(1)
+	go s.loop(ctx, j)
+}
+
+// loop waits for each run of the job, until the job is removed, its schedule
+// ends, or ctx is done.
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+	schedule := j.Schedule
+	now := time.Now()
+	if st, ok := schedule.(starter); ok {
+		schedule = st.start(now)
+	}
+	next := schedule.Next(now)
+	for !next.IsZero() {
+		delay := time.Until(next)
+		if j.Jitter > 0 {
+			delay += rand.N(j.Jitter)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-j.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.trigger(ctx, j)
+		now = time.Now()
+		next = schedule.Next(next)
+		if !next.IsZero() && next.Before(now) {
+			// Skip runs that were missed, such as while the system was
+			// suspended
+			next = schedule.Next(now)
+		}
+	}
+}
+
+// trigger runs the job, according to its overlap policy.
+func (s *Scheduler) trigger(ctx context.Context, j *job) {
+	j.mu.Lock()
+	if j.running > 0 && j.Overlap != OverlapConcurrent {
+		skip := j.Overlap != OverlapQueue
+		if !skip {
+			j.queued = true
+		}
+		j.mu.Unlock()
+		if skip {
+			s.skipped(ctx, j)
+		}
+		return
+	}
+	j.running++
+	j.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.run(ctx, j)
+
+			j.mu.Lock()
+			if !j.queued || ctx.Err() != nil {
+				j.running--
+				j.mu.Unlock()
+				return
+			}
+			j.queued = false
+			j.mu.Unlock()
+		}
+	}()
+}
+
+// run runs the job once, calling the hooks and logging its error.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	if s.hooks.OnStart != nil {
+		s.hooks.OnStart(j.Name)
+	}
+	start := time.Now()
+	err := call(ctx, j.Func)
+	elapsed := time.Since(start)
+	if s.hooks.OnFinish != nil {
+		s.hooks.OnFinish(j.Name, elapsed, err)
+	}
+	if err != nil && s.logger != nil {
+		s.logger.LogAttrs(ctx, slog.LevelError, "scheduled job failed",
+			slog.String("job", j.Name),
+			slog.Duration("elapsed", elapsed),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// skipped reports a run that was skipped.
+func (s *Scheduler) skipped(ctx context.Context, j *job) {
+	if s.hooks.OnSkip != nil {
+		s.hooks.OnSkip(j.Name)
+	}
+	if s.logger != nil {
+		s.logger.LogAttrs(ctx, slog.LevelWarn, "scheduled job skipped, previous run still in progress",
+			slog.String("job", j.Name),
+		)
+	}
+}
+
+// call calls fn, converting a panic to a [*PanicError].
+func call(ctx context.Context, fn func(ctx context.Context) error) error {
+	return panics.Call(func() error {
+		return fn(ctx)
+	})
+}
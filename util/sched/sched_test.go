@@ -0,0 +1,293 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sched
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+// runFor runs s until d has passed.
+func runFor(t *testing.T, s *Scheduler, d time.Duration) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	if err := s.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+}
+
+func TestSchedulerEvery(t *testing.T) {
+	s := New(nil)
+	var runs atomic.Int32
+	if err := s.Add(Job{
+		Name:     "every",
+		Schedule: Every(10 * time.Millisecond),
+		Func: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	runFor(t, s, 105*time.Millisecond)
+	if n := runs.Load(); n < 3 || n > 10 {
+		t.Errorf("runs = %d, want about 10", n)
+	}
+}
+
+func TestSchedulerAfter(t *testing.T) {
+	s := New(nil)
+	var runs atomic.Int32
+	_ = s.Add(Job{
+		Name:     "once",
+		Schedule: After(10 * time.Millisecond),
+		Func: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	runFor(t, s, 50*time.Millisecond)
+	if n := runs.Load(); n != 1 {
+		t.Errorf("runs = %d, want 1", n)
+	}
+}
+
+func TestSchedulerAfterReused(t *testing.T) {
+	s := New(nil)
+	var runs atomic.Int32
+	schedule := After(0)
+	for _, name := range []string{"first", "second"} {
+		_ = s.Add(Job{
+			Name:     name,
+			Schedule: schedule,
+			Func: func(context.Context) error {
+				runs.Add(1)
+				return nil
+			},
+		})
+	}
+	runFor(t, s, 30*time.Millisecond)
+	runFor(t, s, 30*time.Millisecond)
+	if n := runs.Load(); n != 4 {
+		t.Errorf("runs = %d, want 4", n)
+	}
+}
+
+func TestSchedulerAt(t *testing.T) {
+	s := New(nil)
+	var runs atomic.Int32
+	_ = s.Add(Job{
+		Name:     "past",
+		Schedule: At(time.Now().Add(-time.Hour)),
+		Func: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	runFor(t, s, 30*time.Millisecond)
+	if n := runs.Load(); n != 1 {
+		t.Errorf("runs = %d, want 1", n)
+	}
+}
+
+func TestEveryNonPositive(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Every(0) did not panic")
+		}
+	}()
+	Every(0)
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		overlap     Overlap
+		runs, skips int32
+	}{
+		{name: "skip", overlap: OverlapSkip, runs: 1, skips: 3},
+		{name: "queue", overlap: OverlapQueue, runs: 2},
+		{name: "concurrent", overlap: OverlapConcurrent, runs: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs, skips atomic.Int32
+			s := New(&Options{Hooks: Hooks{
+				OnSkip: func(string) { skips.Add(1) },
+			}})
+			release := make(chan struct{})
+			started := make(chan struct{}, 1)
+			j := &job{Job: Job{
+				Name:    "slow",
+				Overlap: tt.overlap,
+				Func: func(context.Context) error {
+					if runs.Add(1) == 1 {
+						started <- struct{}{}
+					}
+					<-release
+					return nil
+				},
+			}}
+
+			ctx := context.Background()
+			s.trigger(ctx, j)
+			<-started
+			for i := 0; i < 3; i++ {
+				s.trigger(ctx, j)
+			}
+			close(release)
+			s.wg.Wait()
+
+			if n := runs.Load(); n != tt.runs {
+				t.Errorf("runs = %d, want %d", n, tt.runs)
+			}
+			if n := skips.Load(); n != tt.skips {
+				t.Errorf("skips = %d, want %d", n, tt.skips)
+			}
+		})
+	}
+}
+
+func TestSchedulerHooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	s := New(&Options{Hooks: Hooks{
+		OnStart: func(name string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, "start "+name)
+		},
+		OnFinish: func(name string, _ time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, "finish "+name+": "+err.Error())
+		},
+	}})
+	_ = s.Add(Job{
+		Name:     "panics",
+		Schedule: After(0),
+		Func: func(context.Context) error {
+			panic("boom")
+		},
+	})
+	runFor(t, s, 20*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0] != "start panics" || events[1][:15] != "finish panics: " {
+		t.Errorf("events = %q", events)
+	}
+}
+
+func TestSchedulerAddRemove(t *testing.T) {
+	s := New(nil)
+	job := Job{Name: "job", Schedule: Every(time.Hour), Func: func(context.Context) error { return errTest }}
+	if err := s.Add(job); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.Add(job); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("Add() duplicate error = %v, want %v", err, ErrDuplicateJob)
+	}
+	if err := s.Add(Job{Name: "nil", Func: job.Func}); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("Add() without schedule error = %v, want %v", err, ErrInvalidJob)
+	}
+	if err := s.Add(Job{Name: "nil", Schedule: job.Schedule}); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("Add() without func error = %v, want %v", err, ErrInvalidJob)
+	}
+	if !s.Remove("job") || s.Remove("job") {
+		t.Error("Remove() did not remove the job exactly once")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	var runs atomic.Int32
+	for i := 0; ; i++ {
+		err := s.Add(Job{Name: "added", Schedule: After(0), Func: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		}})
+		if err != nil {
+			t.Fatalf("Add() while running error = %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if runs.Load() > 0 || i > 100 {
+			break
+		}
+		s.Remove("added")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("runs = %d, want 1", runs.Load())
+	}
+}
+
+func TestSchedulerAddWhileStopping(t *testing.T) {
+	s := New(nil)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = s.Add(Job{Name: "slow", Schedule: After(0), Func: func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	<-started
+	cancel()
+
+	job := Job{Name: "late", Schedule: After(0), Func: func(context.Context) error { return nil }}
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = s.Add(job); errors.Is(err, ErrStopping) {
+			break
+		}
+		s.Remove("late")
+		time.Sleep(time.Millisecond)
+	}
+	if !errors.Is(err, ErrStopping) {
+		t.Errorf("Add() while stopping error = %v, want %v", err, ErrStopping)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if err := s.Add(job); err != nil {
+		t.Errorf("Add() after Run error = %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sched
+
+import (
+	"time"
+)
+
+// Schedule decides when a job runs.
+type Schedule interface {
+	// Next returns the time of the first run after the given time, or the
+	// zero time if the job should not run again.
+	Next(after time.Time) time.Time
+}
+
+// ScheduleFunc is a function that implements [Schedule].
+type ScheduleFunc func(after time.Time) time.Time
+
+// Next implements [Schedule].
+func (f ScheduleFunc) Next(after time.Time) time.Time {
+	return f(after)
+}
+
+// Every returns a [Schedule] that runs a job at a fixed interval, starting
+// one interval after the job is added. Every panics if interval is not
+// positive.
+func Every(interval time.Duration) Schedule {
+	if interval <= 0 {
+		panic("sched: non-positive interval for Every")
+	}
+	return ScheduleFunc(func(after time.Time) time.Time {
+		return after.Add(interval)
+	})
+}
+
+// After returns a [Schedule] that runs a job once, delay after the job is
+// scheduled.
+func After(delay time.Duration) Schedule {
+	return oneShot(func(start time.Time) time.Time {
+		return start.Add(delay)
+	})
+}
+
+// At returns a [Schedule] that runs a job once at t. If t has passed when
+// the job is scheduled, it runs immediately.
+func At(t time.Time) Schedule {
+	return oneShot(func(time.Time) time.Time {
+		return t
+	})
+}
+
+// starter is implemented by schedules that keep state for each job. A
+// [Scheduler] calls start each time it schedules a job, and uses the
+// returned schedule for the job.
+type starter interface {
+	start(now time.Time) Schedule
+}
+
+// oneShot is a schedule that runs a job once, at a time relative to when the
+// job is scheduled.
+type oneShot func(start time.Time) time.Time
+
+// Next implements [Schedule], returning the time of the run for a job
+// scheduled at after.
+func (f oneShot) Next(after time.Time) time.Time {
+	return f(after)
+}
+
+func (f oneShot) start(now time.Time) Schedule {
+	next := f(now)
+	return ScheduleFunc(func(time.Time) time.Time {
+		t := next
+		next = time.Time{}
+		return t
+	})
+}
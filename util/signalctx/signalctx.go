@@ -0,0 +1,180 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package signalctx provides contexts that are cancelled by a signal, and that
+force the program to exit on a second signal or after a timeout.
+
+	ctx, stop := signalctx.NotifyContext(context.Background(), &signalctx.Options{
+		ForceTimeout: 30 * time.Second,
+	})
+	defer stop()
+*/
+package signalctx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SignalError is the cause of a context cancelled by a signal.
+type SignalError struct {
+	// Signal is the signal that was received.
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("signalctx: received signal %v", e.Signal)
+}
+
+// Options configure [NotifyContext].
+type Options struct {
+	// Signals are the signals that cancel the context. Defaults to
+	// [os.Interrupt] and [syscall.SIGTERM].
+	Signals []os.Signal
+
+	// ForceTimeout is the time after the first signal after which the
+	// program is forced to exit. If zero, only a second signal forces the
+	// program to exit.
+	ForceTimeout time.Duration
+
+	// DisableForce disables forcing the program to exit. A second signal is
+	// ignored, after being passed to OnSignal.
+	DisableForce bool
+
+	// ExitCode is the exit code used when forcing the program to exit.
+	// Defaults to 1.
+	ExitCode int
+
+	// OnSignal is called with each signal received.
+	OnSignal func(sig os.Signal)
+
+	// OnForce is called before the program is forced to exit. sig is nil if
+	// the exit is caused by ForceTimeout.
+	OnForce func(sig os.Signal)
+}
+
+// NotifyContext returns a copy of parent that is cancelled when one of the
+// signals is received, when stop is called, or when parent is done. The
+// cause of a context cancelled by a signal is a [*SignalError].
+//
+// Once the context is cancelled by a signal, a second signal or the
+// ForceTimeout forces the program to exit, unless DisableForce is set.
+// Calling stop stops the relay of signals and the force timeout.
+func NotifyContext(parent context.Context, opts *Options) (context.Context, context.CancelFunc) {
+	return notifyContext(parent, opts, signal.Notify, signal.Stop, os.Exit)
+}
+
+func notifyContext(
+	parent context.Context,
+	opts *Options,
+	notify func(c chan<- os.Signal, sig ...os.Signal),
+	stopNotify func(c chan<- os.Signal),
+	exit func(code int),
+) (context.Context, context.CancelFunc) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	signals := opts.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	code := opts.ExitCode
+	if code == 0 {
+		code = 1
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	ch := make(chan os.Signal, 1)
+	notify(ch, signals...)
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+
+	force := func(sig os.Signal) {
+		if opts.OnForce != nil {
+			opts.OnForce(sig)
+		}
+		exit(code)
+	}
+
+	go func() {
+		defer close(done)
+		defer stopNotify(ch)
+
+		var timer *time.Timer
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		var timeout <-chan time.Time
+		parentDone := parent.Done()
+		received := false
+		for {
+			select {
+			case sig := <-ch:
+				if opts.OnSignal != nil {
+					opts.OnSignal(sig)
+				}
+				if !received {
+					received = true
+					cancel(&SignalError{Signal: sig})
+					if opts.ForceTimeout > 0 && !opts.DisableForce {
+						timer = time.NewTimer(opts.ForceTimeout)
+						timeout = timer.C
+					}
+					continue
+				}
+				if !opts.DisableForce {
+					force(sig)
+					return
+				}
+			case <-timeout:
+				force(nil)
+				return
+			case <-parentDone:
+				if !received {
+					return
+				}
+				// Keep forcing on a second signal while shutting down.
+				parentDone = nil
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	stop := func() {
+		stopOnce.Do(func() { close(stopped) })
+		<-done
+		cancel(nil)
+	}
+	return ctx, stop
+}
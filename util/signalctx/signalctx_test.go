@@ -0,0 +1,130 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package signalctx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeSignals replaces signal.Notify and signal.Stop in tests.
+type fakeSignals struct {
+	ch      chan<- os.Signal
+	stopped chan struct{}
+}
+
+func (f *fakeSignals) notify(c chan<- os.Signal, _ ...os.Signal) {
+	f.ch = c
+}
+
+func (f *fakeSignals) stop(chan<- os.Signal) {
+	close(f.stopped)
+}
+
+func setup(t *testing.T, opts *Options) (context.Context, context.CancelFunc, *fakeSignals, chan int) {
+	t.Helper()
+	f := &fakeSignals{stopped: make(chan struct{})}
+	exits := make(chan int, 1)
+	ctx, stop := notifyContext(context.Background(), opts, f.notify, f.stop, func(code int) {
+		exits <- code
+	})
+	t.Cleanup(stop)
+	return ctx, stop, f, exits
+}
+
+func TestNotifyContext(t *testing.T) {
+	var signals []os.Signal
+	forced := make(chan os.Signal, 1)
+	ctx, _, f, exits := setup(t, &Options{
+		ExitCode: 130,
+		OnSignal: func(sig os.Signal) { signals = append(signals, sig) },
+		OnForce:  func(sig os.Signal) { forced <- sig },
+	})
+
+	f.ch <- os.Interrupt
+	<-ctx.Done()
+	var serr *SignalError
+	if !errors.As(context.Cause(ctx), &serr) || serr.Signal != os.Interrupt {
+		t.Errorf("Cause() = %v, want SignalError for %v", context.Cause(ctx), os.Interrupt)
+	}
+
+	f.ch <- syscall.SIGTERM
+	if code := <-exits; code != 130 {
+		t.Errorf("exit code = %d, want 130", code)
+	}
+	if sig := <-forced; sig != syscall.SIGTERM {
+		t.Errorf("OnForce() signal = %v, want %v", sig, syscall.SIGTERM)
+	}
+	if len(signals) != 2 {
+		t.Errorf("OnSignal() called %d times, want 2", len(signals))
+	}
+}
+
+func TestNotifyContextForceTimeout(t *testing.T) {
+	ctx, _, f, exits := setup(t, &Options{ForceTimeout: 10 * time.Millisecond})
+	f.ch <- os.Interrupt
+	<-ctx.Done()
+	select {
+	case code := <-exits:
+		if code != 1 {
+			t.Errorf("exit code = %d, want 1", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not forced to exit after ForceTimeout")
+	}
+}
+
+func TestNotifyContextDisableForce(t *testing.T) {
+	ctx, stop, f, exits := setup(t, &Options{DisableForce: true, ForceTimeout: time.Millisecond})
+	f.ch <- os.Interrupt
+	<-ctx.Done()
+	f.ch <- os.Interrupt
+	time.Sleep(10 * time.Millisecond)
+	stop()
+	select {
+	case <-exits:
+		t.Error("forced to exit with DisableForce")
+	default:
+	}
+}
+
+func TestNotifyContextStop(t *testing.T) {
+	ctx, stop, f, exits := setup(t, nil)
+	stop()
+	stop()
+	<-f.stopped
+	if !errors.Is(context.Cause(ctx), context.Canceled) {
+		t.Errorf("Cause() = %v, want %v", context.Cause(ctx), context.Canceled)
+	}
+	select {
+	case <-exits:
+		t.Error("forced to exit after stop")
+	default:
+	}
+}
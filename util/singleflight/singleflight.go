@@ -0,0 +1,174 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package singleflight provides duplicate call suppression, so that concurrent
+calls for the same key share the result of a single call.
+
+Unlike golang.org/x/sync/singleflight, results are typed, and callers whose
+context is done stop waiting without cancelling the call for the other
+callers.
+*/
+package singleflight
+
+import (
+	"context"
+	"sync"
+
+	"hypera.dev/lib/util/internal/panics"
+)
+
+// PanicError is returned to every caller when the shared function panics.
+// It holds the value passed to panic and the stack trace.
+type PanicError = panics.Error
+
+// Result is the result of a call, delivered by [Group.DoChan].
+type Result[V any] struct {
+	// Val is the value returned by the function.
+	Val V
+
+	// Err is the error returned by the function, or the context's error if
+	// the caller stopped waiting.
+	Err error
+
+	// Shared reports whether the result was given to multiple callers.
+	Shared bool
+}
+
+// call is an in-flight or completed call.
+type call[V any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	val     V
+	err     error
+	waiters int
+	dups    int
+}
+
+// Group deduplicates calls with the same key. The zero value is ready to
+// use, and a Group is safe for concurrent use.
+type Group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+// Do calls fn and returns its results, making sure that only one call for
+// the key is in flight at a time. If a call for the key is already in
+// flight, Do waits for it and returns its results instead.
+//
+// fn is given a context that carries the values of the context of the
+// caller that started the call, but is only cancelled once every caller
+// waiting for the call has stopped waiting. If ctx is done before the call
+// finishes, Do returns the context's error.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (V, error) {
+	c := g.join(ctx, key, fn)
+	r := g.wait(ctx, key, c)
+	return r.Val, r.Err
+}
+
+// DoChan is like [Group.Do], but returns a channel that receives the result
+// once it is ready. The channel is not closed.
+func (g *Group[K, V]) DoChan(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) <-chan Result[V] {
+	ch := make(chan Result[V], 1)
+	c := g.join(ctx, key, fn)
+	go func() {
+		ch <- g.wait(ctx, key, c)
+	}()
+	return ch
+}
+
+// Forget makes the next call for the key call its function, rather than
+// waiting for an earlier call to finish. The earlier call is not cancelled.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.calls, key)
+}
+
+// join returns the in-flight call for the key, starting it if needed.
+func (g *Group[K, V]) join(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) *call[V] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.calls[key]; ok {
+		c.waiters++
+		c.dups++
+		return c
+	}
+
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c := &call[V]{
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		waiters: 1,
+	}
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	g.calls[key] = c
+	go g.run(callCtx, key, c, fn)
+	return c
+}
+
+// run calls fn and stores its results in c.
+func (g *Group[K, V]) run(ctx context.Context, key K, c *call[V], fn func(ctx context.Context) (V, error)) {
+	defer func() {
+		c.cancel()
+
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.err = panics.Call(func() error {
+		var err error
+		c.val, err = fn(ctx)
+		return err
+	})
+}
+
+// wait waits for c to finish or ctx to be done. If ctx is done and no other
+// callers are waiting, the call is cancelled.
+func (g *Group[K, V]) wait(ctx context.Context, key K, c *call[V]) Result[V] {
+	select {
+	case <-c.done:
+		g.mu.Lock()
+		shared := c.dups > 0
+		g.mu.Unlock()
+		return Result[V]{Val: c.val, Err: c.err, Shared: shared}
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c.waiters--
+	if c.waiters == 0 {
+		c.cancel()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+	}
+	return Result[V]{Err: ctx.Err()}
+}
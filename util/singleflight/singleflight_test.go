@@ -0,0 +1,146 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroupDo(t *testing.T) {
+	var g Group[string, int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan Result[int], 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- <-g.DoChan(context.Background(), "key", fn)
+		}()
+	}
+	// Wait for every caller to join the call
+	for {
+		g.mu.Lock()
+		c := g.calls["key"]
+		joined := c != nil && c.waiters == 10
+		g.mu.Unlock()
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for r := range results {
+		if r.Val != 42 || r.Err != nil || !r.Shared {
+			t.Errorf("result = %+v, want 42, nil, shared", r)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("calls = %d, want 1", n)
+	}
+}
+
+func TestGroupCancel(t *testing.T) {
+	var g Group[string, int]
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	ch1 := g.DoChan(ctx1, "key", fn)
+	<-started
+	ch2 := g.DoChan(ctx2, "key", fn)
+
+	cancel1()
+	if r := <-ch1; !errors.Is(r.Err, context.Canceled) {
+		t.Errorf("first caller error = %v, want %v", r.Err, context.Canceled)
+	}
+	select {
+	case <-cancelled:
+		t.Fatal("call cancelled while a caller is still waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel2()
+	<-ch2
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("call not cancelled after the last caller stopped waiting")
+	}
+}
+
+func TestGroupForget(t *testing.T) {
+	var g Group[string, int]
+	release := make(chan struct{})
+	ch := g.DoChan(context.Background(), "key", func(context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+	g.Forget("key")
+
+	v, err := g.Do(context.Background(), "key", func(context.Context) (int, error) {
+		return 2, nil
+	})
+	if v != 2 || err != nil {
+		t.Errorf("Do() = %v, %v, want 2, nil", v, err)
+	}
+	close(release)
+	if r := <-ch; r.Val != 1 || r.Shared {
+		t.Errorf("forgotten call result = %+v, want 1, not shared", r)
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	var g Group[int, int]
+	_, err := g.Do(context.Background(), 1, func(context.Context) (int, error) {
+		panic("boom")
+	})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Do() error = %v, want PanicError", err)
+	}
+}
@@ -0,0 +1,352 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package supervisor runs long-lived goroutines, such as consumers and
+pollers, and restarts them when they fail.
+
+	s := supervisor.New(&supervisor.Options{Logger: logger})
+	err := s.Add(supervisor.Task{
+		Name: "consumer",
+		Func: consume,
+	})
+	...
+	err = s.Run(ctx) // runs tasks until ctx is done
+*/
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"hypera.dev/lib/util/internal/panics"
+	"hypera.dev/lib/util/retry"
+)
+
+var (
+	// ErrDuplicateTask is returned by [Supervisor.Add] when a task with the
+	// same name was already added.
+	ErrDuplicateTask = errors.New("supervisor: duplicate task name")
+
+	// ErrRunning is returned by [Supervisor.Run] when the supervisor is
+	// already running.
+	ErrRunning = errors.New("supervisor: supervisor already running")
+)
+
+// DefaultResetAfter is the default value of [Task.ResetAfter].
+const DefaultResetAfter = time.Minute
+
+// PanicError describes a task that panicked, with the value passed to panic
+// and the stack trace.
+type PanicError = panics.Error
+
+// Restart decides when a task is restarted.
+type Restart int
+
+const (
+	// RestartOnFailure restarts the task when it returns an error or
+	// panics. A task that returns nil is not restarted.
+	RestartOnFailure Restart = iota
+	// RestartAlways restarts the task whenever it returns.
+	RestartAlways
+)
+
+// State is the state of a task.
+type State int
+
+const (
+	// StateIdle is the state of a task that has not been started.
+	StateIdle State = iota
+	// StateRunning is the state of a running task.
+	StateRunning
+	// StateBackoff is the state of a task waiting to be restarted.
+	StateBackoff
+	// StateStopped is the state of a task that returned without needing a
+	// restart, or that was stopped by the supervisor.
+	StateStopped
+	// StateFailed is the state of a task that is not restarted because its
+	// backoff stopped.
+	StateFailed
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateRunning:
+		return "running"
+	case StateBackoff:
+		return "backoff"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Task is a long-lived function run by a [Supervisor].
+type Task struct {
+	// Name identifies the task, and must be unique within a supervisor.
+	Name string
+
+	// Func is the function that is run. Its context is cancelled when the
+	// supervisor shuts down or the task is removed.
+	Func func(ctx context.Context) error
+
+	// Restart decides when the task is restarted. Defaults to
+	// [RestartOnFailure].
+	Restart Restart
+
+	// Backoff returns the backoff used to wait between restarts. If the
+	// backoff returns [retry.Stop], the task is not restarted. If nil, an
+	// exponential backoff that never stops is used.
+	Backoff retry.BackoffFactory
+
+	// ResetAfter is how long the task must run for its backoff to be
+	// reset. Defaults to [DefaultResetAfter].
+	ResetAfter time.Duration
+}
+
+// Status is the status of a task.
+type Status struct {
+	// Name is the name of the task.
+	Name string
+
+	// State is the state of the task.
+	State State
+
+	// Restarts is the number of times the task has been restarted.
+	Restarts int
+
+	// LastStart is the time the task was last started.
+	LastStart time.Time
+
+	// LastError is the last error returned by the task.
+	LastError error
+}
+
+// Options configure a [Supervisor].
+type Options struct {
+	// Logger is used to log tasks that fail. If nil, nothing is logged.
+	Logger *slog.Logger
+}
+
+// task is a task added to a supervisor.
+type task struct {
+	Task
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Supervisor runs tasks, and restarts them when they fail. A Supervisor is
+// safe for concurrent use.
+type Supervisor struct {
+	logger *slog.Logger
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	tasks    []*task
+	ctx      context.Context
+	stopping bool
+}
+
+// New returns a [Supervisor] configured with opts. If opts is nil, the
+// default options are used.
+func New(opts *Options) *Supervisor {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Supervisor{logger: opts.Logger}
+}
+
+// Add adds a task to the supervisor. If the supervisor is running, the task
+// is started immediately, otherwise it is started when [Supervisor.Run] is
+// next called, including when Run is waiting for tasks to return.
+func (s *Supervisor) Add(t Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range s.tasks {
+		if other.Name == t.Name {
+			return fmt.Errorf("%w: %q", ErrDuplicateTask, t.Name)
+		}
+	}
+	tk := &task{Task: t, status: Status{Name: t.Name}}
+	s.tasks = append(s.tasks, tk)
+	if s.ctx != nil {
+		s.start(s.ctx, tk)
+	}
+	return nil
+}
+
+// Remove stops and removes the named task, and reports whether it was
+// found. It does not wait for the task to return.
+func (s *Supervisor) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.tasks {
+		if t.Name == name {
+			if t.cancel != nil {
+				t.cancel()
+			}
+			s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the status of each task, in the order they were added.
+func (s *Supervisor) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]Status, len(s.tasks))
+	for i, t := range s.tasks {
+		t.mu.Lock()
+		statuses[i] = t.status
+		t.mu.Unlock()
+	}
+	return statuses
+}
+
+// Run starts the tasks, and supervises them until ctx is done. It then
+// waits for the tasks to return, and returns nil.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ctx != nil || s.stopping {
+		s.mu.Unlock()
+		return ErrRunning
+	}
+	s.ctx = ctx
+	for _, t := range s.tasks {
+		s.start(ctx, t)
+	}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+	// Tasks added from now on are only started by the next run
+	s.mu.Lock()
+	s.ctx = nil
+	s.stopping = true
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.stopping = false
+	s.mu.Unlock()
+	return nil
+}
+
+// start starts supervising a task. The lock must be held.
+func (s *Supervisor) start(ctx context.Context, t *task) {
+	ctx, t.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.supervise(ctx, t)
+}
+
+// supervise runs the task, restarting it until it stops or ctx is done.
+func (s *Supervisor) supervise(ctx context.Context, t *task) {
+	defer s.wg.Done()
+	defer t.cancel()
+
+	var b retry.Backoff
+	if t.Backoff != nil {
+		b = t.Backoff()
+	} else {
+		b = retry.Forever(retry.NewExponentialBackoff())
+	}
+	resetAfter := t.ResetAfter
+	if resetAfter <= 0 {
+		resetAfter = DefaultResetAfter
+	}
+
+	for {
+		start := time.Now()
+		t.update(func(st *Status) {
+			st.State = StateRunning
+			st.LastStart = start
+		})
+		err := run(ctx, t.Func)
+		if err != nil {
+			t.update(func(st *Status) { st.LastError = err })
+		}
+		if ctx.Err() != nil || (err == nil && t.Restart != RestartAlways) {
+			t.update(func(st *Status) { st.State = StateStopped })
+			return
+		}
+
+		if time.Since(start) >= resetAfter {
+			if r, ok := b.(retry.ResettableBackoff); ok {
+				r.Reset()
+			}
+		}
+		delay := b.Next()
+		if delay == retry.Stop {
+			t.update(func(st *Status) { st.State = StateFailed })
+			if s.logger != nil {
+				s.logger.LogAttrs(ctx, slog.LevelError, "supervised task failed, not restarting",
+					slog.String("task", t.Name), slog.Any("error", err))
+			}
+			return
+		}
+
+		t.update(func(st *Status) { st.State = StateBackoff })
+		if s.logger != nil && err != nil {
+			s.logger.LogAttrs(ctx, slog.LevelWarn, "supervised task failed, restarting",
+				slog.String("task", t.Name), slog.Duration("delay", delay), slog.Any("error", err))
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			t.update(func(st *Status) { st.State = StateStopped })
+			return
+		case <-timer.C:
+		}
+		t.update(func(st *Status) { st.Restarts++ })
+	}
+}
+
+// update updates the status of the task.
+func (t *task) update(fn func(st *Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.status)
+}
+
+// run calls fn, converting a panic to a [*PanicError].
+func run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return panics.Call(func() error {
+		return fn(ctx)
+	})
+}
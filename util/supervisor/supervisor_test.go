@@ -0,0 +1,223 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hypera.dev/lib/util/retry"
+)
+
+var errTest = errors.New("test error")
+
+func fastBackoff() retry.Backoff {
+	return retry.Forever(retry.NewConstantBackoff(time.Millisecond))
+}
+
+// waitFor waits until the status of the named task satisfies cond.
+func waitFor(t *testing.T, s *Supervisor, name string, cond func(st Status) bool) Status {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, st := range s.Status() {
+			if st.Name == name && cond(st) {
+				return st
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("task %q did not reach the expected status: %+v", name, s.Status())
+	return Status{}
+}
+
+func TestRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	s := New(nil)
+	err := s.Add(Task{
+		Name: "flaky",
+		Func: func(context.Context) error {
+			switch calls.Add(1) {
+			case 1:
+				return errTest
+			case 2:
+				panic("boom")
+			default:
+				return nil
+			}
+		},
+		Backoff: fastBackoff,
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.Add(Task{Name: "flaky"}); !errors.Is(err, ErrDuplicateTask) {
+		t.Errorf("Add() duplicate error = %v, want %v", err, ErrDuplicateTask)
+	}
+
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	st := waitFor(t, s, "flaky", func(st Status) bool { return st.State == StateStopped })
+	if st.Restarts != 2 {
+		t.Errorf("Restarts = %d, want 2", st.Restarts)
+	}
+	var perr *PanicError
+	if !errors.As(st.LastError, &perr) {
+		t.Errorf("LastError = %v, want PanicError", st.LastError)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+func TestRestartAlways(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	s := New(nil)
+	go func() { done <- s.Run(ctx) }()
+
+	// Added while running
+	var calls atomic.Int32
+	_ = s.Add(Task{
+		Name:    "poller",
+		Restart: RestartAlways,
+		Func: func(context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+		Backoff: fastBackoff,
+	})
+	waitFor(t, s, "poller", func(st Status) bool { return st.Restarts >= 3 })
+
+	cancel()
+	<-done
+	if st := s.Status()[0]; st.State != StateStopped {
+		t.Errorf("State = %v, want %v", st.State, StateStopped)
+	}
+}
+
+func TestBackoffStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New(nil)
+	_ = s.Add(Task{
+		Name:    "broken",
+		Func:    func(context.Context) error { return errTest },
+		Backoff: func() retry.Backoff { return retry.N(2, time.Millisecond) },
+	})
+	go s.Run(ctx)
+
+	st := waitFor(t, s, "broken", func(st Status) bool { return st.State == StateFailed })
+	if st.Restarts != 2 || !errors.Is(st.LastError, errTest) {
+		t.Errorf("Status = %+v, want 2 restarts with %v", st, errTest)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan struct{})
+	s := New(nil)
+	_ = s.Add(Task{
+		Name: "consumer",
+		Func: func(ctx context.Context) error {
+			<-ctx.Done()
+			close(stopped)
+			return ctx.Err()
+		},
+	})
+	go s.Run(ctx)
+	waitFor(t, s, "consumer", func(st Status) bool { return st.State == StateRunning })
+
+	if !s.Remove("consumer") {
+		t.Fatal("Remove() = false, want true")
+	}
+	<-stopped
+	if s.Remove("consumer") {
+		t.Error("Remove() = true for removed task")
+	}
+	if n := len(s.Status()); n != 0 {
+		t.Errorf("len(Status()) = %d, want 0", n)
+	}
+}
+
+func TestAddWhileStopping(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	s := New(nil)
+	_ = s.Add(Task{
+		Name: "slow",
+		Func: func(context.Context) error {
+			<-release
+			return nil
+		},
+	})
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	waitFor(t, s, "slow", func(st Status) bool { return st.State == StateRunning })
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.mu.Lock()
+		stopping := s.stopping
+		s.mu.Unlock()
+		if stopping || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var started atomic.Bool
+	late := Task{Name: "late", Func: func(ctx context.Context) error {
+		started.Store(true)
+		<-ctx.Done()
+		return nil
+	}}
+	if err := s.Add(late); err != nil {
+		t.Fatalf("Add() while stopping error = %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if started.Load() {
+		t.Error("task added while stopping was started")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	waitFor(t, s, "late", func(st Status) bool { return st.State == StateRunning })
+}
@@ -0,0 +1,211 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package taskgroup runs groups of named tasks concurrently and waits for them
+to finish, like golang.org/x/sync/errgroup, with limits on parallelism,
+recovery from panics and names in errors.
+
+	g, ctx := taskgroup.New(ctx, &taskgroup.Options{Limit: 4})
+	for _, url := range urls {
+		g.Go(url, func(ctx context.Context) error {
+			return fetch(ctx, url)
+		})
+	}
+	err := g.Wait()
+*/
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"hypera.dev/lib/util/internal/panics"
+)
+
+// TaskError is an error returned by a task, with the name of the task.
+type TaskError struct {
+	// Name is the name of the task.
+	Name string
+
+	// Err is the error returned by the task, or a [*PanicError] if it
+	// panicked.
+	Err error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("taskgroup: task %q: %v", e.Name, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError describes a task that panicked, with the value passed to panic
+// and the stack trace.
+type PanicError = panics.Error
+
+// Options configure a [Group].
+type Options struct {
+	// Limit is the maximum number of tasks that run at the same time. If
+	// zero, the number of tasks is not limited.
+	Limit int
+
+	// CollectAll makes the group run every task even if some fail, and
+	// return all of their errors. By default, the first task to fail
+	// cancels the context of the group, and only its error is returned.
+	CollectAll bool
+}
+
+// Group is a group of tasks. A Group must be created with [New], and is
+// safe for concurrent use.
+type Group struct {
+	ctx        context.Context
+	cancel     context.CancelCauseFunc
+	sem        chan struct{}
+	collectAll bool
+	wg         sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// New returns a [Group] configured with opts, and a context derived from
+// ctx that is passed to its tasks. Unless [Options.CollectAll] is set, the
+// context is cancelled when a task fails. It is always cancelled once
+// [Group.Wait] returns. If opts is nil, the default options are used.
+func New(ctx context.Context, opts *Options) (*Group, context.Context) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{
+		ctx:        ctx,
+		cancel:     cancel,
+		collectAll: opts.CollectAll,
+	}
+	if opts.Limit > 0 {
+		g.sem = make(chan struct{}, opts.Limit)
+	}
+	return g, ctx
+}
+
+// Go runs fn in a new goroutine with the context of the group, blocking
+// until it is allowed to start by [Options.Limit]. The name identifies the
+// task in errors.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(name, fn)
+}
+
+// TryGo is like [Group.Go], but does not block. It reports whether the task
+// was started.
+func (g *Group) TryGo(name string, fn func(ctx context.Context) error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(name, fn)
+	return true
+}
+
+func (g *Group) start(name string, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			if g.sem != nil {
+				<-g.sem
+			}
+			g.wg.Done()
+		}()
+		if err := run(g.ctx, fn); err != nil {
+			g.fail(&TaskError{Name: name, Err: err})
+		}
+	}()
+}
+
+// run calls fn, converting a panic to a [*PanicError].
+func run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return panics.Call(func() error {
+		return fn(ctx)
+	})
+}
+
+// fail records the error of a task.
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.collectAll && len(g.errs) > 0 {
+		return
+	}
+	g.errs = append(g.errs, err)
+	if !g.collectAll {
+		g.cancel(err)
+	}
+}
+
+// Wait waits for all tasks to finish, and returns the error of the first
+// task that failed, or the errors of all tasks that failed joined with
+// [errors.Join] if [Options.CollectAll] is set. Errors are [*TaskError]
+// values.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err()
+}
+
+// WaitContext is like [Group.Wait], but returns the context's error if ctx
+// is done before the tasks finish. The tasks are not stopped, but the
+// context of the group is cancelled.
+func (g *Group) WaitContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		g.cancel(nil)
+		return g.err()
+	case <-ctx.Done():
+		g.cancel(ctx.Err())
+		return ctx.Err()
+	}
+}
+
+func (g *Group) err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) == 1 {
+		return g.errs[0]
+	}
+	return errors.Join(g.errs...)
+}
@@ -0,0 +1,150 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test error")
+
+func TestGroupFirstError(t *testing.T) {
+	g, ctx := New(context.Background(), nil)
+	g.Go("fails", func(context.Context) error {
+		return errTest
+	})
+	g.Go("waits", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) || taskErr.Name != "fails" || !errors.Is(err, errTest) {
+		t.Errorf("Wait() error = %v, want error from task %q", err, "fails")
+	}
+	if !errors.Is(context.Cause(ctx), errTest) {
+		t.Errorf("context cause = %v, want %v", context.Cause(ctx), errTest)
+	}
+}
+
+func TestGroupCollectAll(t *testing.T) {
+	g, ctx := New(context.Background(), &Options{CollectAll: true})
+	for _, name := range []string{"a", "b", "c"} {
+		g.Go(name, func(context.Context) error {
+			if name == "b" {
+				return nil
+			}
+			return errTest
+		})
+	}
+	err := g.Wait()
+	var names []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var taskErr *TaskError
+		if errors.As(err, &taskErr) {
+			names = append(names, taskErr.Name)
+		}
+	}
+	if len(names) != 2 {
+		t.Errorf("Wait() error = %v, want errors from a and c", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("context not cancelled after Wait")
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	g, _ := New(context.Background(), nil)
+	g.Go("panics", func(context.Context) error {
+		panic("boom")
+	})
+	var panicErr *PanicError
+	if err := g.Wait(); !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Wait() error = %v, want PanicError", err)
+	}
+}
+
+func TestGroupLimit(t *testing.T) {
+	g, _ := New(context.Background(), &Options{Limit: 2})
+	var running, maxRunning atomic.Int32
+	for i := 0; i < 10; i++ {
+		g.Go("task", func(context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if m := maxRunning.Load(); m > 2 {
+		t.Errorf("max running = %d, want at most 2", m)
+	}
+}
+
+func TestGroupTryGo(t *testing.T) {
+	g, _ := New(context.Background(), &Options{Limit: 1})
+	release := make(chan struct{})
+	if !g.TryGo("first", func(context.Context) error {
+		<-release
+		return nil
+	}) {
+		t.Fatal("TryGo() = false, want true")
+	}
+	if g.TryGo("second", func(context.Context) error { return nil }) {
+		t.Error("TryGo() = true at limit, want false")
+	}
+	close(release)
+	_ = g.Wait()
+}
+
+func TestGroupWaitContext(t *testing.T) {
+	g, groupCtx := New(context.Background(), nil)
+	g.Go("waits", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := g.WaitContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if groupCtx.Err() == nil {
+		t.Error("group context not cancelled")
+	}
+	_ = g.Wait()
+}
@@ -0,0 +1,178 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package throttle limits how often a function is called, calling it at most
+once per interval however often it is requested. This is useful for work
+triggered by frequent events, such as invalidating a cache.
+
+	invalidate := throttle.New(func(struct{}) { ... }, time.Second, nil)
+	for range events {
+		invalidate.Call(struct{}{})
+	}
+*/
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Options configure a [Throttler].
+type Options struct {
+	// DisableLeading disables calling the function immediately when it is
+	// called for the first time in an interval, so that calls are only made
+	// at the end of intervals.
+	DisableLeading bool
+
+	// DisableTrailing disables calling the function at the end of an
+	// interval in which calls were made, so that calls made while the
+	// function is throttled are dropped.
+	DisableTrailing bool
+}
+
+// Throttler calls a function at most once per interval. A Throttler is safe
+// for concurrent use, and the function is never called concurrently.
+type Throttler[T any] struct {
+	f        func(T)
+	interval time.Duration
+	leading  bool
+	trailing bool
+	callMu   sync.Mutex
+
+	mu         sync.Mutex
+	timer      *time.Timer
+	generation uint64
+	pending    bool
+	value      T
+}
+
+// New returns a [Throttler] that calls f at most once per interval. By
+// default, f is called immediately if it was not called in the last
+// interval, and otherwise once the interval ends, with the value of the last
+// call. If opts is nil, the default options are used.
+func New[T any](f func(T), interval time.Duration, opts *Options) *Throttler[T] {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Throttler[T]{
+		f:        f,
+		interval: interval,
+		leading:  !opts.DisableLeading,
+		trailing: !opts.DisableTrailing,
+	}
+}
+
+// Call requests a call to the function with v, which is made immediately or
+// once the current interval ends.
+func (t *Throttler[T]) Call(v T) {
+	t.mu.Lock()
+	leading := t.timer == nil && t.leading
+	if t.timer == nil {
+		t.start()
+	}
+	if !leading && t.trailing {
+		t.pending = true
+		t.value = v
+	}
+	t.mu.Unlock()
+
+	if leading {
+		t.call(v)
+	}
+}
+
+// start starts a new interval. The lock must be held.
+func (t *Throttler[T]) start() {
+	t.generation++
+	generation := t.generation
+	t.timer = time.AfterFunc(t.interval, func() {
+		t.fire(generation)
+	})
+}
+
+// fire ends an interval, calling the function if a call is pending. The
+// call starts a new interval.
+func (t *Throttler[T]) fire(generation uint64) {
+	t.mu.Lock()
+	if generation != t.generation {
+		t.mu.Unlock()
+		return
+	}
+	t.timer = nil
+	if !t.pending {
+		t.mu.Unlock()
+		return
+	}
+	t.start()
+	t.flush()
+}
+
+// Flush makes a pending call immediately, without waiting for the interval
+// to end. The interval is not restarted.
+func (t *Throttler[T]) Flush() {
+	t.mu.Lock()
+	t.flush()
+}
+
+// flush calls the function if a call is pending. The lock must be held, and
+// is released.
+func (t *Throttler[T]) flush() {
+	pending, v := t.pending, t.value
+	t.pending = false
+	var zero T
+	t.value = zero
+	t.mu.Unlock()
+	if pending {
+		t.call(v)
+	}
+}
+
+// Cancel drops the pending call, if any, and ends the current interval so
+// that the next call is made immediately.
+func (t *Throttler[T]) Cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.generation++
+	t.pending = false
+	var zero T
+	t.value = zero
+}
+
+// Pending reports whether a call to the function is pending.
+func (t *Throttler[T]) Pending() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pending
+}
+
+func (t *Throttler[T]) call(v T) {
+	t.callMu.Lock()
+	defer t.callMu.Unlock()
+	t.f(v)
+}
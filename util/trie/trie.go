@@ -0,0 +1,352 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package trie provides a radix tree, which maps string keys to values and
+finds keys by prefix, such as for routing paths.
+
+The tree is persistent: changes copy the nodes they modify instead of
+modifying them, so a [Snapshot] can be taken in constant time and read by
+any number of goroutines without locking while the tree keeps changing.
+
+	t := trie.New[Handler]()
+	t.Insert("/api/", api)
+	t.Insert("/api/users/", users)
+	prefix, h, ok := t.LongestPrefix("/api/users/42") // "/api/users/", users, true
+*/
+package trie
+
+// node is a node of a radix tree. Nodes are never modified once they are
+// reachable from a tree, so that snapshots can share them.
+type node[V any] struct {
+	label    string
+	leaf     bool
+	val      V
+	children []*node[V] // sorted by the first byte of their label
+}
+
+func (n *node[V]) clone() *node[V] {
+	c := *n
+	c.children = append([]*node[V](nil), n.children...)
+	return &c
+}
+
+// child returns the index of the child whose label starts with b, and the
+// child, or the index to insert it at and nil.
+func (n *node[V]) child(b byte) (int, *node[V]) {
+	lo, hi := 0, len(n.children)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if n.children[mid].label[0] < b {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(n.children) && n.children[lo].label[0] == b {
+		return lo, n.children[lo]
+	}
+	return lo, nil
+}
+
+// insert returns a copy of n with key, relative to n, set to v, and whether
+// an existing value was replaced.
+func (n *node[V]) insert(key string, v V) (*node[V], bool) {
+	c := n.clone()
+	if key == "" {
+		replaced := c.leaf
+		c.leaf, c.val = true, v
+		return c, replaced
+	}
+
+	i, child := c.child(key[0])
+	if child == nil {
+		c.children = append(c.children, nil)
+		copy(c.children[i+1:], c.children[i:])
+		c.children[i] = &node[V]{label: key, leaf: true, val: v}
+		return c, false
+	}
+
+	common := commonPrefix(key, child.label)
+	if common == len(child.label) {
+		var replaced bool
+		c.children[i], replaced = child.insert(key[common:], v)
+		return c, replaced
+	}
+
+	// Split the child at the end of the common prefix.
+	rest := child.clone()
+	rest.label = child.label[common:]
+	split := &node[V]{label: key[:common], children: []*node[V]{rest}}
+	if common == len(key) {
+		split.leaf, split.val = true, v
+	} else {
+		leaf := &node[V]{label: key[common:], leaf: true, val: v}
+		if leaf.label[0] < rest.label[0] {
+			split.children = []*node[V]{leaf, rest}
+		} else {
+			split.children = append(split.children, leaf)
+		}
+	}
+	c.children[i] = split
+	return c, false
+}
+
+// delete returns a copy of n without key, relative to n, the deleted value,
+// and whether it was found. The returned node is nil if it is empty.
+func (n *node[V]) delete(key string, root bool) (*node[V], V, bool) {
+	var zero V
+	if key == "" {
+		if !n.leaf {
+			return n, zero, false
+		}
+		c := n.clone()
+		c.leaf, c.val = false, zero
+		return c.compact(root), n.val, true
+	}
+
+	i, child := n.child(key[0])
+	if child == nil || len(key) < len(child.label) || key[:len(child.label)] != child.label {
+		return n, zero, false
+	}
+	nc, v, ok := child.delete(key[len(child.label):], false)
+	if !ok {
+		return n, zero, false
+	}
+	c := n.clone()
+	if nc == nil {
+		c.children = append(c.children[:i], c.children[i+1:]...)
+	} else {
+		c.children[i] = nc
+	}
+	return c.compact(root), v, true
+}
+
+// compact removes n if it is empty, or merges it with its only child. The
+// root is never removed or merged.
+func (n *node[V]) compact(root bool) *node[V] {
+	if root || n.leaf {
+		return n
+	}
+	switch len(n.children) {
+	case 0:
+		return nil
+	case 1:
+		child := n.children[0].clone()
+		child.label = n.label + child.label
+		return child
+	default:
+		return n
+	}
+}
+
+// get returns the value of key.
+func (n *node[V]) get(key string) (V, bool) {
+	for {
+		if key == "" {
+			return n.val, n.leaf
+		}
+		_, child := n.child(key[0])
+		if child == nil || len(key) < len(child.label) || key[:len(child.label)] != child.label {
+			var zero V
+			return zero, false
+		}
+		key = key[len(child.label):]
+		n = child
+	}
+}
+
+// longestPrefix returns the longest key that is a prefix of key.
+func (n *node[V]) longestPrefix(key string) (string, V, bool) {
+	var prefix string
+	var val V
+	found := false
+	matched := 0
+	for {
+		if n.leaf {
+			prefix, val, found = key[:matched], n.val, true
+		}
+		if matched == len(key) {
+			return prefix, val, found
+		}
+		_, child := n.child(key[matched])
+		if child == nil {
+			return prefix, val, found
+		}
+		rest := key[matched:]
+		if len(rest) < len(child.label) || rest[:len(child.label)] != child.label {
+			return prefix, val, found
+		}
+		matched += len(child.label)
+		n = child
+	}
+}
+
+// walkPrefix calls fn for each key starting with prefix, in order.
+func (n *node[V]) walkPrefix(prefix string, fn func(key string, v V) bool) {
+	path := ""
+	for prefix != "" {
+		_, child := n.child(prefix[0])
+		if child == nil {
+			return
+		}
+		common := commonPrefix(prefix, child.label)
+		if common < len(prefix) && common < len(child.label) {
+			return
+		}
+		path += child.label
+		n = child
+		prefix = prefix[common:]
+	}
+	n.walk(path, fn)
+}
+
+// walk calls fn for n and its descendants in order, and reports whether to
+// continue.
+func (n *node[V]) walk(path string, fn func(key string, v V) bool) bool {
+	if n.leaf && !fn(path, n.val) {
+		return false
+	}
+	for _, child := range n.children {
+		if !child.walk(path+child.label, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefix(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// Tree is a radix tree. A Tree is not safe for concurrent use, but its
+// snapshots are.
+type Tree[V any] struct {
+	root *node[V]
+	len  int
+}
+
+// New returns an empty Tree.
+func New[V any]() *Tree[V] {
+	return &Tree[V]{root: &node[V]{}}
+}
+
+// Len returns the number of keys in the tree.
+func (t *Tree[V]) Len() int {
+	return t.len
+}
+
+// Insert sets the value of key, and reports whether an existing value was
+// replaced.
+func (t *Tree[V]) Insert(key string, v V) bool {
+	var replaced bool
+	t.root, replaced = t.root.insert(key, v)
+	if !replaced {
+		t.len++
+	}
+	return replaced
+}
+
+// Delete deletes key, and returns its value and whether it was found.
+func (t *Tree[V]) Delete(key string) (V, bool) {
+	root, v, ok := t.root.delete(key, true)
+	if ok {
+		t.root = root
+		t.len--
+	}
+	return v, ok
+}
+
+// Get returns the value of key, and reports whether it was found.
+func (t *Tree[V]) Get(key string) (V, bool) {
+	return t.root.get(key)
+}
+
+// LongestPrefix returns the longest key in the tree that is a prefix of
+// key, and its value.
+func (t *Tree[V]) LongestPrefix(key string) (string, V, bool) {
+	return t.root.longestPrefix(key)
+}
+
+// WalkPrefix calls fn for each key starting with prefix, in lexical byte
+// order, until fn returns false.
+func (t *Tree[V]) WalkPrefix(prefix string, fn func(key string, v V) bool) {
+	t.root.walkPrefix(prefix, fn)
+}
+
+// Walk calls fn for each key in lexical byte order, until fn returns false.
+func (t *Tree[V]) Walk(fn func(key string, v V) bool) {
+	t.root.walk("", fn)
+}
+
+// Snapshot returns an immutable snapshot of the tree, in constant time.
+func (t *Tree[V]) Snapshot() *Snapshot[V] {
+	return &Snapshot[V]{root: t.root, len: t.len}
+}
+
+// Snapshot is an immutable view of a [Tree] at the time it was taken. A
+// Snapshot is safe for concurrent use without locking.
+type Snapshot[V any] struct {
+	root *node[V]
+	len  int
+}
+
+// Len returns the number of keys in the snapshot.
+func (s *Snapshot[V]) Len() int {
+	return s.len
+}
+
+// Get returns the value of key, and reports whether it was found.
+func (s *Snapshot[V]) Get(key string) (V, bool) {
+	return s.root.get(key)
+}
+
+// LongestPrefix returns the longest key in the snapshot that is a prefix
+// of key, and its value.
+func (s *Snapshot[V]) LongestPrefix(key string) (string, V, bool) {
+	return s.root.longestPrefix(key)
+}
+
+// WalkPrefix calls fn for each key starting with prefix, in lexical byte
+// order, until fn returns false.
+func (s *Snapshot[V]) WalkPrefix(prefix string, fn func(key string, v V) bool) {
+	s.root.walkPrefix(prefix, fn)
+}
+
+// Walk calls fn for each key in lexical byte order, until fn returns false.
+func (s *Snapshot[V]) Walk(fn func(key string, v V) bool) {
+	s.root.walk("", fn)
+}
+
+// Tree returns a tree that starts from the snapshot. Changing the tree does
+// not change the snapshot.
+func (s *Snapshot[V]) Tree() *Tree[V] {
+	return &Tree[V]{root: s.root, len: s.len}
+}
@@ -0,0 +1,224 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package trie
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func keys[V any](walk func(fn func(key string, v V) bool)) []string {
+	var keys []string
+	walk(func(key string, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func TestTree(t *testing.T) {
+	tr := New[int]()
+	words := []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus", "r", ""}
+	for i, w := range words {
+		if tr.Insert(w, i) {
+			t.Errorf("Insert(%q) replaced a value", w)
+		}
+	}
+	if !tr.Insert("ruber", 100) {
+		t.Error("Insert(ruber) did not replace the value")
+	}
+	if tr.Len() != len(words) {
+		t.Errorf("Len() = %d, want %d", tr.Len(), len(words))
+	}
+
+	for i, w := range words {
+		want := i
+		if w == "ruber" {
+			want = 100
+		}
+		if v, ok := tr.Get(w); v != want || !ok {
+			t.Errorf("Get(%q) = %d, %t, want %d, true", w, v, ok, want)
+		}
+	}
+	for _, w := range []string{"rom", "romanes", "rubi", "x"} {
+		if _, ok := tr.Get(w); ok {
+			t.Errorf("Get(%q) found a missing key", w)
+		}
+	}
+
+	want := slices.Clone(words)
+	slices.Sort(want)
+	if got := keys(tr.Walk); !slices.Equal(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	tr := New[string]()
+	tr.Insert("/", "root")
+	tr.Insert("/api/", "api")
+	tr.Insert("/api/users/", "users")
+
+	tests := []struct {
+		key        string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{key: "/api/users/42", wantPrefix: "/api/users/", wantOK: true},
+		{key: "/api/user", wantPrefix: "/api/", wantOK: true},
+		{key: "/static/app.js", wantPrefix: "/", wantOK: true},
+		{key: "/api/", wantPrefix: "/api/", wantOK: true},
+		{key: "api", wantOK: false},
+	}
+	for _, tt := range tests {
+		prefix, _, ok := tr.LongestPrefix(tt.key)
+		if prefix != tt.wantPrefix || ok != tt.wantOK {
+			t.Errorf("LongestPrefix(%q) = %q, %t, want %q, %t", tt.key, prefix, ok, tt.wantPrefix, tt.wantOK)
+		}
+	}
+}
+
+func TestWalkPrefix(t *testing.T) {
+	tr := New[int]()
+	for i, w := range []string{"team", "tea", "ten", "to", "toast", "inn"} {
+		tr.Insert(w, i)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "te", want: []string{"tea", "team", "ten"}},
+		{prefix: "tea", want: []string{"tea", "team"}},
+		{prefix: "to", want: []string{"to", "toast"}},
+		{prefix: "toa", want: []string{"toast"}},
+		{prefix: "x", want: nil},
+		{prefix: "teamwork", want: nil},
+		{prefix: "", want: []string{"inn", "tea", "team", "ten", "to", "toast"}},
+	}
+	for _, tt := range tests {
+		got := keys(func(fn func(string, int) bool) { tr.WalkPrefix(tt.prefix, fn) })
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("WalkPrefix(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+
+	var visited int
+	tr.Walk(func(string, int) bool {
+		visited++
+		return visited < 2
+	})
+	if visited != 2 {
+		t.Errorf("Walk() visited %d keys after fn returned false, want 2", visited)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tr := New[int]()
+	ref := make(map[string]int)
+	r := rand.New(rand.NewPCG(1, 2))
+	randKey := func() string {
+		var b strings.Builder
+		for n := r.IntN(6); n > 0; n-- {
+			b.WriteByte("abc"[r.IntN(3)])
+		}
+		return b.String()
+	}
+
+	for i := 0; i < 5000; i++ {
+		key := randKey()
+		if r.IntN(3) == 0 {
+			_, want := ref[key]
+			delete(ref, key)
+			if _, ok := tr.Delete(key); ok != want {
+				t.Fatalf("Delete(%q) = %t, want %t", key, ok, want)
+			}
+		} else {
+			ref[key] = i
+			tr.Insert(key, i)
+		}
+	}
+
+	if tr.Len() != len(ref) {
+		t.Errorf("Len() = %d, want %d", tr.Len(), len(ref))
+	}
+	var want []string
+	for k, v := range ref {
+		want = append(want, k)
+		if got, ok := tr.Get(k); got != v || !ok {
+			t.Errorf("Get(%q) = %d, %t, want %d, true", k, got, ok, v)
+		}
+	}
+	slices.Sort(want)
+	if got := keys(tr.Walk); !slices.Equal(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tr := New[int]()
+	tr.Insert("a", 1)
+	tr.Insert("ab", 2)
+	s := tr.Snapshot()
+
+	tr.Insert("abc", 3)
+	tr.Delete("a")
+	tr.Insert("ab", 20)
+
+	if got := keys(s.Walk); !slices.Equal(got, []string{"a", "ab"}) || s.Len() != 2 {
+		t.Errorf("snapshot keys = %v, changed by the tree", got)
+	}
+	if v, _ := s.Get("ab"); v != 2 {
+		t.Errorf("snapshot Get(ab) = %d, want 2", v)
+	}
+
+	t2 := s.Tree()
+	t2.Insert("b", 4)
+	if _, ok := s.Get("b"); ok {
+		t.Error("snapshot changed by a tree started from it")
+	}
+
+	// Snapshots can be read while the tree changes.
+	var wg sync.WaitGroup
+	s = tr.Snapshot()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				s.LongestPrefix("abcd")
+				s.WalkPrefix("a", func(string, int) bool { return true })
+			}
+		}()
+	}
+	for j := 0; j < 1000; j++ {
+		tr.Insert(strings.Repeat("a", j%10), j)
+		tr.Delete(strings.Repeat("a", (j+5)%10))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,318 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+Package workerpool implements a pool of goroutines that run submitted tasks,
+with a bounded queue and a number of workers that can be changed at runtime.
+
+	p := workerpool.New(&workerpool.Options{Workers: 8, QueueSize: 100})
+	defer p.Shutdown(ctx)
+
+	err := p.Submit(ctx, func() { ... })
+*/
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"hypera.dev/lib/util/internal/panics"
+)
+
+// ErrClosed is returned when a task is submitted after [Pool.Shutdown] was
+// called.
+var ErrClosed = errors.New("workerpool: pool is shut down")
+
+// PanicError describes a task that panicked, with the value passed to panic
+// and the stack trace.
+type PanicError = panics.Error
+
+// Options configure a [Pool].
+type Options struct {
+	// Workers is the number of goroutines that run tasks. Defaults to
+	// [runtime.GOMAXPROCS].
+	Workers int
+
+	// QueueSize is the number of tasks that can wait for a worker. If zero,
+	// submitting a task blocks until a worker is available.
+	QueueSize int
+
+	// OnPanic is called when a task panics. Panics are recovered, so that a
+	// task cannot stop a worker or crash the program.
+	OnPanic func(err *PanicError)
+}
+
+// Stats are metrics of a [Pool].
+type Stats struct {
+	// Workers is the number of workers.
+	Workers int
+
+	// Running is the number of tasks that are running.
+	Running int
+
+	// Queued is the number of tasks waiting for a worker.
+	Queued int
+
+	// Completed is the number of tasks that finished, including those that
+	// panicked.
+	Completed uint64
+
+	// Panicked is the number of tasks that panicked.
+	Panicked uint64
+}
+
+// task is a submitted task.
+type task struct {
+	fn   func()
+	done chan error
+}
+
+// Pool runs submitted tasks on a set of workers. A Pool is safe for
+// concurrent use.
+type Pool struct {
+	tasks   chan task
+	onPanic func(err *PanicError)
+	wg      sync.WaitGroup
+
+	// mu is held for reading while submitting tasks, so that Shutdown can
+	// wait for submissions to finish before closing the queue.
+	mu        sync.RWMutex
+	closing   chan struct{}
+	closeOnce sync.Once
+	discard   atomic.Bool
+
+	workersMu sync.Mutex
+	workers   int
+	target    int
+	wake      chan struct{}
+	closed    bool
+
+	running   atomic.Int64
+	completed atomic.Uint64
+	panicked  atomic.Uint64
+}
+
+// New returns a [Pool] configured with opts, with its workers started. If
+// opts is nil, the default options are used.
+func New(opts *Options) *Pool {
+	if opts == nil {
+		opts = &Options{}
+	}
+	p := &Pool{
+		tasks:   make(chan task, max(opts.QueueSize, 0)),
+		onPanic: opts.OnPanic,
+		closing: make(chan struct{}),
+		wake:    make(chan struct{}),
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	p.Resize(workers)
+	return p
+}
+
+// Submit queues fn to be run by a worker, blocking until there is space in
+// the queue. It returns [ErrClosed] if the pool is shut down, or the
+// context's error if ctx is done before fn is queued.
+func (p *Pool) Submit(ctx context.Context, fn func()) error {
+	return p.submit(ctx, task{fn: fn})
+}
+
+// SubmitWait is like [Pool.Submit], but also waits for fn to finish. If fn
+// panics, a [*PanicError] is returned, and if fn is discarded by
+// [Pool.Shutdown], [ErrClosed] is returned. If ctx is done before fn
+// finishes, the context's error is returned, but fn is not stopped.
+func (p *Pool) SubmitWait(ctx context.Context, fn func()) error {
+	done := make(chan error, 1)
+	if err := p.submit(ctx, task{fn: fn, done: done}); err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) submit(ctx context.Context, t task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	select {
+	case <-p.closing:
+		return ErrClosed
+	default:
+	}
+	select {
+	case p.tasks <- t:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.closing:
+		return ErrClosed
+	}
+}
+
+// Resize changes the number of workers to n, which is at least 1. Excess
+// workers stop after finishing their current task. Resize has no effect
+// after [Pool.Shutdown] was called.
+func (p *Pool) Resize(n int) {
+	n = max(n, 1)
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+	if p.closed {
+		return
+	}
+	p.target = n
+	for p.workers < n {
+		p.workers++
+		p.wg.Add(1)
+		go p.worker()
+	}
+	if p.workers > n {
+		// Wake idle workers, so that the excess ones stop
+		close(p.wake)
+		p.wake = make(chan struct{})
+	}
+}
+
+// Stats returns the current metrics of the pool.
+func (p *Pool) Stats() Stats {
+	p.workersMu.Lock()
+	workers := p.workers
+	p.workersMu.Unlock()
+	return Stats{
+		Workers:   workers,
+		Running:   int(p.running.Load()),
+		Queued:    len(p.tasks),
+		Completed: p.completed.Load(),
+		Panicked:  p.panicked.Load(),
+	}
+}
+
+// Shutdown stops accepting tasks, and waits for the queued and running tasks
+// to finish. If ctx is done first, the queued tasks that have not started
+// are discarded and the context's error is returned, but running tasks are
+// not stopped. Calling Shutdown more than once is safe.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+		// Wait for submissions in progress to finish
+		p.mu.Lock()
+		p.mu.Unlock() // nolint: staticcheck
+		p.close()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		p.discard.Store(true)
+		return ctx.Err()
+	}
+}
+
+// close closes the queue once no more tasks can be submitted.
+func (p *Pool) close() {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+	p.closed = true
+	close(p.tasks)
+}
+
+// worker runs tasks until the queue is closed or it is no longer needed.
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	for {
+		wake, stop := p.idle()
+		if stop {
+			return
+		}
+		select {
+		case t, ok := <-p.tasks:
+			if !ok {
+				p.exit()
+				return
+			}
+			p.run(t)
+		case <-wake:
+		}
+	}
+}
+
+// idle returns the channel that wakes idle workers, and whether the worker
+// should stop because there are too many workers.
+func (p *Pool) idle() (<-chan struct{}, bool) {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+	if !p.closed && p.workers > p.target {
+		p.workers--
+		return nil, true
+	}
+	return p.wake, false
+}
+
+// exit removes a worker after the queue is closed.
+func (p *Pool) exit() {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+	p.workers--
+}
+
+// run runs a task, recovering from panics.
+func (p *Pool) run(t task) {
+	if p.discard.Load() {
+		if t.done != nil {
+			t.done <- ErrClosed
+		}
+		return
+	}
+
+	p.running.Add(1)
+	panicErr := panics.Run(t.fn)
+	if panicErr != nil {
+		p.panicked.Add(1)
+		if p.onPanic != nil {
+			p.onPanic(panicErr)
+		}
+	}
+	p.running.Add(-1)
+	p.completed.Add(1)
+	if t.done != nil {
+		if panicErr != nil {
+			t.done <- panicErr
+		} else {
+			t.done <- nil
+		}
+	}
+}
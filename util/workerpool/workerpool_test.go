@@ -0,0 +1,142 @@
+/*
+ * This file is a part of hypera.dev/lib, licensed under the MIT License.
+ *
+ * Copyright (c) 2024 Joshua Sing <joshua@joshuasing.dev>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it is true or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPool(t *testing.T) {
+	p := New(&Options{Workers: 4, QueueSize: 10})
+	ctx := context.Background()
+	var n atomic.Int32
+	for i := 0; i < 100; i++ {
+		if err := p.Submit(ctx, func() { n.Add(1) }); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := n.Load(); got != 100 {
+		t.Errorf("tasks run = %d, want 100", got)
+	}
+	if got := p.Stats(); got.Completed != 100 || got.Workers != 0 {
+		t.Errorf("Stats() = %+v, want 100 completed and no workers", got)
+	}
+	if err := p.Submit(ctx, func() {}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit() after Shutdown error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestPoolSubmitWaitPanic(t *testing.T) {
+	var onPanic atomic.Int32
+	p := New(&Options{Workers: 1, OnPanic: func(*PanicError) { onPanic.Add(1) }})
+	defer p.Shutdown(context.Background())
+
+	err := p.SubmitWait(context.Background(), func() { panic("boom") })
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("SubmitWait() error = %v, want PanicError", err)
+	}
+	if onPanic.Load() != 1 || p.Stats().Panicked != 1 {
+		t.Errorf("panic not reported")
+	}
+
+	// The worker survives the panic
+	if err := p.SubmitWait(context.Background(), func() {}); err != nil {
+		t.Errorf("SubmitWait() error = %v", err)
+	}
+}
+
+func TestPoolSubmitContext(t *testing.T) {
+	p := New(&Options{Workers: 1})
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		p.Shutdown(context.Background())
+	}()
+
+	_ = p.Submit(context.Background(), func() { <-release })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, func() {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit() to busy pool error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPoolShutdownTimeout(t *testing.T) {
+	p := New(&Options{Workers: 1, QueueSize: 1})
+	release := make(chan struct{})
+	_ = p.Submit(context.Background(), func() { <-release })
+	waitFor(t, func() bool { return p.Stats().Running == 1 })
+	done := make(chan error, 1)
+	go func() {
+		done <- p.SubmitWait(context.Background(), func() {})
+	}()
+	waitFor(t, func() bool { return p.Stats().Queued == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("discarded SubmitWait() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestPoolResize(t *testing.T) {
+	p := New(&Options{Workers: 1})
+	defer p.Shutdown(context.Background())
+
+	release := make(chan struct{})
+	p.Resize(3)
+	for i := 0; i < 3; i++ {
+		_ = p.Submit(context.Background(), func() { <-release })
+	}
+	waitFor(t, func() bool { return p.Stats().Running == 3 })
+
+	p.Resize(1)
+	close(release)
+	waitFor(t, func() bool { return p.Stats().Workers == 1 })
+}